@@ -1,3 +1,7 @@
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    TEXT PRIMARY KEY,
+    applied_at TEXT NOT NULL
+);
 
 CREATE TABLE IF NOT EXISTS secrets (
     id         INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -13,3 +17,129 @@ CREATE TABLE IF NOT EXISTS secrets (
     message TEXT NOT NULL
 );
 
+CREATE TABLE IF NOT EXISTS secret_requests (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    request_key TEXT NOT NULL UNIQUE,
+    access_key  TEXT NOT NULL UNIQUE,
+    signing_key TEXT NOT NULL,
+
+    secret_phrase TEXT NOT NULL,
+
+    note TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS secret_statuses (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    status_key    TEXT NOT NULL UNIQUE,
+    status_secret TEXT NOT NULL,
+
+    access_key       TEXT NOT NULL,
+    reply_access_key TEXT NOT NULL,
+    reply_secret_key TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS uploads (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    access_key  TEXT NOT NULL UNIQUE,
+    signing_key TEXT NOT NULL,
+
+    name         TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+
+    upload_length INTEGER NOT NULL,
+    upload_offset INTEGER NOT NULL DEFAULT 0,
+    chunks        TEXT    NOT NULL DEFAULT '[]'
+);
+
+CREATE TABLE IF NOT EXISTS pake_sessions (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    session_key TEXT NOT NULL UNIQUE,
+    access_key  TEXT NOT NULL,
+    confirm_key TEXT NOT NULL,
+    shared_key  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webauthn_sessions (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    session_key TEXT NOT NULL UNIQUE,
+    access_key  TEXT NOT NULL,
+    challenge   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS email_codes (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    access_key TEXT    NOT NULL UNIQUE,
+    code_hash  TEXT    NOT NULL,
+    attempts   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS signers (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+
+    name        TEXT NOT NULL,
+    public_key  TEXT NOT NULL,
+    fingerprint TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS secret_events (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+
+    access_key TEXT NOT NULL,
+    kind       TEXT NOT NULL,
+    details    TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS secret_events_access_key_idx ON secret_events (access_key);
+
+CREATE TABLE IF NOT EXISTS attachment_downloads (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    download_key TEXT    NOT NULL UNIQUE,
+    blob_key     TEXT    NOT NULL,
+    streamed     INTEGER NOT NULL,
+
+    name         TEXT    NOT NULL,
+    content_type TEXT    NOT NULL,
+    size         INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS signature_nonces (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    fingerprint TEXT NOT NULL,
+    nonce       TEXT NOT NULL UNIQUE
+);
@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN available_from TEXT NOT NULL DEFAULT '0001-01-01T00:00:00Z';
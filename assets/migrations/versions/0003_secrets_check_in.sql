@@ -0,0 +1,3 @@
+ALTER TABLE secrets ADD COLUMN check_in_interval INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE secrets ADD COLUMN check_in_token TEXT NOT NULL DEFAULT '';
+ALTER TABLE secrets ADD COLUMN released INTEGER NOT NULL DEFAULT 0;
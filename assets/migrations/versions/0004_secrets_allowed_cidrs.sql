@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN allowed_cidrs TEXT NOT NULL DEFAULT '';
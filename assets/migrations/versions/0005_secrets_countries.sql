@@ -0,0 +1,2 @@
+ALTER TABLE secrets ADD COLUMN allowed_countries TEXT NOT NULL DEFAULT '';
+ALTER TABLE secrets ADD COLUMN denied_countries TEXT NOT NULL DEFAULT '';
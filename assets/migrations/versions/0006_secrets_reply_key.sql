@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN reply_key TEXT NOT NULL DEFAULT '';
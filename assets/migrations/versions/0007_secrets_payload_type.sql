@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN payload_type TEXT NOT NULL DEFAULT 'text';
@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN attachments TEXT NOT NULL DEFAULT '[]';
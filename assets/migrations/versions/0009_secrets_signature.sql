@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN signature TEXT NOT NULL DEFAULT '';
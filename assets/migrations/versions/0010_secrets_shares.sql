@@ -0,0 +1,4 @@
+ALTER TABLE secrets ADD COLUMN share_threshold INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE secrets ADD COLUMN share_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE secrets ADD COLUMN share_window INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE secrets ADD COLUMN share_digests TEXT NOT NULL DEFAULT '';
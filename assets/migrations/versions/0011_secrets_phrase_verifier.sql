@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN phrase_verifier TEXT NOT NULL DEFAULT '';
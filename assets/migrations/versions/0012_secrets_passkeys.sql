@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN passkeys TEXT NOT NULL DEFAULT '';
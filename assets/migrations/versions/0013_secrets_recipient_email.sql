@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN recipient_email TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN sealed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN key_check TEXT NOT NULL DEFAULT '';
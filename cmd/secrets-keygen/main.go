@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/cryptor/hybrid"
+	"github.com/protomem/secrets-keeper/internal/recipient"
+)
+
+const usage = `usage:
+  secrets-keygen                    generate a MLKEM768-X25519 key pair
+  secrets-keygen decrypt IDENTITY   decrypt an age message from stdin to stdout`
+
+func main() {
+	var err error
+
+	switch {
+	case len(os.Args) == 1:
+		err = generate()
+	case len(os.Args) == 3 && os.Args[1] == "decrypt":
+		err = decrypt(os.Args[2])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Printf("error: %v", err)
+		os.Exit(1)
+	}
+}
+
+func generate() error {
+	kem := hybrid.NewKEM()
+
+	publicKey, privateKey, err := kem.GenerateKey()
+	if err != nil {
+		return err
+	}
+	defer cryptor.Wipe(privateKey)
+
+	recipientKey, identity := recipient.FormatKEMKeys(kem, publicKey, privateKey)
+
+	fmt.Printf("# recipient: %s\n", recipientKey)
+	fmt.Println(identity)
+
+	return nil
+}
+
+func decrypt(identityPath string) error {
+	identityData, err := os.ReadFile(identityPath)
+	if err != nil {
+		return err
+	}
+	defer cryptor.Wipe(identityData)
+
+	identities, err := recipient.ParseIdentities(identityData, cryptor.NewKEMRegistry(hybrid.NewKEM()))
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := recipient.Decrypt(ciphertext, identities)
+	if err != nil {
+		return err
+	}
+	defer cryptor.Wipe(plaintext)
+
+	_, err = os.Stdout.Write(plaintext)
+
+	return err
+}
@@ -1,17 +1,34 @@
 module github.com/protomem/secrets-keeper
 
-go 1.21.0
+go 1.24.0
 
 require (
-	github.com/google/uuid v1.3.1
+	filippo.io/age v1.1.1
+	filippo.io/edwards25519 v1.0.0
+	github.com/google/uuid v1.5.0
 	github.com/gorilla/handlers v1.5.1
 	github.com/gorilla/mux v1.8.0
+	github.com/klauspost/compress v1.17.4
 	github.com/mattn/go-sqlite3 v1.14.17
+	github.com/minio/minio-go/v7 v7.0.66
+	github.com/oschwald/maxminddb-golang v1.13.1
 	github.com/rs/cors v1.9.0
-	golang.org/x/crypto v0.12.0
+	golang.org/x/crypto v0.16.0
+	golang.org/x/sys v0.21.0
 )
 
 require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/felixge/httpsnoop v1.0.1 // indirect
-	golang.org/x/sys v0.11.0 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.6 // indirect
+	github.com/minio/md5-simd v1.1.2 // indirect
+	github.com/minio/sha256-simd v1.0.1 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/rs/xid v1.5.0 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	golang.org/x/net v0.19.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/ini.v1 v1.67.0 // indirect
 )
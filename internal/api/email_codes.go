@@ -0,0 +1,16 @@
+package api
+
+import "github.com/protomem/secrets-keeper/internal/usecase"
+
+func (s *Server) emailCodeOptions() usecase.EmailCodeOptions {
+	return usecase.EmailCodeOptions{
+		TTL:            s.conf.EmailCodeTTL,
+		MaxAttempts:    s.conf.EmailCodeMaxAttempts,
+		ResendInterval: s.conf.EmailCodeResendInterval,
+		Lockout: usecase.LockoutOptions{
+			MaxAttempts: s.conf.MaxAttempts,
+			BaseDelay:   s.conf.AttemptDelay,
+			MaxDelay:    s.conf.MaxAttemptDelay,
+		},
+	}
+}
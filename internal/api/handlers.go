@@ -3,11 +3,19 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/export"
 	"github.com/protomem/secrets-keeper/internal/model"
 	"github.com/protomem/secrets-keeper/internal/usecase"
+	"github.com/protomem/secrets-keeper/pkg/pake"
+	"github.com/protomem/secrets-keeper/pkg/realip"
 	"github.com/protomem/secrets-keeper/pkg/requestid"
 )
 
@@ -23,7 +31,7 @@ func (*Server) handleHealthCheck() http.Handler {
 
 func (s *Server) handleGetSecret() http.Handler {
 	type Request struct {
-		SecretPhrase string `json:"secretPhrase"`
+		SecretPhrase model.SecretText `json:"secretPhrase"`
 	}
 
 	type Response struct {
@@ -47,6 +55,24 @@ func (s *Server) handleGetSecret() http.Handler {
 		}()
 
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("Vary", "Accept")
+
+		var format export.Format
+		if formatParam := r.URL.Query().Get("format"); formatParam != "" {
+			format, err = export.ParseFormat(formatParam)
+			if err != nil {
+				logger.Error("failed to parse export format", "error", err)
+
+				w.WriteHeader(http.StatusBadRequest)
+				err = json.NewEncoder(w).Encode(map[string]string{
+					"error": "unsupported format",
+				})
+
+				return
+			}
+		} else if negotiated, ok := export.Negotiate(r.Header.Get("Accept")); ok {
+			format = negotiated
+		}
 
 		secretKey, ok := mux.Vars(r)["key"]
 		if !ok {
@@ -61,7 +87,7 @@ func (s *Server) handleGetSecret() http.Handler {
 		}
 
 		var req Request
-		err = json.NewDecoder(r.Body).Decode(&req)
+		err = decodeProtectedJSON(r.Body, s.conf.MaxUploadSize, &req)
 		if err != nil {
 			logger.Error("failed to decode request", "error", err)
 
@@ -73,18 +99,37 @@ func (s *Server) handleGetSecret() http.Handler {
 			return
 		}
 
+		defer cryptor.Wipe(req.SecretPhrase)
+
 		secret, err := usecase.GetSecret(
 			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.SignerRepo(),
+			s.store.DownloadRepo(),
 			s.hasher,
 			s.encoder,
 			s.encryptor,
+			s.sealer,
+			s.blobs,
+			s.locator,
+			usecase.LockoutOptions{
+				MaxAttempts: s.conf.MaxAttempts,
+				BaseDelay:   s.conf.AttemptDelay,
+				MaxDelay:    s.conf.MaxAttemptDelay,
+			},
+			s.conf.AttachmentDownloadTTL,
 		)(ctx, usecase.GetSecretDTO{
 			SecretKey:    secretKey,
 			SecretPhrase: req.SecretPhrase,
+			ClientIP:     realip.FromRequest(r, s.trustedProxies),
 		})
 		if err != nil {
 			logger.Error("failed to get secret", "error", err)
 
+			if errors.Is(err, model.ErrAccessDenied) {
+				logger.Info("secret access denied", "clientIp", realip.FromRequest(r, s.trustedProxies).String())
+			}
+
 			code := http.StatusInternalServerError
 			res := map[string]string{
 				"error": "failed to get secret",
@@ -97,29 +142,144 @@ func (s *Server) handleGetSecret() http.Handler {
 				}
 			}
 
+			var notYetAvailableErr *model.SecretNotYetAvailableError
+			if errors.As(err, &notYetAvailableErr) {
+				code = http.StatusTooEarly
+				res = map[string]string{
+					"error":         model.ErrSecretNotYetAvailable.Error(),
+					"availableFrom": notYetAvailableErr.AvailableFrom.Format(time.RFC3339),
+				}
+			}
+
 			w.WriteHeader(code)
 			err = json.NewEncoder(w).Encode(res)
 
 			return
 		}
 
+		defer cryptor.Wipe(secret.Message)
+
+		if format != "" {
+			var doc export.Document
+			doc, err = export.Render(format, "secret", secret)
+			if err != nil {
+				logger.Error("failed to render secret", "error", err)
+
+				w.WriteHeader(http.StatusInternalServerError)
+				err = json.NewEncoder(w).Encode(map[string]string{
+					"error": "failed to render secret",
+				})
+
+				return
+			}
+
+			w.Header().Set("Content-Type", doc.ContentType)
+			w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+				"filename": doc.Filename,
+			}))
+			w.WriteHeader(http.StatusOK)
+			_, err = w.Write(doc.Data)
+			cryptor.Wipe(doc.Data)
+
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
-		err = json.NewEncoder(w).Encode(Response{
+		err = encodeProtectedJSON(w, Response{
 			Secret: secret,
 		})
 	})
 }
 
+func (s *Server) handleDownloadAttachment() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.DownloadAttachment"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		content, err := usecase.DownloadAttachment(
+			s.store.DownloadRepo(),
+			s.encoder,
+			s.encryptor,
+			s.streamer,
+			s.blobs,
+		)(ctx, usecase.DownloadAttachmentDTO{
+			DownloadKey: mux.Vars(r)["key"],
+		})
+		if err != nil {
+			logger.Error("failed to download attachment", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to download attachment",
+			}
+
+			if errors.Is(err, model.ErrAttachmentNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrAttachmentNotFound.Error(),
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+		defer func() { _ = content.Data.Close() }()
+
+		w.Header().Set("Content-Type", content.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(content.Size, 10))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": content.Name,
+		}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, content.Data)
+	})
+}
+
 func (s *Server) handleCreateSecret() http.Handler {
 	type Request struct {
-		Message      string `json:"message"`
-		TTL          int64  `json:"ttl"`
-		SecretPhrase string `json:"secretPhrase"`
+		Message          model.SecretText       `json:"message"`
+		Fields           []model.SecretField    `json:"fields"`
+		TTL              int64                  `json:"ttl"`
+		SecretPhrase     model.SecretText       `json:"secretPhrase"`
+		AvailableFrom    time.Time              `json:"availableFrom"`
+		CheckInInterval  int64                  `json:"checkInInterval"`
+		AllowedCIDRs     []string               `json:"allowedCidrs"`
+		AllowedCountries []string               `json:"allowedCountries"`
+		DeniedCountries  []string               `json:"deniedCountries"`
+		AllowReply       bool                   `json:"allowReply"`
+		Uploads          []string               `json:"uploads"`
+		Recipients       []string               `json:"recipients"`
+		Signature        *signatureRequest      `json:"signature"`
+		Shares           *sharesRequest         `json:"shares"`
+		PhraseVerifier   *phraseVerifierRequest `json:"phraseVerifier"`
+		Passkeys         []passkeyRequest       `json:"passkeys"`
+		RecipientEmail   string                 `json:"recipientEmail"`
 	}
 
 	type Response struct {
-		SecretKey        string `json:"secretKey"`
-		WithSecretPhrase bool   `json:"withSecretPhrase"`
+		SecretKey        string   `json:"secretKey"`
+		ShareKeys        []string `json:"shareKeys,omitempty"`
+		WithSecretPhrase bool     `json:"withSecretPhrase"`
+		WithPasskey      bool     `json:"withPasskey"`
+		WithEmailCode    bool     `json:"withEmailCode"`
+		CheckInToken     string   `json:"checkInToken,omitempty"`
+		StatusKey        string   `json:"statusKey,omitempty"`
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -140,28 +300,76 @@ func (s *Server) handleCreateSecret() http.Handler {
 
 		w.Header().Set("Content-Type", "application/json")
 
+		r.Body = http.MaxBytesReader(w, r.Body, s.conf.MaxUploadSize)
+
 		var req Request
-		err = json.NewDecoder(r.Body).Decode(&req)
+		var attachments usecase.AttachmentSource
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+			req, attachments, err = decodeMultipartSecret[Request](r, s.conf.MaxUploadSize)
+		} else {
+			err = decodeProtectedJSON(r.Body, s.conf.MaxUploadSize, &req)
+		}
 		if err != nil {
 			logger.Error("failed to decode request", "error", err)
 
-			w.WriteHeader(http.StatusBadRequest)
-			err = json.NewEncoder(w).Encode(map[string]string{
+			code := http.StatusBadRequest
+			res := map[string]string{
 				"error": "invalid request",
-			})
+			}
+
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				code = http.StatusRequestEntityTooLarge
+				res = map[string]string{
+					"error": model.ErrAttachmentTooLarge.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
 
 			return
 		}
 
-		secretKey, err := usecase.CreateSecret(
+		defer cryptor.Wipe(req.Message)
+		defer cryptor.Wipe(req.SecretPhrase)
+
+		created, err := usecase.CreateSecret(
 			s.store.SecretRepo(),
+			s.store.RequestRepo(),
+			s.store.StatusRepo(),
+			s.store.EventRepo(),
+			s.store.UploadRepo(),
+			s.store.SignerRepo(),
 			s.hasher,
 			s.encoder,
 			s.encryptor,
+			s.streamer,
+			s.sealer,
+			s.kems,
+			s.blobs,
+			s.locator,
+			s.mailer,
+			s.mode,
 		)(ctx, usecase.CreateSecretDTO{
-			Message:      req.Message,
-			TTL:          req.TTL,
-			SecretPhrase: req.SecretPhrase,
+			Message:          req.Message,
+			Fields:           req.Fields,
+			TTL:              req.TTL,
+			SecretPhrase:     req.SecretPhrase,
+			AvailableFrom:    req.AvailableFrom,
+			CheckInInterval:  req.CheckInInterval,
+			AllowedCIDRs:     req.AllowedCIDRs,
+			AllowedCountries: req.AllowedCountries,
+			DeniedCountries:  req.DeniedCountries,
+			AllowReply:       req.AllowReply,
+			Attachments:      attachments,
+			Uploads:          req.Uploads,
+			Recipients:       req.Recipients,
+			Signature:        req.Signature.dto(),
+			Shares:           req.Shares.dto(s.conf.ShareWindow),
+			PhraseVerifier:   req.PhraseVerifier.dto(),
+			Passkeys:         passkeysDTO(req.Passkeys),
+			RecipientEmail:   req.RecipientEmail,
 		})
 		if err != nil {
 			logger.Error("failed to create secret", "error", err)
@@ -171,6 +379,21 @@ func (s *Server) handleCreateSecret() http.Handler {
 				"error": "failed to create secret",
 			}
 
+			if errors.Is(err, model.ErrInvalidSecret) {
+				code = http.StatusBadRequest
+				res = map[string]string{
+					"error": model.ErrInvalidSecret.Error(),
+				}
+			}
+
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				code = http.StatusRequestEntityTooLarge
+				res = map[string]string{
+					"error": model.ErrAttachmentTooLarge.Error(),
+				}
+			}
+
 			w.WriteHeader(code)
 			err = json.NewEncoder(w).Encode(res)
 
@@ -179,8 +402,1760 @@ func (s *Server) handleCreateSecret() http.Handler {
 
 		w.WriteHeader(http.StatusCreated)
 		err = json.NewEncoder(w).Encode(Response{
-			SecretKey:        secretKey,
-			WithSecretPhrase: req.SecretPhrase != "",
+			SecretKey:        created.SecretKey,
+			ShareKeys:        created.ShareKeys,
+			WithSecretPhrase: len(req.SecretPhrase) > 0 || req.PhraseVerifier != nil,
+			WithPasskey:      len(req.Passkeys) > 0,
+			WithEmailCode:    req.RecipientEmail != "",
+			CheckInToken:     created.CheckInToken,
+			StatusKey:        created.StatusKey,
+		})
+	})
+}
+
+func (s *Server) handleGetSecretStatus() http.Handler {
+	type Response struct {
+		Events         []model.SecretEvent `json:"events"`
+		Replied        bool                `json:"replied"`
+		ReplySecretKey string              `json:"replySecretKey,omitempty"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.GetSecretStatus"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		statusKey, ok := mux.Vars(r)["key"]
+		if !ok {
+			logger.Error("failed to get status key")
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "missing status key",
+			})
+
+			return
+		}
+
+		status, err := usecase.GetSecretStatus(
+			s.store.StatusRepo(),
+			s.store.EventRepo(),
+			s.hasher,
+			s.encoder,
+			s.encryptor,
+		)(ctx, usecase.GetSecretStatusDTO{
+			StatusKey: statusKey,
+		})
+		if err != nil {
+			logger.Error("failed to get secret status", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to get secret status",
+			}
+
+			if errors.Is(err, model.ErrStatusNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrStatusNotFound.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			Events:         status.Events,
+			Replied:        status.Replied,
+			ReplySecretKey: status.ReplySecretKey,
+		})
+	})
+}
+
+func (s *Server) handleCheckInSecret() http.Handler {
+	type Response struct {
+		Deadline time.Time `json:"deadline"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.CheckInSecret"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		checkInToken, ok := mux.Vars(r)["token"]
+		if !ok {
+			logger.Error("failed to get check-in token")
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "missing check-in token",
+			})
+
+			return
+		}
+
+		deadline, err := usecase.CheckInSecret(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.hasher,
+			s.encoder,
+		)(ctx, usecase.CheckInSecretDTO{
+			CheckInToken: checkInToken,
+		})
+		if err != nil {
+			logger.Error("failed to check in secret", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to check in secret",
+			}
+
+			if errors.Is(err, model.ErrSecretNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrSecretNotFound.Error(),
+				}
+			}
+
+			if errors.Is(err, model.ErrSecretReleased) {
+				code = http.StatusConflict
+				res = map[string]string{
+					"error": model.ErrSecretReleased.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			Deadline: deadline,
+		})
+	})
+}
+
+func (s *Server) handleCreateSecretRequest() http.Handler {
+	type Request struct {
+		Note         string           `json:"note"`
+		TTL          int64            `json:"ttl"`
+		SecretPhrase model.SecretText `json:"secretPhrase"`
+	}
+
+	type Response struct {
+		RequestKey       string `json:"requestKey"`
+		SecretKey        string `json:"secretKey"`
+		WithSecretPhrase bool   `json:"withSecretPhrase"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.CreateSecretRequest"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = decodeProtectedJSON(r.Body, s.conf.MaxUploadSize, &req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		defer cryptor.Wipe(req.SecretPhrase)
+
+		created, err := usecase.CreateSecretRequest(
+			s.store.RequestRepo(),
+			s.hasher,
+			s.encoder,
+		)(ctx, usecase.CreateSecretRequestDTO{
+			Note:         req.Note,
+			TTL:          req.TTL,
+			SecretPhrase: req.SecretPhrase,
+		})
+		if err != nil {
+			logger.Error("failed to create secret request", "error", err)
+
+			w.WriteHeader(http.StatusInternalServerError)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "failed to create secret request",
+			})
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(Response{
+			RequestKey:       created.RequestKey,
+			SecretKey:        created.SecretKey,
+			WithSecretPhrase: len(req.SecretPhrase) > 0,
+		})
+	})
+}
+
+func (s *Server) handleGetSecretRequest() http.Handler {
+	type Response struct {
+		Request model.SecretRequest `json:"request"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.GetSecretRequest"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		requestKey, ok := mux.Vars(r)["key"]
+		if !ok {
+			logger.Error("failed to get request key")
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "missing request key",
+			})
+
+			return
+		}
+
+		request, err := usecase.GetSecretRequest(
+			s.store.RequestRepo(),
+			s.encoder,
+		)(ctx, usecase.GetSecretRequestDTO{
+			RequestKey: requestKey,
+		})
+		if err != nil {
+			logger.Error("failed to get secret request", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to get secret request",
+			}
+
+			if errors.Is(err, model.ErrRequestNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrRequestNotFound.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			Request: request,
+		})
+	})
+}
+
+func (s *Server) handleFulfilSecretRequest() http.Handler {
+	type Request struct {
+		Message model.SecretText    `json:"message"`
+		Fields  []model.SecretField `json:"fields"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.FulfilSecretRequest"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		requestKey, ok := mux.Vars(r)["key"]
+		if !ok {
+			logger.Error("failed to get request key")
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "missing request key",
+			})
+
+			return
+		}
+
+		var req Request
+		err = decodeProtectedJSON(r.Body, s.conf.MaxUploadSize, &req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		defer cryptor.Wipe(req.Message)
+
+		_, err = usecase.FulfilSecretRequest(
+			s.store.RequestRepo(),
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.encoder,
+			s.encryptor,
+			s.sealer,
+		)(ctx, usecase.FulfilSecretRequestDTO{
+			RequestKey: requestKey,
+			Message:    req.Message,
+			Fields:     req.Fields,
+		})
+		if err != nil {
+			logger.Error("failed to fulfil secret request", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to fulfil secret request",
+			}
+
+			if errors.Is(err, model.ErrRequestNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrRequestNotFound.Error(),
+				}
+			}
+
+			if errors.Is(err, model.ErrInvalidSecret) {
+				code = http.StatusBadRequest
+				res = map[string]string{
+					"error": model.ErrInvalidSecret.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"status": "ok",
+		})
+	})
+}
+
+func (s *Server) handleUploadOptions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(headerTusVersion, tusVersion)
+		w.Header().Set(headerTusExtension, tusExtensions)
+		w.Header().Set(headerTusMaxSize, strconv.FormatInt(s.conf.MaxResumableUploadSize, 10))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) handleCreateUpload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.CreateUpload"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		length, err := parseUploadInt(r, headerUploadLength)
+		if err != nil {
+			logger.Error("failed to parse upload length", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+			})
+
+			return
+		}
+
+		meta, err := parseUploadMetadata(r.Header.Get(headerUploadMeta))
+		if err != nil {
+			logger.Error("failed to parse upload metadata", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+			})
+
+			return
+		}
+
+		created, err := usecase.CreateUpload(
+			s.store.UploadRepo(),
+			s.encoder,
+			usecase.UploadOptions{
+				MaxSize: s.conf.MaxResumableUploadSize,
+				TTL:     s.conf.UploadTTL,
+			},
+		)(ctx, usecase.CreateUploadDTO{
+			Length:      length,
+			Name:        meta["filename"],
+			ContentType: meta["filetype"],
+		})
+		if err != nil {
+			logger.Error("failed to create upload", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to create upload",
+			}
+
+			if errors.Is(err, model.ErrUploadTooLarge) {
+				code = http.StatusRequestEntityTooLarge
+				res = map[string]string{
+					"error": model.ErrUploadTooLarge.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.Header().Set("Location", "/api/uploads/"+created.UploadKey)
+		w.Header().Set(headerUploadExpires, uploadExpires(created.ExpiredAt))
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func (s *Server) handleGetUpload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.GetUpload"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		upload, err := usecase.GetUpload(
+			s.store.UploadRepo(),
+			s.encoder,
+		)(ctx, usecase.GetUploadDTO{
+			UploadKey: mux.Vars(r)["key"],
+		})
+		if err != nil {
+			logger.Error("failed to get upload", "error", err)
+
+			code := http.StatusInternalServerError
+			if errors.Is(err, model.ErrUploadNotFound) {
+				code = http.StatusNotFound
+			}
+
+			w.WriteHeader(code)
+
+			return
+		}
+
+		setUploadHeaders(w, upload)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func (s *Server) handleWriteUpload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.WriteUpload"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		if r.Header.Get("Content-Type") != tusContentType {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "unsupported content type",
+			})
+
+			return
+		}
+
+		offset, err := parseUploadInt(r, headerUploadOffset)
+		if err != nil {
+			logger.Error("failed to parse upload offset", "error", err)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+			})
+
+			return
+		}
+
+		newOffset, err := usecase.WriteUpload(
+			s.store.UploadRepo(),
+			s.encoder,
+			s.streamer,
+			s.blobs,
+		)(ctx, usecase.WriteUploadDTO{
+			UploadKey: mux.Vars(r)["key"],
+			Offset:    offset,
+			Data:      r.Body,
+		})
+		if err != nil {
+			logger.Error("failed to write upload", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to write upload",
+			}
+
+			if errors.Is(err, model.ErrUploadNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrUploadNotFound.Error(),
+				}
+			}
+
+			if errors.Is(err, model.ErrUploadOffsetMismatch) {
+				code = http.StatusConflict
+				res = map[string]string{
+					"error": model.ErrUploadOffsetMismatch.Error(),
+				}
+			}
+
+			if errors.Is(err, model.ErrUploadTooLarge) {
+				code = http.StatusRequestEntityTooLarge
+				res = map[string]string{
+					"error": model.ErrUploadTooLarge.Error(),
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.Header().Set(headerUploadOffset, strconv.FormatInt(newOffset, 10))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) handleDeleteUpload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.DeleteUpload"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		_, err = usecase.DeleteUpload(
+			s.store.UploadRepo(),
+			s.encoder,
+			s.blobs,
+		)(ctx, usecase.DeleteUploadDTO{
+			UploadKey: mux.Vars(r)["key"],
+		})
+		if err != nil {
+			logger.Error("failed to delete upload", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to delete upload",
+			}
+
+			if errors.Is(err, model.ErrUploadNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrUploadNotFound.Error(),
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) handleCreateE2ESecret() http.Handler {
+	type Request struct {
+		Ciphertext       string            `json:"ciphertext"`
+		TTL              int64             `json:"ttl"`
+		SecretPhrase     model.SecretText  `json:"secretPhrase"`
+		AvailableFrom    time.Time         `json:"availableFrom"`
+		AllowedCIDRs     []string          `json:"allowedCidrs"`
+		AllowedCountries []string          `json:"allowedCountries"`
+		DeniedCountries  []string          `json:"deniedCountries"`
+		Signature        *signatureRequest `json:"signature"`
+	}
+
+	type Response struct {
+		SecretKey        string `json:"secretKey"`
+		WithSecretPhrase bool   `json:"withSecretPhrase"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.CreateE2ESecret"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = json.NewDecoder(http.MaxBytesReader(w, r.Body, s.conf.MaxUploadSize)).Decode(&req)
+		if err != nil || req.Ciphertext == "" {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		defer cryptor.Wipe(req.SecretPhrase)
+
+		created, err := usecase.CreateSecret(
+			s.store.SecretRepo(),
+			s.store.RequestRepo(),
+			s.store.StatusRepo(),
+			s.store.EventRepo(),
+			s.store.UploadRepo(),
+			s.store.SignerRepo(),
+			s.hasher,
+			s.encoder,
+			s.encryptor,
+			s.streamer,
+			s.sealer,
+			s.kems,
+			s.blobs,
+			s.locator,
+			s.mailer,
+			s.mode,
+		)(ctx, usecase.CreateSecretDTO{
+			Ciphertext:       req.Ciphertext,
+			TTL:              req.TTL,
+			SecretPhrase:     req.SecretPhrase,
+			AvailableFrom:    req.AvailableFrom,
+			AllowedCIDRs:     req.AllowedCIDRs,
+			AllowedCountries: req.AllowedCountries,
+			DeniedCountries:  req.DeniedCountries,
+			Signature:        req.Signature.dto(),
+		})
+		if err != nil {
+			logger.Error("failed to create secret", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to create secret",
+			}
+
+			if errors.Is(err, model.ErrInvalidSecret) {
+				code = http.StatusBadRequest
+				res = map[string]string{
+					"error": model.ErrInvalidSecret.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(Response{
+			SecretKey:        created.SecretKey,
+			WithSecretPhrase: len(req.SecretPhrase) > 0,
+		})
+	})
+}
+
+func (s *Server) handleGetE2ESecret() http.Handler {
+	type Request struct {
+		SecretPhrase model.SecretText `json:"secretPhrase"`
+	}
+
+	type Response struct {
+		ID         int                 `json:"id"`
+		CreatedAt  time.Time           `json:"createdAt"`
+		Ciphertext string              `json:"ciphertext"`
+		Signer     *model.SecretSigner `json:"signer,omitempty"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.GetE2ESecret"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = decodeProtectedJSON(r.Body, s.conf.MaxUploadSize, &req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		defer cryptor.Wipe(req.SecretPhrase)
+
+		secret, err := usecase.GetSecret(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.SignerRepo(),
+			s.store.DownloadRepo(),
+			s.hasher,
+			s.encoder,
+			s.encryptor,
+			s.sealer,
+			s.blobs,
+			s.locator,
+			usecase.LockoutOptions{
+				MaxAttempts: s.conf.MaxAttempts,
+				BaseDelay:   s.conf.AttemptDelay,
+				MaxDelay:    s.conf.MaxAttemptDelay,
+			},
+			s.conf.AttachmentDownloadTTL,
+		)(ctx, usecase.GetSecretDTO{
+			SecretKey:    mux.Vars(r)["key"],
+			SecretPhrase: req.SecretPhrase,
+			ClientIP:     realip.FromRequest(r, s.trustedProxies),
+			E2E:          true,
+		})
+		if err != nil {
+			logger.Error("failed to get secret", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to get secret",
+			}
+
+			if errors.Is(err, model.ErrSecretNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrSecretNotFound.Error(),
+				}
+			}
+
+			var notYetAvailableErr *model.SecretNotYetAvailableError
+			if errors.As(err, &notYetAvailableErr) {
+				code = http.StatusTooEarly
+				res = map[string]string{
+					"error":         model.ErrSecretNotYetAvailable.Error(),
+					"availableFrom": notYetAvailableErr.AvailableFrom.Format(time.RFC3339),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			ID:         secret.ID,
+			CreatedAt:  secret.CreatedAt,
+			Ciphertext: string(secret.Message),
+			Signer:     secret.Signer,
+		})
+	})
+}
+
+func (s *Server) handleRegisterSigner() http.Handler {
+	type Request struct {
+		Name      string `json:"name"`
+		PublicKey string `json:"publicKey"`
+		Proof     string `json:"proof"`
+	}
+
+	type Response struct {
+		Signer model.Signer `json:"signer"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.RegisterSigner"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		signer, err := usecase.RegisterSigner(
+			s.store.SignerRepo(),
+		)(ctx, usecase.RegisterSignerDTO{
+			Name:      req.Name,
+			PublicKey: req.PublicKey,
+			Proof:     req.Proof,
+		})
+		if err != nil {
+			logger.Error("failed to register signer", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to register signer",
+			}
+
+			if errors.Is(err, model.ErrInvalidSigner) {
+				code = http.StatusBadRequest
+				res = map[string]string{
+					"error": model.ErrInvalidSigner.Error(),
+				}
+			}
+
+			if errors.Is(err, model.ErrSignerExists) {
+				code = http.StatusConflict
+				res = map[string]string{
+					"error": model.ErrSignerExists.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(Response{
+			Signer: signer,
+		})
+	})
+}
+
+func (s *Server) handleGetSigner() http.Handler {
+	type Response struct {
+		Signer model.Signer `json:"signer"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.GetSigner"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		signer, err := usecase.GetSigner(
+			s.store.SignerRepo(),
+		)(ctx, mux.Vars(r)["fingerprint"])
+		if err != nil {
+			logger.Error("failed to get signer", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to get signer",
+			}
+
+			if errors.Is(err, model.ErrSignerNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrSignerNotFound.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			Signer: signer,
+		})
+	})
+}
+
+func (s *Server) handleIssueSignatureNonce() http.Handler {
+	type Response struct {
+		Nonce     string    `json:"nonce"`
+		ExpiredAt time.Time `json:"expiredAt"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.IssueSignatureNonce"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		nonce, err := usecase.IssueSignatureNonce(
+			s.store.SignerRepo(),
+			s.conf.SignatureNonceTTL,
+		)(ctx, mux.Vars(r)["fingerprint"])
+		if err != nil {
+			logger.Error("failed to issue signature nonce", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to issue signature nonce",
+			}
+
+			if errors.Is(err, model.ErrSignerNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrSignerNotFound.Error(),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(Response{
+			Nonce:     nonce.Nonce,
+			ExpiredAt: nonce.ExpiredAt,
+		})
+	})
+}
+
+func (s *Server) handleSubmitSecretShare() http.Handler {
+	type Response struct {
+		Secret    *model.Secret `json:"secret,omitempty"`
+		Submitted int           `json:"submitted"`
+		Threshold int           `json:"threshold"`
+		ExpiredAt *time.Time    `json:"expiredAt,omitempty"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.SubmitSecretShare"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		submitted, err := usecase.SubmitSecretShare(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.ShareRepo(),
+			s.store.SignerRepo(),
+			s.store.DownloadRepo(),
+			s.encoder,
+			s.encryptor,
+			s.sealer,
+			s.blobs,
+			s.locator,
+			s.conf.AttachmentDownloadTTL,
+		)(ctx, usecase.SubmitSecretShareDTO{
+			ShareKey: mux.Vars(r)["key"],
+			ClientIP: realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to submit secret share", "error", err)
+
+			code := http.StatusInternalServerError
+			res := map[string]string{
+				"error": "failed to submit secret share",
+			}
+
+			if errors.Is(err, model.ErrSecretNotFound) {
+				code = http.StatusNotFound
+				res = map[string]string{
+					"error": model.ErrSecretNotFound.Error(),
+				}
+			}
+
+			if errors.Is(err, model.ErrShareAlreadySubmitted) {
+				code = http.StatusConflict
+				res = map[string]string{
+					"error": model.ErrShareAlreadySubmitted.Error(),
+				}
+			}
+
+			var notYetAvailableErr *model.SecretNotYetAvailableError
+			if errors.As(err, &notYetAvailableErr) {
+				code = http.StatusTooEarly
+				res = map[string]string{
+					"error":         model.ErrSecretNotYetAvailable.Error(),
+					"availableFrom": notYetAvailableErr.AvailableFrom.Format(time.RFC3339),
+				}
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		defer cryptor.Wipe(submitted.Secret.Message)
+
+		if !submitted.Revealed {
+			w.WriteHeader(http.StatusAccepted)
+			err = json.NewEncoder(w).Encode(Response{
+				Submitted: submitted.Submitted,
+				Threshold: submitted.Threshold,
+				ExpiredAt: &submitted.ExpiredAt,
+			})
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = encodeProtectedJSON(w, Response{
+			Secret:    &submitted.Secret,
+			Submitted: submitted.Submitted,
+			Threshold: submitted.Threshold,
+		})
+	})
+}
+
+func (s *Server) handlePakeUnavailable() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.PakeUnavailable"
+
+		ctx := r.Context()
+		s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		).Info("pake requested in fips mode")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotImplemented)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"error": "pake is not available in fips mode",
+		})
+	})
+}
+
+func (s *Server) handleGetPakeParams() http.Handler {
+	type Response struct {
+		Suite  string      `json:"suite"`
+		Salt   []byte      `json:"salt"`
+		Params pake.Params `json:"params"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.GetPakeParams"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		params, err := usecase.GetPakeParams(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.encoder,
+			s.encryptor,
+			s.blobs,
+			s.locator,
+			s.pakeOptions(),
+		)(ctx, usecase.GetPakeParamsDTO{
+			SecretKey: mux.Vars(r)["key"],
+			ClientIP:  realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to get pake params", "error", err)
+
+			code, res := ceremonyErrorResponse(err)
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			Suite:  params.Suite,
+			Salt:   params.Salt,
+			Params: params.Params,
+		})
+	})
+}
+
+func (s *Server) handleBeginPake() http.Handler {
+	type Request struct {
+		ShareP []byte `json:"shareP"`
+	}
+
+	type Response struct {
+		SessionKey string    `json:"sessionKey"`
+		ShareV     []byte    `json:"shareV"`
+		ConfirmV   []byte    `json:"confirmV"`
+		ExpiredAt  time.Time `json:"expiredAt"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.BeginPake"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		began, err := usecase.BeginPake(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.PakeRepo(),
+			s.encoder,
+			s.encryptor,
+			s.blobs,
+			s.locator,
+			s.pakeOptions(),
+		)(ctx, usecase.BeginPakeDTO{
+			SecretKey: mux.Vars(r)["key"],
+			ShareP:    req.ShareP,
+			ClientIP:  realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to begin pake", "error", err)
+
+			code, res := ceremonyErrorResponse(err)
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			SessionKey: began.SessionKey,
+			ShareV:     began.ShareV,
+			ConfirmV:   began.ConfirmV,
+			ExpiredAt:  began.ExpiredAt,
+		})
+	})
+}
+
+func (s *Server) handleFinishPake() http.Handler {
+	type Request struct {
+		SessionKey string `json:"sessionKey"`
+		ConfirmP   []byte `json:"confirmP"`
+	}
+
+	type Sealed struct {
+		Secret model.Secret `json:"secret"`
+	}
+
+	type Response struct {
+		Sealed []byte `json:"sealed"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.FinishPake"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		finished, err := usecase.FinishPake(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.SignerRepo(),
+			s.store.DownloadRepo(),
+			s.store.PakeRepo(),
+			s.encoder,
+			s.encryptor,
+			s.sealer,
+			s.blobs,
+			s.locator,
+			s.pakeOptions(),
+			s.conf.AttachmentDownloadTTL,
+		)(ctx, usecase.FinishPakeDTO{
+			SecretKey:  mux.Vars(r)["key"],
+			SessionKey: req.SessionKey,
+			ConfirmP:   req.ConfirmP,
+			ClientIP:   realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to finish pake", "error", err)
+
+			code, res := ceremonyErrorResponse(err)
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		defer cryptor.Wipe(finished.Secret.Message)
+
+		payload, err := json.Marshal(Sealed{
+			Secret: finished.Secret,
+		})
+		if err != nil {
+			logger.Error("failed to encode secret", "error", err)
+
+			w.WriteHeader(http.StatusInternalServerError)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "failed to get secret",
+			})
+
+			return
+		}
+
+		sealed, err := pake.Seal(finished.SharedKey, payload)
+		clear(payload)
+		if err != nil {
+			logger.Error("failed to seal secret", "error", err)
+
+			w.WriteHeader(http.StatusInternalServerError)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "failed to get secret",
+			})
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			Sealed: sealed,
+		})
+	})
+}
+
+func (s *Server) handleBeginWebAuthn() http.Handler {
+	type Credential struct {
+		Type string `json:"type"`
+		ID   []byte `json:"id"`
+	}
+
+	type PublicKey struct {
+		Challenge        []byte       `json:"challenge"`
+		RPID             string       `json:"rpId"`
+		Timeout          int64        `json:"timeout"`
+		AllowCredentials []Credential `json:"allowCredentials"`
+		UserVerification string       `json:"userVerification"`
+	}
+
+	type Response struct {
+		SessionKey string    `json:"sessionKey"`
+		ExpiredAt  time.Time `json:"expiredAt"`
+		PublicKey  PublicKey `json:"publicKey"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.BeginWebAuthn"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		opts := s.webauthnOptions()
+		began, err := usecase.BeginWebAuthn(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.WebAuthnRepo(),
+			s.encoder,
+			s.encryptor,
+			s.blobs,
+			s.locator,
+			opts,
+		)(ctx, usecase.BeginWebAuthnDTO{
+			SecretKey: mux.Vars(r)["key"],
+			ClientIP:  realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to begin webauthn", "error", err)
+
+			code, res := ceremonyErrorResponse(err)
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		credentials := make([]Credential, 0, len(began.Credentials))
+		for _, id := range began.Credentials {
+			credentials = append(credentials, Credential{Type: "public-key", ID: id})
+		}
+
+		userVerification := "preferred"
+		if began.UserVerification {
+			userVerification = "required"
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(Response{
+			SessionKey: began.SessionKey,
+			ExpiredAt:  began.ExpiredAt,
+			PublicKey: PublicKey{
+				Challenge:        began.Challenge,
+				RPID:             began.RPID,
+				Timeout:          opts.SessionTTL.Milliseconds(),
+				AllowCredentials: credentials,
+				UserVerification: userVerification,
+			},
+		})
+	})
+}
+
+func (s *Server) handleFinishWebAuthn() http.Handler {
+	type Request struct {
+		SessionKey        string `json:"sessionKey"`
+		CredentialID      []byte `json:"credentialId"`
+		ClientDataJSON    []byte `json:"clientDataJSON"`
+		AuthenticatorData []byte `json:"authenticatorData"`
+		Signature         []byte `json:"signature"`
+	}
+
+	type Response struct {
+		Secret model.Secret `json:"secret"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.FinishWebAuthn"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		secret, err := usecase.FinishWebAuthn(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.SignerRepo(),
+			s.store.DownloadRepo(),
+			s.store.WebAuthnRepo(),
+			s.encoder,
+			s.encryptor,
+			s.sealer,
+			s.blobs,
+			s.locator,
+			s.webauthnOptions(),
+			s.conf.AttachmentDownloadTTL,
+		)(ctx, usecase.FinishWebAuthnDTO{
+			SecretKey:         mux.Vars(r)["key"],
+			SessionKey:        req.SessionKey,
+			CredentialID:      req.CredentialID,
+			ClientDataJSON:    req.ClientDataJSON,
+			AuthenticatorData: req.AuthenticatorData,
+			Signature:         req.Signature,
+			ClientIP:          realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to finish webauthn", "error", err)
+
+			code, res := ceremonyErrorResponse(err)
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		defer cryptor.Wipe(secret.Message)
+
+		w.WriteHeader(http.StatusOK)
+		err = encodeProtectedJSON(w, Response{
+			Secret: secret,
+		})
+	})
+}
+
+func (s *Server) handleSendEmailCode() http.Handler {
+	type Response struct {
+		Email     string    `json:"email"`
+		ExpiredAt time.Time `json:"expiredAt"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.SendEmailCode"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		sent, err := usecase.SendEmailCode(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.EmailCodeRepo(),
+			s.hasher,
+			s.encoder,
+			s.encryptor,
+			s.blobs,
+			s.mailer,
+			s.locator,
+			s.emailCodeOptions(),
+		)(ctx, usecase.SendEmailCodeDTO{
+			SecretKey: mux.Vars(r)["key"],
+			ClientIP:  realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to send email code", "error", err)
+
+			code, res := ceremonyErrorResponse(err)
+			if errors.Is(err, model.ErrEmailCodeRecentlySent) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(s.conf.EmailCodeResendInterval.Seconds())))
+			}
+
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		err = json.NewEncoder(w).Encode(Response{
+			Email:     sent.Email,
+			ExpiredAt: sent.ExpiredAt,
+		})
+	})
+}
+
+func (s *Server) handleVerifyEmailCode() http.Handler {
+	type Request struct {
+		Code string `json:"code"`
+	}
+
+	type Response struct {
+		Secret model.Secret `json:"secret"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "server.VerifyEmailCode"
+		var err error
+
+		ctx := r.Context()
+		logger := s.logger.With(
+			"operation", op,
+			requestid.LogKey, requestid.Extract(ctx),
+		)
+
+		defer func() {
+			if err != nil {
+				logger.Error("failed to handle request", "error", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Request
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			logger.Error("failed to decode request", "error", err)
+
+			w.WriteHeader(http.StatusBadRequest)
+			err = json.NewEncoder(w).Encode(map[string]string{
+				"error": "invalid request",
+			})
+
+			return
+		}
+
+		secret, err := usecase.VerifyEmailCode(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.store.SignerRepo(),
+			s.store.DownloadRepo(),
+			s.store.EmailCodeRepo(),
+			s.hasher,
+			s.encoder,
+			s.encryptor,
+			s.sealer,
+			s.blobs,
+			s.locator,
+			s.emailCodeOptions(),
+			s.conf.AttachmentDownloadTTL,
+		)(ctx, usecase.VerifyEmailCodeDTO{
+			SecretKey: mux.Vars(r)["key"],
+			Code:      req.Code,
+			ClientIP:  realip.FromRequest(r, s.trustedProxies),
+		})
+		if err != nil {
+			logger.Error("failed to verify email code", "error", err)
+
+			code, res := ceremonyErrorResponse(err)
+			w.WriteHeader(code)
+			err = json.NewEncoder(w).Encode(res)
+
+			return
+		}
+
+		defer cryptor.Wipe(secret.Message)
+
+		w.WriteHeader(http.StatusOK)
+		err = encodeProtectedJSON(w, Response{
+			Secret: secret,
 		})
 	})
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/protomem/secrets-keeper/pkg/requestid"
+	"github.com/protomem/secrets-keeper/pkg/timing"
 	"github.com/rs/cors"
 )
 
@@ -39,11 +40,19 @@ func (s *Server) recovery() mux.MiddlewareFunc {
 	}
 }
 
+func (s *Server) normalizeTiming() mux.MiddlewareFunc {
+	return timing.Middleware(s.conf.ResponseTimeQuantum)
+}
+
 func (s *Server) CORS() mux.MiddlewareFunc {
 	return cors.New(cors.Options{
 		AllowCredentials: true,
 		AllowedOrigins:   []string{"*"},
 		AllowedHeaders:   []string{"*"},
-		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions},
+		AllowedMethods: []string{
+			http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		ExposedHeaders: append([]string{"Content-Disposition"}, tusExposedHeaders...),
 	}).Handler
 }
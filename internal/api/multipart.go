@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/protomem/secrets-keeper/internal/usecase"
+)
+
+const (
+	multipartSecretField      = "secret"
+	multipartAttachmentsField = "attachments"
+)
+
+func decodeMultipartSecret[T any](r *http.Request, limit int64) (T, usecase.AttachmentSource, error) {
+	var req T
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return req, nil, fmt.Errorf("read multipart body: %w", err)
+	}
+
+	part, err := reader.NextPart()
+	if err != nil {
+		return req, nil, fmt.Errorf("read %s field: %w", multipartSecretField, err)
+	}
+
+	if part.FormName() != multipartSecretField {
+		return req, nil, fmt.Errorf("expected %s field first, got %q", multipartSecretField, part.FormName())
+	}
+
+	err = decodeProtectedJSON(part, limit, &req)
+	if err != nil {
+		return req, nil, fmt.Errorf("decode %s field: %w", multipartSecretField, err)
+	}
+
+	part, err = reader.NextPart()
+	if errors.Is(err, io.EOF) {
+		return req, nil, nil
+	}
+	if err != nil {
+		return req, nil, fmt.Errorf("read %s field: %w", multipartAttachmentsField, err)
+	}
+
+	if part.FormName() != multipartAttachmentsField {
+		return req, nil, fmt.Errorf("unexpected multipart field %q", part.FormName())
+	}
+
+	return req, &multipartAttachments{reader: reader, pending: part}, nil
+}
+
+type multipartAttachments struct {
+	reader  *multipart.Reader
+	pending *multipart.Part
+}
+
+func (a *multipartAttachments) Next() (usecase.AttachmentDTO, error) {
+	part := a.pending
+	a.pending = nil
+
+	if part == nil {
+		var err error
+		part, err = a.reader.NextPart()
+		if err != nil {
+			return usecase.AttachmentDTO{}, err
+		}
+
+		if part.FormName() != multipartAttachmentsField {
+			return usecase.AttachmentDTO{}, fmt.Errorf("unexpected multipart field %q", part.FormName())
+		}
+	}
+
+	return usecase.AttachmentDTO{
+		Name:        part.FileName(),
+		ContentType: part.Header.Get("Content-Type"),
+		Data:        part,
+	}, nil
+}
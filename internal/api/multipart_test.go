@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type multipartField struct {
+	name     string
+	fileName string
+	data     string
+}
+
+func newMultipartRequest(t *testing.T, fields ...multipartField) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, field := range fields {
+		var (
+			w   io.Writer
+			err error
+		)
+		if field.fileName != "" {
+			w, err = mw.CreateFormFile(field.name, field.fileName)
+		} else {
+			w, err = mw.CreateFormField(field.name)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = io.WriteString(w, field.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/secrets", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return r
+}
+
+func TestDecodeMultipartSecretStreamsAttachments(t *testing.T) {
+	r := newMultipartRequest(t,
+		multipartField{name: multipartSecretField, data: `{"message":"hello"}`},
+		multipartField{name: multipartAttachmentsField, fileName: "a.txt", data: "first"},
+		multipartField{name: multipartAttachmentsField, fileName: "b.txt", data: "second"},
+	)
+
+	req, source, err := decodeMultipartSecret[struct {
+		Message string `json:"message"`
+	}](r, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Message != "hello" {
+		t.Fatalf("message = %q", req.Message)
+	}
+
+	for _, want := range []multipartField{{fileName: "a.txt", data: "first"}, {fileName: "b.txt", data: "second"}} {
+		dto, err := source.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := io.ReadAll(dto.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dto.Name != want.fileName || string(data) != want.data {
+			t.Fatalf("got %q with %q, want %q with %q", dto.Name, data, want.fileName, want.data)
+		}
+	}
+
+	if _, err := source.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF after the last attachment, got %v", err)
+	}
+}
+
+func TestDecodeMultipartSecretWithoutAttachments(t *testing.T) {
+	r := newMultipartRequest(t, multipartField{name: multipartSecretField, data: `{}`})
+
+	_, source, err := decodeMultipartSecret[struct{}](r, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != nil {
+		t.Fatal("expected no attachment source")
+	}
+}
+
+func TestDecodeMultipartSecretRequiresSecretFirst(t *testing.T) {
+	r := newMultipartRequest(t,
+		multipartField{name: multipartAttachmentsField, fileName: "a.txt", data: "first"},
+		multipartField{name: multipartSecretField, data: `{}`},
+	)
+
+	_, _, err := decodeMultipartSecret[struct{}](r, 1<<20)
+	if err == nil {
+		t.Fatal("expected an error when attachments precede the secret field")
+	}
+}
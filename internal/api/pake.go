@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/usecase"
+	"github.com/protomem/secrets-keeper/pkg/pake"
+)
+
+type phraseVerifierRequest struct {
+	Salt   []byte      `json:"salt"`
+	Params pake.Params `json:"params"`
+	W0     []byte      `json:"w0"`
+	L      []byte      `json:"l"`
+}
+
+func (req *phraseVerifierRequest) dto() *usecase.PhraseVerifierDTO {
+	if req == nil {
+		return nil
+	}
+
+	return &usecase.PhraseVerifierDTO{
+		Salt:   req.Salt,
+		Params: req.Params,
+		W0:     req.W0,
+		L:      req.L,
+	}
+}
+
+func (s *Server) pakeOptions() usecase.PakeOptions {
+	return usecase.PakeOptions{
+		SessionTTL: s.conf.PakeSessionTTL,
+		Lockout: usecase.LockoutOptions{
+			MaxAttempts: s.conf.MaxAttempts,
+			BaseDelay:   s.conf.AttemptDelay,
+			MaxDelay:    s.conf.MaxAttemptDelay,
+		},
+	}
+}
+
+func ceremonyErrorResponse(err error) (int, map[string]string) {
+	if errors.Is(err, model.ErrSecretNotFound) ||
+		errors.Is(err, model.ErrPakeSessionNotFound) ||
+		errors.Is(err, model.ErrWebAuthnSessionNotFound) ||
+		errors.Is(err, model.ErrEmailCodeNotFound) {
+		return http.StatusNotFound, map[string]string{
+			"error": model.ErrSecretNotFound.Error(),
+		}
+	}
+
+	if errors.Is(err, model.ErrEmailCodeRecentlySent) {
+		return http.StatusTooManyRequests, map[string]string{
+			"error": model.ErrEmailCodeRecentlySent.Error(),
+		}
+	}
+
+	if errors.Is(err, model.ErrInvalidSecret) {
+		return http.StatusBadRequest, map[string]string{
+			"error": "invalid request",
+		}
+	}
+
+	var notYetAvailableErr *model.SecretNotYetAvailableError
+	if errors.As(err, &notYetAvailableErr) {
+		return http.StatusTooEarly, map[string]string{
+			"error":         model.ErrSecretNotYetAvailable.Error(),
+			"availableFrom": notYetAvailableErr.AvailableFrom.Format(time.RFC3339),
+		}
+	}
+
+	return http.StatusInternalServerError, map[string]string{
+		"error": "failed to get secret",
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+func decodeProtectedJSON(r io.Reader, limit int64, v any) error {
+	body, err := cryptor.ReadBuffer(r, limit)
+	if err != nil {
+		return err
+	}
+	defer body.Destroy()
+
+	return json.Unmarshal(body.Bytes(), v)
+}
+
+func encodeProtectedJSON(w http.ResponseWriter, v any) error {
+	body, err := cryptor.NewBuffer(0)
+	if err != nil {
+		return err
+	}
+	defer body.Destroy()
+
+	err = json.NewEncoder(body).Encode(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(body.Bytes())
+	return err
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func TestDecodeProtectedJSONSecretText(t *testing.T) {
+	var req struct {
+		Message      model.SecretText `json:"message"`
+		SecretPhrase model.SecretText `json:"secretPhrase"`
+	}
+
+	body := `{"message":"line\none \"quoted\"","secretPhrase":"correct horse"}`
+	err := decodeProtectedJSON(strings.NewReader(body), 1<<10, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(req.Message) != "line\none \"quoted\"" {
+		t.Fatalf("message = %q", req.Message)
+	}
+	if string(req.SecretPhrase) != "correct horse" {
+		t.Fatalf("secretPhrase = %q", req.SecretPhrase)
+	}
+
+	for _, text := range []model.SecretText{req.Message, req.SecretPhrase} {
+		cryptor.Wipe(text)
+		if !bytes.Equal(text, make([]byte, len(text))) {
+			t.Fatalf("Wipe() left %q", text)
+		}
+	}
+}
+
+func TestEncodeProtectedJSONSecret(t *testing.T) {
+	secret := model.Secret{
+		PayloadType: model.PayloadText,
+		Message:     model.SecretText("line\none <tag> \"quoted\""),
+	}
+
+	rec := httptest.NewRecorder()
+	err := encodeProtectedJSON(rec, struct {
+		Secret model.Secret `json:"secret"`
+	}{Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cryptor.Wipe(secret.Message)
+
+	var res struct {
+		Secret struct {
+			Message string `json:"message"`
+		} `json:"secret"`
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Secret.Message != "line\none <tag> \"quoted\"" {
+		t.Fatalf("message = %q", res.Secret.Message)
+	}
+}
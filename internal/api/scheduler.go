@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/usecase"
+)
+
+func (s *Server) startScheduler(ctx context.Context) {
+	logger := s.logger.With("operation", "server.Scheduler")
+
+	ticker := time.NewTicker(s.conf.SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		released, err := usecase.ReleaseMissedCheckInSecrets(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.notifier,
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to release missed check-in secrets", "error", err)
+		}
+
+		if released > 0 {
+			logger.Info("released missed check-in secrets", "count", released)
+		}
+
+		purged, err := usecase.PurgeExpiredSecrets(
+			s.store.SecretRepo(),
+			s.store.EventRepo(),
+			s.blobs,
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired secrets", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired secrets", "count", purged)
+		}
+
+		purged, err = usecase.PurgeExpiredDownloads(
+			s.store.DownloadRepo(),
+			s.blobs,
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired attachment downloads", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired attachment downloads", "count", purged)
+		}
+
+		purged, err = usecase.PurgeExpiredShares(
+			s.store.ShareRepo(),
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired shares", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired shares", "count", purged)
+		}
+
+		purged, err = usecase.PurgeExpiredSignatureNonces(
+			s.store.SignerRepo(),
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired signature nonces", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired signature nonces", "count", purged)
+		}
+
+		purged, err = usecase.PurgeExpiredPakeSessions(
+			s.store.PakeRepo(),
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired pake sessions", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired pake sessions", "count", purged)
+		}
+
+		purged, err = usecase.PurgeExpiredWebAuthnSessions(
+			s.store.WebAuthnRepo(),
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired webauthn sessions", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired webauthn sessions", "count", purged)
+		}
+
+		purged, err = usecase.PurgeExpiredEmailCodes(
+			s.store.EmailCodeRepo(),
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired email codes", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired email codes", "count", purged)
+		}
+
+		purged, err = usecase.PurgeExpiredUploads(
+			s.store.UploadRepo(),
+			s.blobs,
+		)(ctx, struct{}{})
+		if err != nil {
+			logger.Error("failed to purge expired uploads", "error", err)
+		}
+
+		if purged > 0 {
+			logger.Info("purged expired uploads", "count", purged)
+		}
+	}
+}
@@ -2,26 +2,46 @@ package api
 
 import (
 	"context"
+	"crypto/fips140"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/blobstore/fs"
+	"github.com/protomem/secrets-keeper/internal/blobstore/s3"
 	"github.com/protomem/secrets-keeper/internal/config"
 	"github.com/protomem/secrets-keeper/internal/cryptor"
 	"github.com/protomem/secrets-keeper/internal/cryptor/aes"
 	"github.com/protomem/secrets-keeper/internal/cryptor/base64"
+	"github.com/protomem/secrets-keeper/internal/cryptor/hex"
+	"github.com/protomem/secrets-keeper/internal/cryptor/hybrid"
 	"github.com/protomem/secrets-keeper/internal/cryptor/pkcs7"
+	"github.com/protomem/secrets-keeper/internal/cryptor/stream"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/geoip/maxmind"
+	"github.com/protomem/secrets-keeper/internal/mailer"
+	"github.com/protomem/secrets-keeper/internal/mailer/smtp"
+	"github.com/protomem/secrets-keeper/internal/notify"
+	"github.com/protomem/secrets-keeper/internal/notify/webhook"
 	"github.com/protomem/secrets-keeper/internal/passhash"
 	"github.com/protomem/secrets-keeper/internal/passhash/argon2"
+	"github.com/protomem/secrets-keeper/internal/passhash/bcrypt"
+	"github.com/protomem/secrets-keeper/internal/passhash/pbkdf2"
 	"github.com/protomem/secrets-keeper/internal/storage"
 	"github.com/protomem/secrets-keeper/pkg/closer"
+	"github.com/protomem/secrets-keeper/pkg/hardening"
 	"github.com/protomem/secrets-keeper/pkg/logging"
 	"github.com/protomem/secrets-keeper/pkg/logging/stdlog"
+	"github.com/protomem/secrets-keeper/pkg/realip"
 )
 
 type Server struct {
@@ -32,8 +52,20 @@ type Server struct {
 
 	hasher passhash.Hasher
 
+	mode      cryptor.Mode
 	encoder   cryptor.Encoder
 	encryptor cryptor.Encryptor
+	streamer  cryptor.StreamEncryptor
+	sealer    *envelope.Envelope
+	kems      *cryptor.KEMRegistry
+
+	blobs    blobstore.Store
+	notifier notify.Notifier
+	mailer   mailer.Mailer
+	locator  geoip.Locator
+	watcher  *maxmind.Locator
+
+	trustedProxies []netip.Prefix
 
 	router *mux.Router
 	server *http.Server
@@ -53,6 +85,15 @@ func New(conf config.Config) (*Server, error) {
 
 	logger.Debug("server configured ...", "config", conf)
 
+	if conf.ProcessHardening {
+		err = hardening.Apply()
+		if errors.Is(err, hardening.ErrUnsupported) {
+			logger.Info("process hardening skipped", "error", err)
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: harden process: %s", err, op)
+		}
+	}
+
 	store, err := storage.New(ctx, logger, conf.Database)
 	if err != nil {
 		return nil, fmt.Errorf("%w: init storage: %s", err, op)
@@ -63,11 +104,124 @@ func New(conf config.Config) (*Server, error) {
 		return nil, fmt.Errorf("%w: migrate: %s", err, op)
 	}
 
-	hasher := argon2.NewHasher(base64.NewEncoder(false), argon2.DefaultOptions)
+	mode, err := cryptor.ParseMode(conf.CryptoMode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse crypto mode: %s", err, op)
+	}
+
+	logger.Info("crypto mode selected", "mode", mode.String(), "fips140", fips140.Enabled())
+
+	encoders := cryptor.NewRegistry[cryptor.Encoder](mode)
+	encoders.Register("base64", base64.NewEncoder(false), true)
+	encoders.Register("base64url", base64.NewEncoder(true), true)
+	encoders.Register("hex", hex.NewEncoder(), true)
+
+	encoder, err := encoders.Get("base64url")
+	if err != nil {
+		return nil, fmt.Errorf("%w: init encoder: %s", err, op)
+	}
+
+	rawEncoder, err := encoders.Get("base64")
+	if err != nil {
+		return nil, fmt.Errorf("%w: init encoder: %s", err, op)
+	}
+
+	argon2Hasher := argon2.NewHasher(rawEncoder, argon2.DefaultOptions)
+	bcryptHasher := bcrypt.NewHasher(bcrypt.DefaultCost)
+	pbkdf2Hasher := pbkdf2.NewHasher(rawEncoder, pbkdf2.DefaultOptions)
+
+	hashers := passhash.NewRegistry(mode)
+	hashers.Register("argon2id", argon2Hasher, false)
+	hashers.Register("bcrypt", bcryptHasher, false)
+	hashers.Register("pbkdf2-sha256", pbkdf2Hasher, true)
+
+	generator, err := hashers.Get(conf.PasswordHasher)
+	if err != nil {
+		return nil, fmt.Errorf("%w: init hasher: %s", err, op)
+	}
+
+	hasher := passhash.NewDispatcher(generator, hashers.Allowed()...)
+
+	encryptors := cryptor.NewRegistry[cryptor.Encryptor](mode)
+	encryptors.Register("aes-cbc", aes.NewEncryptor(rawEncoder, pkcs7.NewPaddinger()), true)
+
+	encryptor, err := encryptors.Get("aes-cbc")
+	if err != nil {
+		return nil, fmt.Errorf("%w: init encryptor: %s", err, op)
+	}
+
+	chunkedStreamer, err := stream.NewEncryptor(conf.StreamChunkSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: init stream encryptor: %s", err, op)
+	}
+
+	streamers := cryptor.NewRegistry[cryptor.StreamEncryptor](mode)
+	streamers.Register("aes-gcm", chunkedStreamer, true)
+
+	streamer, err := streamers.Get("aes-gcm")
+	if err != nil {
+		return nil, fmt.Errorf("%w: init stream encryptor: %s", err, op)
+	}
+
+	kems := cryptor.NewKEMRegistry()
+	if mode != cryptor.ModeFIPS {
+		kems = cryptor.NewKEMRegistry(hybrid.NewKEM())
+	}
+
+	err = selfTest(mode, encoders, hashers, encryptors, streamers)
+	if err != nil {
+		return nil, fmt.Errorf("%w: self-test: %s", err, op)
+	}
+
+	codec, err := envelope.ParseCodec(conf.CompressionCodec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse compression codec: %s", err, op)
+	}
+
+	padding, err := envelope.ParsePadding(conf.Padding)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse padding: %s", err, op)
+	}
+
+	sealer, err := envelope.New(envelope.Options{
+		Codec:     codec,
+		Padding:   padding,
+		Threshold: conf.CompressionThreshold,
+		MaxSize:   conf.MaxDecompressedSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: init envelope: %s", err, op)
+	}
+
+	trustedProxies, err := realip.ParsePrefixes(conf.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse trusted proxies: %s", err, op)
+	}
+
+	blobs, err := newBlobStore(ctx, conf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: init blob store: %s", err, op)
+	}
+
+	notifier := webhook.NewNotifier(10*time.Second, conf.NotifyWebhooks...)
+
+	sender, err := newMailer(conf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: init mailer: %s", err, op)
+	}
+
+	var (
+		locator geoip.Locator
+		watcher *maxmind.Locator
+	)
+	if conf.GeoIPDatabase != "" {
+		watcher, err = maxmind.NewLocator(logger, conf.GeoIPDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("%w: init geoip: %s", err, op)
+		}
 
-	encoder := base64.NewEncoder(true)
-	paddinger := pkcs7.NewPaddinger()
-	encryptor := aes.NewEncryptor(base64.NewEncoder(false), paddinger)
+		locator = watcher
+	}
 
 	router := mux.NewRouter()
 	server := &http.Server{
@@ -80,11 +234,22 @@ func New(conf config.Config) (*Server, error) {
 		logger:    logger.With("module", "server"),
 		store:     store,
 		hasher:    hasher,
+		mode:      mode,
 		encoder:   encoder,
 		encryptor: encryptor,
+		streamer:  streamer,
+		sealer:    sealer,
+		kems:      kems,
+		blobs:     blobs,
+		notifier:  notifier,
+		mailer:    sender,
+		locator:   locator,
+		watcher:   watcher,
 		router:    router,
 		server:    server,
 		closer:    closer.New(),
+
+		trustedProxies: trustedProxies,
 	}, nil
 }
 
@@ -93,11 +258,41 @@ func (s *Server) Run() error {
 	var err error
 	ctx := context.Background()
 
-	s.registerOnShutdown()
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	var background sync.WaitGroup
+
+	s.registerOnShutdown(func(ctx context.Context) error {
+		stopScheduler()
+
+		stopped := make(chan struct{})
+		go func() {
+			background.Wait()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("stop scheduler: %w", ctx.Err())
+		}
+	})
 	s.setupRoutes()
 
 	errs := make(chan error)
 
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		s.startScheduler(schedulerCtx)
+	}()
+	if s.watcher != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			s.watcher.Watch(schedulerCtx, s.conf.GeoIPReloadInterval)
+		}()
+	}
 	go s.startServer(ctx, errs)
 	go s.gracefulShutdown(ctx, errs)
 
@@ -112,9 +307,13 @@ func (s *Server) Run() error {
 	return nil
 }
 
-func (s *Server) registerOnShutdown() {
+func (s *Server) registerOnShutdown(stopScheduler closer.Func) {
 	s.closer.Add(s.server.Shutdown)
+	s.closer.Add(stopScheduler)
 	s.closer.Add(s.store.Close)
+	if s.watcher != nil {
+		s.closer.Add(s.watcher.Close)
+	}
 	s.closer.Add(s.logger.Sync)
 }
 
@@ -125,9 +324,52 @@ func (s *Server) setupRoutes() {
 
 	s.router.Handle("/health", s.handleHealthCheck()).Methods(http.MethodGet)
 
-	s.router.Handle("/api/secrets/{key}", s.handleGetSecret()).Methods(http.MethodPost)
 	s.router.Handle("/api/secrets", s.handleCreateSecret()).Methods(http.MethodPost)
 
+	secrets := s.router.PathPrefix("/api/secrets/{key}").Subrouter()
+	secrets.Use(s.normalizeTiming())
+	secrets.Handle("", s.handleGetSecret()).Methods(http.MethodPost)
+	if s.mode != cryptor.ModeFIPS {
+		secrets.Handle("/pake", s.handleGetPakeParams()).Methods(http.MethodGet)
+		secrets.Handle("/pake/begin", s.handleBeginPake()).Methods(http.MethodPost)
+		secrets.Handle("/pake/finish", s.handleFinishPake()).Methods(http.MethodPost)
+	} else {
+		secrets.Handle("/pake", s.handlePakeUnavailable()).Methods(http.MethodGet)
+		secrets.Handle("/pake/begin", s.handlePakeUnavailable()).Methods(http.MethodPost)
+		secrets.Handle("/pake/finish", s.handlePakeUnavailable()).Methods(http.MethodPost)
+	}
+	secrets.Handle("/webauthn/begin", s.handleBeginWebAuthn()).Methods(http.MethodPost)
+	secrets.Handle("/webauthn/finish", s.handleFinishWebAuthn()).Methods(http.MethodPost)
+	secrets.Handle("/email-code", s.handleSendEmailCode()).Methods(http.MethodPost)
+	secrets.Handle("/email-code/verify", s.handleVerifyEmailCode()).Methods(http.MethodPost)
+
+	s.router.Handle("/api/attachments/{key}", s.normalizeTiming()(s.handleDownloadAttachment())).Methods(http.MethodGet)
+
+	s.router.Handle("/api/shares/{key}", s.normalizeTiming()(s.handleSubmitSecretShare())).Methods(http.MethodPost)
+
+	s.router.Handle("/api/e2e/secrets/{key}", s.normalizeTiming()(s.handleGetE2ESecret())).Methods(http.MethodPost)
+	s.router.Handle("/api/e2e/secrets", s.handleCreateE2ESecret()).Methods(http.MethodPost)
+
+	s.router.Handle("/api/requests/{key}", s.handleGetSecretRequest()).Methods(http.MethodGet)
+	s.router.Handle("/api/requests/{key}", s.handleFulfilSecretRequest()).Methods(http.MethodPost)
+	s.router.Handle("/api/requests", s.handleCreateSecretRequest()).Methods(http.MethodPost)
+
+	s.router.Handle("/api/statuses/{key}", s.handleGetSecretStatus()).Methods(http.MethodGet)
+
+	s.router.Handle("/api/check-ins/{token}", s.handleCheckInSecret()).Methods(http.MethodPost)
+
+	s.router.Handle("/api/signers/{fingerprint}", s.handleGetSigner()).Methods(http.MethodGet)
+	s.router.Handle("/api/signers/{fingerprint}/nonces", s.handleIssueSignatureNonce()).Methods(http.MethodPost)
+	s.router.Handle("/api/signers", s.handleRegisterSigner()).Methods(http.MethodPost)
+
+	uploads := s.router.PathPrefix("/api/uploads").Subrouter()
+	uploads.Use(s.tusResumable())
+	uploads.Handle("", s.handleUploadOptions()).Methods(http.MethodOptions)
+	uploads.Handle("", s.handleCreateUpload()).Methods(http.MethodPost)
+	uploads.Handle("/{key}", s.handleGetUpload()).Methods(http.MethodHead)
+	uploads.Handle("/{key}", s.handleWriteUpload()).Methods(http.MethodPatch)
+	uploads.Handle("/{key}", s.handleDeleteUpload()).Methods(http.MethodDelete)
+
 	s.server.Handler = s.CORS()(s.router)
 }
 
@@ -155,8 +397,61 @@ func (s *Server) gracefulShutdown(ctx context.Context, errs chan error) {
 	errs <- nil
 }
 
+func newBlobStore(ctx context.Context, conf config.Config) (blobstore.Store, error) {
+	switch conf.BlobStore {
+	case "fs":
+		return fs.NewStore(conf.BlobDir)
+	case "s3":
+		return s3.NewStore(ctx, s3.Options{
+			Endpoint:  conf.S3Endpoint,
+			Region:    conf.S3Region,
+			Bucket:    conf.S3Bucket,
+			AccessKey: conf.S3AccessKey,
+			SecretKey: conf.S3SecretKey,
+			UseSSL:    conf.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", conf.BlobStore)
+	}
+}
+
+func newMailer(conf config.Config) (mailer.Mailer, error) {
+	if conf.SMTPAddr == "" {
+		return nil, nil
+	}
+
+	tlsMode, err := smtp.ParseTLSMode(conf.SMTPTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return smtp.NewMailer(smtp.Options{
+		Addr:     conf.SMTPAddr,
+		Username: conf.SMTPUsername,
+		Password: conf.SMTPPassword,
+		From:     conf.SMTPFrom,
+		TLS:      tlsMode,
+		Timeout:  10 * time.Second,
+	})
+}
+
 func wait() <-chan os.Signal {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
 	return ch
 }
+
+func selfTest(mode cryptor.Mode, registries ...cryptor.SelfTester) error {
+	for _, registry := range registries {
+		err := registry.SelfTest()
+		if err != nil {
+			return err
+		}
+	}
+
+	if mode != cryptor.ModeFIPS {
+		return hybrid.SelfTest()
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/usecase"
+)
+
+type sharesRequest struct {
+	Total     int   `json:"total"`
+	Threshold int   `json:"threshold"`
+	Window    int64 `json:"window"` // in minutes
+}
+
+func (req *sharesRequest) dto(defaultWindow time.Duration) *usecase.SharesDTO {
+	if req == nil {
+		return nil
+	}
+
+	window := defaultWindow
+	if req.Window != 0 {
+		window = time.Duration(req.Window) * time.Minute
+	}
+
+	return &usecase.SharesDTO{
+		Total:     req.Total,
+		Threshold: req.Threshold,
+		Window:    window,
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import "github.com/protomem/secrets-keeper/internal/usecase"
+
+type signatureRequest struct {
+	Fingerprint string `json:"fingerprint"`
+	Nonce       string `json:"nonce"`
+	Value       string `json:"value"`
+}
+
+func (req *signatureRequest) dto() *usecase.SignatureDTO {
+	if req == nil {
+		return nil
+	}
+
+	return &usecase.SignatureDTO{
+		Fingerprint: req.Fingerprint,
+		Nonce:       req.Nonce,
+		Value:       req.Value,
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,expiration,termination"
+	tusContentType = "application/offset+octet-stream"
+
+	headerTusResumable  = "Tus-Resumable"
+	headerTusVersion    = "Tus-Version"
+	headerTusExtension  = "Tus-Extension"
+	headerTusMaxSize    = "Tus-Max-Size"
+	headerUploadLength  = "Upload-Length"
+	headerUploadOffset  = "Upload-Offset"
+	headerUploadExpires = "Upload-Expires"
+	headerUploadMeta    = "Upload-Metadata"
+)
+
+var tusExposedHeaders = []string{
+	"Location",
+	headerTusResumable,
+	headerTusVersion,
+	headerTusExtension,
+	headerTusMaxSize,
+	headerUploadLength,
+	headerUploadOffset,
+	headerUploadExpires,
+}
+
+func (s *Server) tusResumable() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(headerTusResumable, tusVersion)
+
+			if r.Method != http.MethodOptions && r.Header.Get(headerTusResumable) != tusVersion {
+				w.Header().Set(headerTusVersion, tusVersion)
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setUploadHeaders(w http.ResponseWriter, upload model.Upload) {
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set(headerUploadLength, strconv.FormatInt(upload.Length, 10))
+	w.Header().Set(headerUploadExpires, uploadExpires(upload.ExpiredAt))
+}
+
+func parseUploadInt(r *http.Request, header string) (int64, error) {
+	val, err := strconv.ParseInt(r.Header.Get(header), 10, 64)
+	if err != nil || val < 0 {
+		return 0, fmt.Errorf("invalid %s header", header)
+	}
+
+	return val, nil
+}
+
+func parseUploadMetadata(header string) (map[string]string, error) {
+	meta := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return meta, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, fmt.Errorf("invalid %s header", headerUploadMeta)
+		}
+
+		val, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value for %q", headerUploadMeta, key)
+		}
+
+		meta[key] = string(val)
+	}
+
+	return meta, nil
+}
+
+func uploadExpires(expiredAt time.Time) string {
+	return expiredAt.UTC().Format(http.TimeFormat)
+}
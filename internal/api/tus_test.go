@@ -0,0 +1,494 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/config"
+)
+
+type tusTestServer struct {
+	server  *Server
+	handler http.Handler
+	blobDir string
+}
+
+func newTusTestServer(t *testing.T, configure func(conf *config.Config)) *tusTestServer {
+	t.Helper()
+
+	conf, err := config.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	conf.LogLevel = "error"
+	conf.Database = filepath.Join(dir, "test.db")
+	conf.BlobStore = "fs"
+	conf.BlobDir = filepath.Join(dir, "blobs")
+	conf.ProcessHardening = false
+	conf.ResponseTimeQuantum = 0
+	conf.MaxResumableUploadSize = 1 << 20
+	if configure != nil {
+		configure(&conf)
+	}
+
+	s, err := New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = s.store.Close(context.Background())
+	})
+
+	s.setupRoutes()
+
+	return &tusTestServer{
+		server:  s,
+		handler: s.server.Handler,
+		blobDir: conf.BlobDir,
+	}
+}
+
+func (ts *tusTestServer) do(t *testing.T, method, target string, body io.Reader, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(method, target, body)
+	r.Header.Set(headerTusResumable, tusVersion)
+	for key, val := range headers {
+		r.Header.Set(key, val)
+	}
+
+	w := httptest.NewRecorder()
+	ts.handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func (ts *tusTestServer) create(t *testing.T, length int, name string) string {
+	t.Helper()
+
+	w := ts.do(t, http.MethodPost, "/api/uploads", nil, map[string]string{
+		headerUploadLength: strconv.Itoa(length),
+		headerUploadMeta: "filename " + base64.StdEncoding.EncodeToString([]byte(name)) +
+			",filetype " + base64.StdEncoding.EncodeToString([]byte("text/plain")),
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", w.Code, w.Body)
+	}
+
+	location := w.Header().Get("Location")
+	if !strings.HasPrefix(location, "/api/uploads/") {
+		t.Fatalf("Location = %q", location)
+	}
+
+	return location
+}
+
+func (ts *tusTestServer) patch(t *testing.T, location string, offset int, body io.Reader) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return ts.do(t, http.MethodPatch, location, body, map[string]string{
+		"Content-Type":     tusContentType,
+		headerUploadOffset: strconv.Itoa(offset),
+	})
+}
+
+func (ts *tusTestServer) offset(t *testing.T, location string) int {
+	t.Helper()
+
+	w := ts.do(t, http.MethodHead, location, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("HEAD status = %d", w.Code)
+	}
+
+	offset, err := strconv.Atoi(w.Header().Get(headerUploadOffset))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return offset
+}
+
+func (ts *tusTestServer) blobCount(t *testing.T) int {
+	t.Helper()
+
+	count := 0
+	err := filepath.WalkDir(ts.blobDir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			count++
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+type brokenBody struct {
+	data []byte
+	sent bool
+}
+
+func (b *brokenBody) Read(p []byte) (int, error) {
+	if b.sent {
+		return 0, errors.New("connection reset")
+	}
+	b.sent = true
+
+	return copy(p, b.data), nil
+}
+
+func TestTusOptions(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+
+	r := httptest.NewRequest(http.MethodOptions, "/api/uploads", nil)
+	w := httptest.NewRecorder()
+	ts.handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if got := w.Header().Get(headerTusVersion); got != tusVersion {
+		t.Fatalf("%s = %q", headerTusVersion, got)
+	}
+	if got := w.Header().Get(headerTusExtension); got != tusExtensions {
+		t.Fatalf("%s = %q", headerTusExtension, got)
+	}
+	if got := w.Header().Get(headerTusMaxSize); got != strconv.Itoa(1<<20) {
+		t.Fatalf("%s = %q", headerTusMaxSize, got)
+	}
+}
+
+func TestTusRequiresResumableHeader(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/uploads", nil)
+	r.Header.Set(headerUploadLength, "10")
+	w := httptest.NewRecorder()
+	ts.handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+	if got := w.Header().Get(headerTusVersion); got != tusVersion {
+		t.Fatalf("%s = %q", headerTusVersion, got)
+	}
+}
+
+func TestTusCreateUpload(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+
+	location := ts.create(t, 11, "notes.txt")
+
+	w := ts.do(t, http.MethodHead, location, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("HEAD status = %d", w.Code)
+	}
+	if got := w.Header().Get(headerUploadOffset); got != "0" {
+		t.Fatalf("%s = %q, want 0", headerUploadOffset, got)
+	}
+	if got := w.Header().Get(headerUploadLength); got != "11" {
+		t.Fatalf("%s = %q, want 11", headerUploadLength, got)
+	}
+	if w.Header().Get(headerUploadExpires) == "" {
+		t.Fatalf("%s is missing", headerUploadExpires)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Fatalf("Cache-Control = %q", got)
+	}
+}
+
+func TestTusCreateUploadRejectsInvalidRequests(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "missing length", headers: map[string]string{}, want: http.StatusBadRequest},
+		{name: "negative length", headers: map[string]string{headerUploadLength: "-1"}, want: http.StatusBadRequest},
+		{name: "too large", headers: map[string]string{headerUploadLength: strconv.Itoa(1<<20 + 1)}, want: http.StatusRequestEntityTooLarge},
+		{
+			name:    "bad metadata",
+			headers: map[string]string{headerUploadLength: "1", headerUploadMeta: "filename ***"},
+			want:    http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := ts.do(t, http.MethodPost, "/api/uploads", nil, tt.headers)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestTusPatchChecksOffset(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+	location := ts.create(t, 11, "notes.txt")
+
+	w := ts.patch(t, location, 3, strings.NewReader("hello"))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("PATCH at a wrong offset status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	w = ts.do(t, http.MethodPatch, location, strings.NewReader("hello"), map[string]string{
+		"Content-Type":     "text/plain",
+		headerUploadOffset: "0",
+	})
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("PATCH with a wrong content type status = %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+
+	w = ts.patch(t, location, 0, strings.NewReader("hello"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("PATCH status = %d, body = %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get(headerUploadOffset); got != "5" {
+		t.Fatalf("%s = %q, want 5", headerUploadOffset, got)
+	}
+
+	w = ts.patch(t, location, 0, strings.NewReader(" world"))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("PATCH at a stale offset status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	w = ts.patch(t, location, 5, strings.NewReader(" world and more"))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("PATCH past the length status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	if got := ts.offset(t, location); got != 5 {
+		t.Fatalf("offset = %d, want 5", got)
+	}
+
+	w = ts.patch(t, "/api/uploads/unknown", 0, strings.NewReader("hello"))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("PATCH of an unknown upload status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestTusResumeAfterPartialBody(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+	location := ts.create(t, 11, "notes.txt")
+
+	w := ts.patch(t, location, 0, &brokenBody{data: []byte("hello")})
+	if w.Code == http.StatusNoContent {
+		t.Fatal("PATCH with a broken body reported success")
+	}
+
+	offset := ts.offset(t, location)
+	if offset != 5 {
+		t.Fatalf("offset after a broken body = %d, want 5", offset)
+	}
+
+	w = ts.patch(t, location, offset, strings.NewReader(" world"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("resumed PATCH status = %d, body = %s", w.Code, w.Body)
+	}
+	if got := ts.offset(t, location); got != 11 {
+		t.Fatalf("offset = %d, want 11", got)
+	}
+
+	if got := finalizeUpload(t, ts, location); got != "hello world" {
+		t.Fatalf("attachment = %q, want %q", got, "hello world")
+	}
+}
+
+func TestTusTermination(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+	location := ts.create(t, 11, "notes.txt")
+
+	w := ts.patch(t, location, 0, strings.NewReader("hello"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("PATCH status = %d", w.Code)
+	}
+	if ts.blobCount(t) == 0 {
+		t.Fatal("PATCH stored no chunk")
+	}
+
+	w = ts.do(t, http.MethodDelete, location, nil, nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d", w.Code)
+	}
+
+	if w = ts.do(t, http.MethodHead, location, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("HEAD after DELETE status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w = ts.do(t, http.MethodDelete, location, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("second DELETE status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if got := ts.blobCount(t); got != 0 {
+		t.Fatalf("%d chunks left after DELETE", got)
+	}
+}
+
+func TestTusExpiredUploadsAreCollected(t *testing.T) {
+	ts := newTusTestServer(t, func(conf *config.Config) {
+		conf.UploadTTL = time.Second
+		conf.SchedulerInterval = 50 * time.Millisecond
+	})
+	location := ts.create(t, 11, "notes.txt")
+
+	w := ts.patch(t, location, 0, strings.NewReader("hello"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("PATCH status = %d", w.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ts.server.startScheduler(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		expired, err := ts.server.store.UploadRepo().FindExpiredUploads(context.Background(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expired) == 0 && ts.blobCount(t) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expired upload was not collected: %d rows, %d chunks", len(expired), ts.blobCount(t))
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if w = ts.do(t, http.MethodHead, location, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("HEAD after expiry status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestTusFinalizeIntoAttachment(t *testing.T) {
+	ts := newTusTestServer(t, nil)
+	location := ts.create(t, 11, "notes.txt")
+
+	w := ts.patch(t, location, 0, strings.NewReader("hello"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("PATCH status = %d", w.Code)
+	}
+
+	w = ts.do(t, http.MethodPost, "/api/secrets", createSecretWithUploads(t, location), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("create with an incomplete upload status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = ts.patch(t, location, 5, strings.NewReader(" world"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("PATCH status = %d", w.Code)
+	}
+
+	if got := finalizeUpload(t, ts, location); got != "hello world" {
+		t.Fatalf("attachment = %q, want %q", got, "hello world")
+	}
+
+	if w = ts.do(t, http.MethodHead, location, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("HEAD after finalizing status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func createSecretWithUploads(t *testing.T, locations ...string) io.Reader {
+	t.Helper()
+
+	uploadKeys := make([]string, 0, len(locations))
+	for _, location := range locations {
+		uploadKeys = append(uploadKeys, strings.TrimPrefix(location, "/api/uploads/"))
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"message": "see attachment",
+		"ttl":     1,
+		"uploads": uploadKeys,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.NewReader(body)
+}
+
+func finalizeUpload(t *testing.T, ts *tusTestServer, location string) string {
+	t.Helper()
+
+	w := ts.do(t, http.MethodPost, "/api/secrets", createSecretWithUploads(t, location), nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", w.Code, w.Body)
+	}
+
+	var created struct {
+		SecretKey string `json:"secretKey"`
+	}
+	err := json.NewDecoder(w.Body).Decode(&created)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w = ts.do(t, http.MethodPost, "/api/secrets/"+created.SecretKey, strings.NewReader("{}"), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get status = %d, body = %s", w.Code, w.Body)
+	}
+
+	var got struct {
+		Secret struct {
+			Attachments []struct {
+				Name        string `json:"name"`
+				ContentType string `json:"contentType"`
+				Size        int64  `json:"size"`
+				DownloadKey string `json:"downloadKey"`
+			} `json:"attachments"`
+		} `json:"secret"`
+	}
+	err = json.NewDecoder(w.Body).Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Secret.Attachments) != 1 {
+		t.Fatalf("attachments = %d, want 1", len(got.Secret.Attachments))
+	}
+
+	attachment := got.Secret.Attachments[0]
+	if attachment.Name != "notes.txt" || attachment.ContentType != "text/plain" {
+		t.Fatalf("attachment = %q (%q), want notes.txt (text/plain)", attachment.Name, attachment.ContentType)
+	}
+
+	w = ts.do(t, http.MethodGet, "/api/attachments/"+attachment.DownloadKey, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("download status = %d, body = %s", w.Code, w.Body)
+	}
+	if w.Body.Len() != int(attachment.Size) {
+		t.Fatalf("downloaded %d bytes, want %d", w.Body.Len(), attachment.Size)
+	}
+
+	return w.Body.String()
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/protomem/secrets-keeper/internal/usecase"
+	"github.com/protomem/secrets-keeper/pkg/webauthn"
+)
+
+type passkeyRequest struct {
+	ID        []byte `json:"id"`
+	PublicKey []byte `json:"publicKey"`
+}
+
+func passkeysDTO(reqs []passkeyRequest) []usecase.PasskeyDTO {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	dtos := make([]usecase.PasskeyDTO, 0, len(reqs))
+	for _, req := range reqs {
+		dtos = append(dtos, usecase.PasskeyDTO{
+			ID:        req.ID,
+			PublicKey: req.PublicKey,
+		})
+	}
+
+	return dtos
+}
+
+func (s *Server) webauthnOptions() usecase.WebAuthnOptions {
+	return usecase.WebAuthnOptions{
+		Config: webauthn.Config{
+			RPID:             s.conf.WebAuthnRPID,
+			Origins:          s.conf.WebAuthnOrigins,
+			UserVerification: s.conf.WebAuthnUserVerification,
+		},
+		SessionTTL: s.conf.WebAuthnSessionTTL,
+		Lockout: usecase.LockoutOptions{
+			MaxAttempts: s.conf.MaxAttempts,
+			BaseDelay:   s.conf.AttemptDelay,
+			MaxDelay:    s.conf.MaxAttemptDelay,
+		},
+	}
+}
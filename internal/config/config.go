@@ -1,7 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -9,11 +14,75 @@ type Config struct {
 	LogLevel  string
 	Database  string
 	CertsName string
+
+	MaxAttempts     int
+	AttemptDelay    time.Duration
+	MaxAttemptDelay time.Duration
+
+	SchedulerInterval time.Duration
+	NotifyWebhooks    []string
+
+	TrustedProxies []string
+
+	GeoIPDatabase       string
+	GeoIPReloadInterval time.Duration
+
+	BlobStore     string
+	BlobDir       string
+	MaxUploadSize int64
+
+	StreamChunkSize int
+
+	CompressionCodec     string
+	CompressionThreshold int
+	MaxDecompressedSize  int64
+	Padding              string
+
+	MaxResumableUploadSize int64
+	UploadTTL              time.Duration
+
+	AttachmentDownloadTTL time.Duration
+
+	ShareWindow time.Duration
+
+	SignatureNonceTTL time.Duration
+
+	PakeSessionTTL time.Duration
+
+	WebAuthnRPID             string
+	WebAuthnOrigins          []string
+	WebAuthnUserVerification bool
+	WebAuthnSessionTTL       time.Duration
+
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	SMTPTLS      string
+
+	EmailCodeTTL            time.Duration
+	EmailCodeMaxAttempts    int
+	EmailCodeResendInterval time.Duration
+
+	ResponseTimeQuantum time.Duration
+
+	ProcessHardening bool
+
+	CryptoMode     string
+	PasswordHasher string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
 }
 
 func New() (Config, error) {
 	const op = "config.New"
 	var (
+		err   error
 		exist bool
 		conf  Config
 	)
@@ -38,5 +107,270 @@ func New() (Config, error) {
 		conf.CertsName = "localhost"
 	}
 
+	conf.MaxAttempts, err = lookupInt("MAX_ATTEMPTS", 5)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.AttemptDelay, err = lookupDuration("ATTEMPT_DELAY", 500*time.Millisecond)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.MaxAttemptDelay, err = lookupDuration("MAX_ATTEMPT_DELAY", 30*time.Second)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.SchedulerInterval, err = lookupDuration("SCHEDULER_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.NotifyWebhooks = lookupList("NOTIFY_WEBHOOKS")
+
+	conf.TrustedProxies = lookupList("TRUSTED_PROXIES")
+
+	conf.GeoIPDatabase = os.Getenv("GEOIP_DATABASE")
+
+	conf.GeoIPReloadInterval, err = lookupDuration("GEOIP_RELOAD_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.BlobStore, exist = os.LookupEnv("BLOB_STORE")
+	if !exist {
+		conf.BlobStore = "fs"
+	}
+
+	conf.BlobDir, exist = os.LookupEnv("BLOB_DIR")
+	if !exist {
+		conf.BlobDir = "./data/blobs"
+	}
+
+	maxUploadSize, err := lookupInt("MAX_UPLOAD_SIZE", 10<<20)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+	conf.MaxUploadSize = int64(maxUploadSize)
+
+	conf.StreamChunkSize, err = lookupInt("STREAM_CHUNK_SIZE", 64<<10)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.CompressionCodec, exist = os.LookupEnv("COMPRESSION_CODEC")
+	if !exist {
+		conf.CompressionCodec = "zstd"
+	}
+
+	conf.CompressionThreshold, err = lookupInt("COMPRESSION_THRESHOLD", 1024)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	maxDecompressedSize, err := lookupInt("MAX_DECOMPRESSED_SIZE", 64<<20)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+	conf.MaxDecompressedSize = int64(maxDecompressedSize)
+
+	conf.Padding, exist = os.LookupEnv("PADDING")
+	if !exist {
+		conf.Padding = "pow2"
+	}
+
+	maxResumableUploadSize, err := lookupInt("MAX_RESUMABLE_UPLOAD_SIZE", 1<<30)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+	conf.MaxResumableUploadSize = int64(maxResumableUploadSize)
+
+	conf.UploadTTL, err = lookupDuration("UPLOAD_TTL", 24*time.Hour)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.AttachmentDownloadTTL, err = lookupDuration("ATTACHMENT_DOWNLOAD_TTL", 15*time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.ShareWindow, err = lookupDuration("SHARE_WINDOW", time.Hour)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.SignatureNonceTTL, err = lookupDuration("SIGNATURE_NONCE_TTL", 5*time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.PakeSessionTTL, err = lookupDuration("PAKE_SESSION_TTL", time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.WebAuthnRPID, exist = os.LookupEnv("WEBAUTHN_RP_ID")
+	if !exist {
+		conf.WebAuthnRPID = conf.CertsName
+	}
+
+	conf.WebAuthnOrigins = lookupList("WEBAUTHN_ORIGINS")
+
+	conf.WebAuthnUserVerification, err = lookupBool("WEBAUTHN_USER_VERIFICATION", true)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.WebAuthnSessionTTL, err = lookupDuration("WEBAUTHN_SESSION_TTL", 2*time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.SMTPAddr = os.Getenv("SMTP_ADDR")
+	conf.SMTPUsername = os.Getenv("SMTP_USERNAME")
+	conf.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+
+	conf.SMTPFrom, exist = os.LookupEnv("SMTP_FROM")
+	if !exist {
+		conf.SMTPFrom = "secrets-keeper@" + conf.CertsName
+	}
+
+	conf.SMTPTLS, exist = os.LookupEnv("SMTP_TLS")
+	if !exist {
+		conf.SMTPTLS = "starttls"
+	}
+
+	conf.EmailCodeTTL, err = lookupDuration("EMAIL_CODE_TTL", 10*time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.EmailCodeMaxAttempts, err = lookupInt("EMAIL_CODE_MAX_ATTEMPTS", 3)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.EmailCodeResendInterval, err = lookupDuration("EMAIL_CODE_RESEND_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.ResponseTimeQuantum, err = lookupDuration("RESPONSE_TIME_QUANTUM", 500*time.Millisecond)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.ProcessHardening, err = lookupBool("PROCESS_HARDENING", true)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	conf.CryptoMode, exist = os.LookupEnv("CRYPTO_MODE")
+	if !exist {
+		conf.CryptoMode = "standard"
+	}
+
+	conf.PasswordHasher, exist = os.LookupEnv("PASSWORD_HASHER")
+	if !exist {
+		conf.PasswordHasher = "argon2id"
+		if strings.EqualFold(conf.CryptoMode, "fips") {
+			conf.PasswordHasher = "pbkdf2-sha256"
+		}
+	}
+
+	conf.S3Endpoint = os.Getenv("S3_ENDPOINT")
+	conf.S3Region = os.Getenv("S3_REGION")
+	conf.S3AccessKey = os.Getenv("S3_ACCESS_KEY")
+	conf.S3SecretKey = os.Getenv("S3_SECRET_KEY")
+
+	conf.S3Bucket, exist = os.LookupEnv("S3_BUCKET")
+	if !exist {
+		conf.S3Bucket = "secrets-keeper"
+	}
+
+	conf.S3UseSSL, err = lookupBool("S3_USE_SSL", true)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return conf, nil
 }
+
+type loggedConfig Config
+
+func (c Config) LogValue() slog.Value {
+	logged := loggedConfig(c)
+	logged.SMTPPassword = redact(c.SMTPPassword)
+	logged.S3SecretKey = redact(c.S3SecretKey)
+
+	return slog.AnyValue(logged)
+}
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return "[REDACTED]"
+}
+
+func lookupList(key string) []string {
+	val, exist := os.LookupEnv(key)
+	if !exist {
+		return nil
+	}
+
+	list := make([]string, 0)
+	for _, item := range strings.Split(val, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func lookupInt(key string, def int) (int, error) {
+	val, exist := os.LookupEnv(key)
+	if !exist {
+		return def, nil
+	}
+
+	num, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+
+	return num, nil
+}
+
+func lookupBool(key string, def bool) (bool, error) {
+	val, exist := os.LookupEnv(key)
+	if !exist {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", key, err)
+	}
+
+	return b, nil
+}
+
+func lookupDuration(key string, def time.Duration) (time.Duration, error) {
+	val, exist := os.LookupEnv(key)
+	if !exist {
+		return def, nil
+	}
+
+	dur, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+
+	return dur, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestConfigLogValueRedactsSecrets(t *testing.T) {
+	conf := Config{
+		BindAddr:     "localhost:8443",
+		SMTPPassword: "smtp-secret-value",
+		S3AccessKey:  "access-key-id",
+		S3SecretKey:  "s3-secret-value",
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("configured", "config", conf)
+
+	out := buf.String()
+	for _, secret := range []string{"smtp-secret-value", "s3-secret-value"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("log output leaks %q: %s", secret, out)
+		}
+	}
+	for _, visible := range []string{"localhost:8443", "access-key-id", "[REDACTED]"} {
+		if !strings.Contains(out, visible) {
+			t.Fatalf("log output is missing %q: %s", visible, out)
+		}
+	}
+}
+
+func TestConfigLogValueKeepsEmptySecretsEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("configured", "config", Config{})
+
+	if strings.Contains(buf.String(), "[REDACTED]") {
+		t.Fatalf("empty secret was reported as set: %s", buf.String())
+	}
+}
@@ -3,12 +3,15 @@ package aes
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"errors"
 	"fmt"
 
 	"github.com/protomem/secrets-keeper/internal/cryptor"
 )
 
+const formatRandomIV byte = 1
+
 var _ cryptor.Encryptor = (*Encryptor)(nil)
 
 type Encryptor struct {
@@ -25,33 +28,41 @@ func NewEncryptor(encoder cryptor.Encoder, paddinger cryptor.Paddinger) *Encrypt
 
 func (e *Encryptor) Encrypt(data []byte, key []byte) ([]byte, error) {
 	const op = "aes.Encrypt"
-	var err error
 
-	block, err := aes.NewCipher(key)
+	iv := make([]byte, aes.BlockSize)
+	_, err := rand.Read(iv)
 	if err != nil {
-		var aesKeySizeError aes.KeySizeError
-		if errors.As(err, &aesKeySizeError) {
-			return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidKeySize)
-		}
-
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	alignedData, err := e.paddinger.Padding(data, block.BlockSize())
+	encodedData, err := e.encrypt(data, key, iv)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	ecryptedData := make([]byte, len(alignedData))
-	blockMode := cipher.NewCBCEncrypter(block, key)
-	blockMode.CryptBlocks(ecryptedData, alignedData)
+	return encodedData, nil
+}
 
-	encodedData, err := e.encoder.Encode(ecryptedData)
+func (e *Encryptor) encrypt(data []byte, key []byte, iv []byte) ([]byte, error) {
+	block, err := newCipher(key)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, err
 	}
 
-	return encodedData, nil
+	alignedData, err := e.paddinger.Padding(data, block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedData := make([]byte, 1+len(iv)+len(alignedData))
+	encryptedData[0] = formatRandomIV
+	copy(encryptedData[1:], iv)
+
+	blockMode := cipher.NewCBCEncrypter(block, iv)
+	blockMode.CryptBlocks(encryptedData[1+len(iv):], alignedData)
+	cryptor.Wipe(alignedData)
+
+	return e.encoder.Encode(encryptedData)
 }
 
 func (e *Encryptor) Decrypt(data []byte, key []byte) ([]byte, error) {
@@ -63,24 +74,43 @@ func (e *Encryptor) Decrypt(data []byte, key []byte) ([]byte, error) {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	block, err := aes.NewCipher(key)
+	block, err := newCipher(key)
 	if err != nil {
-		var aesKeySizeError aes.KeySizeError
-		if errors.As(err, &aesKeySizeError) {
-			return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidKeySize)
-		}
-
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	iv := key
+	if len(decodedData) > block.BlockSize() && len(decodedData)%block.BlockSize() == 1 && decodedData[0] == formatRandomIV {
+		iv, decodedData = decodedData[1:1+block.BlockSize()], decodedData[1+block.BlockSize():]
+	}
+
+	if len(decodedData) == 0 || len(decodedData)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidDataSize)
+	}
+
 	decryptedData := make([]byte, len(decodedData))
-	blockMode := cipher.NewCBCDecrypter(block, key)
+	blockMode := cipher.NewCBCDecrypter(block, iv)
 	blockMode.CryptBlocks(decryptedData, decodedData)
 
 	originData, err := e.paddinger.Unpadding(decryptedData, block.BlockSize())
 	if err != nil {
+		cryptor.Wipe(decryptedData)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return originData, nil
 }
+
+func newCipher(key []byte) (cipher.Block, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		var aesKeySizeError aes.KeySizeError
+		if errors.As(err, &aesKeySizeError) {
+			return nil, cryptor.ErrInvalidKeySize
+		}
+
+		return nil, err
+	}
+
+	return block, nil
+}
@@ -0,0 +1,112 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/cryptor/base64"
+	"github.com/protomem/secrets-keeper/internal/cryptor/pkcs7"
+)
+
+func newTestEncryptor() *Encryptor {
+	return NewEncryptor(base64.NewEncoder(false), pkcs7.NewPaddinger())
+}
+
+func TestSelfTest(t *testing.T) {
+	err := newTestEncryptor().SelfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncryptUsesRandomIV(t *testing.T) {
+	e := newTestEncryptor()
+	key := []byte(legacyKATKey)
+
+	first, err := e.Encrypt([]byte("same plaintext"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := e.Encrypt([]byte("same plaintext"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(first, second) {
+		t.Fatal("two encryptions of the same plaintext are equal")
+	}
+
+	for _, encrypted := range [][]byte{first, second} {
+		decoded, err := e.encoder.Decode(encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Contains(decoded, key) {
+			t.Fatal("ciphertext carries the key as its IV")
+		}
+
+		decrypted, err := e.Decrypt(encrypted, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(decrypted) != "same plaintext" {
+			t.Fatalf("decrypted = %q", decrypted)
+		}
+	}
+}
+
+func TestDecryptLegacyCiphertext(t *testing.T) {
+	e := newTestEncryptor()
+
+	ciphertext, err := hex.DecodeString(legacyKATCiphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := e.encoder.Encode(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypted, err := e.Decrypt(encoded, []byte(legacyKATKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(decrypted) != legacyKATPlaintext {
+		t.Fatalf("decrypted = %q", decrypted)
+	}
+}
+
+func TestDecryptRejectsTruncatedData(t *testing.T) {
+	e := newTestEncryptor()
+	key := []byte(legacyKATKey)
+
+	encrypted, err := e.Encrypt([]byte("truncated"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := e.encoder.Decode(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, 5, 17, 18} {
+		truncated, err := e.encoder.Encode(decoded[:size])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = e.Decrypt(truncated, key)
+		if !errors.Is(err, cryptor.ErrInvalidDataSize) {
+			t.Fatalf("Decrypt(%d bytes) error = %v, want %v", size, err, cryptor.ErrInvalidDataSize)
+		}
+	}
+}
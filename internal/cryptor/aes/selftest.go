@@ -0,0 +1,71 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+const (
+	katKey        = "2b7e151628aed2a6abf7158809cf4f3c"
+	katIV         = "000102030405060708090a0b0c0d0e0f"
+	katPlaintext  = "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51" + "30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710"
+	katCiphertext = "7649abac8119b246cee98e9b12e9197d5086cb9b507219ee95db113a917678b2" + "73bed6b8e3c1743b7116e69e222295163ff1caa1681fac09120eca307586e1a7"
+
+	legacyKATKey        = "0123456789abcdef"
+	legacyKATPlaintext  = "secrets-keeper aes kat"
+	legacyKATCiphertext = "cb2031e728f0bb22abe908f6ffa1d77fa0a2b4fc1f6c9848b913b37e043a5c57"
+)
+
+func (e *Encryptor) SelfTest() error {
+	const op = "aes.SelfTest"
+
+	key, _ := hex.DecodeString(katKey)
+	iv, _ := hex.DecodeString(katIV)
+	plaintext, _ := hex.DecodeString(katPlaintext)
+	ciphertext, _ := hex.DecodeString(katCiphertext)
+
+	encrypted, err := e.encrypt(plaintext, key, iv)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	decoded, err := e.encoder.Decode(encrypted)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	want := append([]byte{formatRandomIV}, iv...)
+	want = append(want, ciphertext...)
+	if !bytes.HasPrefix(decoded, want) {
+		return fmt.Errorf("%s: %w: ciphertext mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	decrypted, err := e.Decrypt(encrypted, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if !bytes.Equal(decrypted, plaintext) {
+		return fmt.Errorf("%s: %w: plaintext mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	legacyCiphertext, _ := hex.DecodeString(legacyKATCiphertext)
+	legacyEncrypted, err := e.encoder.Encode(legacyCiphertext)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	decrypted, err = e.Decrypt(legacyEncrypted, []byte(legacyKATKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if !bytes.Equal(decrypted, []byte(legacyKATPlaintext)) {
+		return fmt.Errorf("%s: %w: legacy plaintext mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package base64
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+var katData = []byte{'f', 'o', 'o', 0xfb, 0xff}
+
+const (
+	katStd = "Zm9v+/8"
+	katURL = "Zm9v-_8"
+)
+
+func (e *Encoder) SelfTest() error {
+	const op = "base64.SelfTest"
+
+	expected := katStd
+	if e.isURL {
+		expected = katURL
+	}
+
+	encoded, err := e.Encode(katData)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if string(encoded) != expected {
+		return fmt.Errorf("%s: %w: encoding mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	decoded, err := e.Decode([]byte(expected))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if !bytes.Equal(decoded, katData) {
+		return fmt.Errorf("%s: %w: decoding mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package cryptor
+
+import (
+	"errors"
+	"io"
+)
+
+const minBufferGrowth = 512
+
+var ErrBufferTooLarge = errors.New("buffer too large")
+
+type Buffer struct {
+	data   []byte
+	size   int
+	locked bool
+}
+
+func NewBuffer(size int) (*Buffer, error) {
+	b := &Buffer{}
+
+	err := b.grow(size)
+	if err != nil {
+		return nil, err
+	}
+	b.size = size
+
+	return b, nil
+}
+
+func NewBufferFrom(parts ...[]byte) (*Buffer, error) {
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+
+	b, err := NewBuffer(size)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	for _, part := range parts {
+		offset += copy(b.data[offset:], part)
+	}
+
+	return b, nil
+}
+
+func ReadBuffer(r io.Reader, limit int64) (*Buffer, error) {
+	b := &Buffer{}
+
+	_, err := b.ReadFrom(io.LimitReader(r, limit+1))
+	if err != nil {
+		b.Destroy()
+		return nil, err
+	}
+
+	if int64(b.size) > limit {
+		b.Destroy()
+		return nil, ErrBufferTooLarge
+	}
+
+	return b, nil
+}
+
+func (b *Buffer) Bytes() []byte {
+	return b.data[:b.size:b.size]
+}
+
+func (b *Buffer) Len() int {
+	return b.size
+}
+
+func (b *Buffer) Locked() bool {
+	return b.locked
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	err := b.grow(b.size + len(p))
+	if err != nil {
+		return 0, err
+	}
+
+	n := copy(b.data[b.size:], p)
+	b.size += n
+
+	return n, nil
+}
+
+func (b *Buffer) WriteString(s string) (int, error) {
+	err := b.grow(b.size + len(s))
+	if err != nil {
+		return 0, err
+	}
+
+	n := copy(b.data[b.size:], s)
+	b.size += n
+
+	return n, nil
+}
+
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+
+	for {
+		if b.size == len(b.data) {
+			err := b.grow(b.size + 1)
+			if err != nil {
+				return total, err
+			}
+		}
+
+		n, err := r.Read(b.data[b.size:])
+		b.size += n
+		total += int64(n)
+
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+func (b *Buffer) Destroy() {
+	if b == nil || b.data == nil {
+		return
+	}
+
+	Wipe(b.data)
+	freeProtected(b.data, b.locked)
+
+	b.data, b.size, b.locked = nil, 0, false
+}
+
+func (b *Buffer) grow(size int) error {
+	if size <= len(b.data) {
+		return nil
+	}
+
+	capacity := max(size, 2*len(b.data), minBufferGrowth)
+
+	data, locked, err := allocProtected(capacity)
+	if err != nil {
+		return err
+	}
+
+	copy(data, b.data[:b.size])
+	if b.data != nil {
+		Wipe(b.data)
+		freeProtected(b.data, b.locked)
+	}
+
+	b.data, b.locked = data, locked
+
+	return nil
+}
+
+func Wipe(data []byte) {
+	clear(data)
+}
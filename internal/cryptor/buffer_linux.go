@@ -0,0 +1,25 @@
+//go:build linux
+
+package cryptor
+
+import "golang.org/x/sys/unix"
+
+func allocProtected(size int) ([]byte, bool, error) {
+	data, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_ = unix.Madvise(data, unix.MADV_DONTDUMP)
+	locked := unix.Mlock(data) == nil
+
+	return data, locked, nil
+}
+
+func freeProtected(data []byte, locked bool) {
+	if locked {
+		_ = unix.Munlock(data)
+	}
+
+	_ = unix.Munmap(data)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package cryptor
+
+func allocProtected(size int) ([]byte, bool, error) {
+	return make([]byte, size), false, nil
+}
+
+func freeProtected([]byte, bool) {}
@@ -0,0 +1,113 @@
+package cryptor
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewBufferFrom(t *testing.T) {
+	b, err := NewBufferFrom([]byte("access"), []byte("$"), []byte("signing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Destroy()
+
+	if got := string(b.Bytes()); got != "access$signing" {
+		t.Fatalf("Bytes() = %q", got)
+	}
+	if b.Len() != len("access$signing") {
+		t.Fatalf("Len() = %d", b.Len())
+	}
+}
+
+func TestBufferWriteGrows(t *testing.T) {
+	b, err := NewBuffer(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Destroy()
+
+	want := bytes.Repeat([]byte("0123456789"), 3*minBufferGrowth/10)
+	for i := 0; i < len(want); i += 7 {
+		chunk := want[i:min(i+7, len(want))]
+		if i%2 == 0 {
+			_, err = b.Write(chunk)
+		} else {
+			_, err = b.WriteString(string(chunk))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Fatal("buffer lost data while growing")
+	}
+}
+
+func TestBufferBytesCannotGrowPastSize(t *testing.T) {
+	b, err := NewBufferFrom([]byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Destroy()
+
+	data := b.Bytes()
+	if cap(data) != len(data) {
+		t.Fatalf("cap(Bytes()) = %d, want %d", cap(data), len(data))
+	}
+
+	_ = append(data, "extra"...)
+	if string(b.Bytes()) != "key" {
+		t.Fatalf("append to Bytes() changed the buffer to %q", b.Bytes())
+	}
+}
+
+func TestReadBuffer(t *testing.T) {
+	body := strings.Repeat("x", 3*minBufferGrowth)
+
+	b, err := ReadBuffer(strings.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Destroy()
+
+	if string(b.Bytes()) != body {
+		t.Fatal("ReadBuffer() returned different data")
+	}
+}
+
+func TestReadBufferTooLarge(t *testing.T) {
+	_, err := ReadBuffer(strings.NewReader("0123456789"), 9)
+	if !errors.Is(err, ErrBufferTooLarge) {
+		t.Fatalf("ReadBuffer() error = %v, want %v", err, ErrBufferTooLarge)
+	}
+}
+
+func TestBufferDestroy(t *testing.T) {
+	b, err := NewBufferFrom([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.Destroy()
+	if b.Len() != 0 || len(b.Bytes()) != 0 || b.Locked() {
+		t.Fatalf("destroyed buffer: Len() = %d, Locked() = %v", b.Len(), b.Locked())
+	}
+
+	b.Destroy()
+
+	var nilBuffer *Buffer
+	nilBuffer.Destroy()
+}
+
+func TestWipe(t *testing.T) {
+	data := []byte("correct horse battery staple")
+	Wipe(data)
+
+	if !bytes.Equal(data, make([]byte, len(data))) {
+		t.Fatalf("Wipe() left %q", data)
+	}
+}
@@ -2,6 +2,7 @@ package cryptor
 
 import (
 	"errors"
+	"io"
 )
 
 var (
@@ -10,6 +11,10 @@ var (
 	ErrInvalidKeySize   = errors.New("invalid key size")
 	ErrInvalidBlockSize = errors.New("invalid block size")
 	ErrInvalidPadding   = errors.New("invalid padding")
+	ErrInvalidChunkSize = errors.New("invalid chunk size")
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	ErrInvalidSecretKey = errors.New("invalid secret key")
+	ErrSelfTestFailed   = errors.New("self-test failed")
 )
 
 type Encryptor interface {
@@ -17,6 +22,11 @@ type Encryptor interface {
 	Decrypt(data []byte, key []byte) ([]byte, error)
 }
 
+type StreamEncryptor interface {
+	EncryptWriter(w io.Writer, key []byte) (io.WriteCloser, error)
+	DecryptReader(r io.Reader, key []byte) (io.Reader, error)
+}
+
 type Encoder interface {
 	Encode(data []byte) ([]byte, error)
 	Decode(data []byte) ([]byte, error)
@@ -26,3 +36,10 @@ type Paddinger interface {
 	Padding(data []byte, blockSize int) ([]byte, error)
 	Unpadding(data []byte, blockSize int) ([]byte, error)
 }
+
+type KEM interface {
+	Name() string
+	GenerateKey() (publicKey []byte, privateKey []byte, err error)
+	Encapsulate(publicKey []byte) (sharedKey []byte, ciphertext []byte, err error)
+	Decapsulate(privateKey []byte, ciphertext []byte) (sharedKey []byte, err error)
+}
@@ -0,0 +1,36 @@
+package hex
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+var katData = []byte{'f', 'o', 'o', 0xfb, 0xff}
+
+const katEncoded = "666f6ffbff"
+
+func (e *Encoder) SelfTest() error {
+	const op = "hex.SelfTest"
+
+	encoded, err := e.Encode(katData)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if string(encoded) != katEncoded {
+		return fmt.Errorf("%s: %w: encoding mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	decoded, err := e.Decode([]byte(katEncoded))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if !bytes.Equal(decoded, katData) {
+		return fmt.Errorf("%s: %w: decoding mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package hybrid
+
+import (
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha3"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+var _ cryptor.KEM = (*KEM)(nil)
+
+const (
+	Name = "MLKEM768-X25519"
+
+	x25519Size     = 32
+	PublicKeySize  = mlkem.EncapsulationKeySize768 + x25519Size
+	PrivateKeySize = 32
+	CiphertextSize = mlkem.CiphertextSize768 + x25519Size
+)
+
+var label = []byte(`\.//^\`)
+
+type KEM struct{}
+
+func NewKEM() *KEM {
+	return &KEM{}
+}
+
+func (*KEM) Name() string {
+	return Name
+}
+
+func (*KEM) GenerateKey() ([]byte, []byte, error) {
+	const op = "hybrid.GenerateKey"
+
+	privateKey := make([]byte, PrivateKeySize)
+	_, err := rand.Read(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	dk, xk, err := expandPrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	publicKey := append(dk.EncapsulationKey().Bytes(), xk.PublicKey().Bytes()...)
+
+	return publicKey, privateKey, nil
+}
+
+func (*KEM) Encapsulate(publicKey []byte) ([]byte, []byte, error) {
+	const op = "hybrid.Encapsulate"
+
+	if len(publicKey) != PublicKeySize {
+		return nil, nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidPublicKey)
+	}
+
+	ek, err := mlkem.NewEncapsulationKey768(publicKey[:mlkem.EncapsulationKeySize768])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w: %w", op, cryptor.ErrInvalidPublicKey, err)
+	}
+
+	peer, err := ecdh.X25519().NewPublicKey(publicKey[mlkem.EncapsulationKeySize768:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w: %w", op, cryptor.ErrInvalidPublicKey, err)
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sharedKeyM, ciphertextM := ek.Encapsulate()
+
+	sharedKeyX, err := ephemeral.ECDH(peer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ciphertextX := ephemeral.PublicKey().Bytes()
+
+	return combine(sharedKeyM, sharedKeyX, ciphertextX, peer.Bytes()), append(ciphertextM, ciphertextX...), nil
+}
+
+func (*KEM) Decapsulate(privateKey []byte, ciphertext []byte) ([]byte, error) {
+	const op = "hybrid.Decapsulate"
+
+	if len(privateKey) != PrivateKeySize {
+		return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidSecretKey)
+	}
+
+	if len(ciphertext) != CiphertextSize {
+		return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidData)
+	}
+
+	dk, xk, err := expandPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, cryptor.ErrInvalidSecretKey, err)
+	}
+
+	ciphertextX := ciphertext[mlkem.CiphertextSize768:]
+	ephemeral, err := ecdh.X25519().NewPublicKey(ciphertextX)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, cryptor.ErrInvalidData, err)
+	}
+
+	sharedKeyM, err := dk.Decapsulate(ciphertext[:mlkem.CiphertextSize768])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, cryptor.ErrInvalidData, err)
+	}
+
+	sharedKeyX, err := xk.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return combine(sharedKeyM, sharedKeyX, ciphertextX, xk.PublicKey().Bytes()), nil
+}
+
+func expandPrivateKey(privateKey []byte) (*mlkem.DecapsulationKey768, *ecdh.PrivateKey, error) {
+	expanded := sha3.SumSHAKE256(privateKey, mlkem.SeedSize+x25519Size)
+	defer cryptor.Wipe(expanded)
+
+	dk, err := mlkem.NewDecapsulationKey768(expanded[:mlkem.SeedSize])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	xk, err := ecdh.X25519().NewPrivateKey(expanded[mlkem.SeedSize:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dk, xk, nil
+}
+
+func combine(sharedKeyM, sharedKeyX, ciphertextX, publicKeyX []byte) []byte {
+	h := sha3.New256()
+	h.Write(sharedKeyM)
+	h.Write(sharedKeyX)
+	h.Write(ciphertextX)
+	h.Write(publicKeyX)
+	h.Write(label)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,194 @@
+//go:build go1.26
+
+package hybrid
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/mlkem/mlkemtest"
+	"crypto/sha3"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func sequence(start byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = start + byte(i)
+	}
+
+	return b
+}
+
+func TestMLKEM768KnownAnswer(t *testing.T) {
+	seed := sequence(0x01, mlkem.SeedSize)
+	random := sequence(0x41, 32)
+	want := mustHex(t, "5501fc523b745f41762a188de44a59b920f430146204ee4e793732396df7aa48")
+
+	dk, err := mlkem.NewDecapsulationKey768(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sharedKey, ciphertext, err := mlkemtest.Encapsulate768(dk.EncapsulationKey(), random)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sharedKey, want) {
+		t.Fatalf("encapsulated key = %x, want %x", sharedKey, want)
+	}
+
+	got, err := dk.Decapsulate(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("decapsulated key = %x, want %x", got, want)
+	}
+}
+
+func TestMLKEM768Accumulated(t *testing.T) {
+	const want = "1114b1b6699ed191734fa339376afa7e285c9e6acf6ff0177d346696ce564415"
+
+	s := sha3.NewSHAKE128()
+	o := sha3.NewSHAKE128()
+	seed := make([]byte, mlkem.SeedSize)
+	random := make([]byte, 32)
+	garbage := make([]byte, mlkem.CiphertextSize768)
+
+	for range 100 {
+		_, _ = s.Read(seed)
+		dk, err := mlkem.NewDecapsulationKey768(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ek := dk.EncapsulationKey()
+		_, _ = o.Write(ek.Bytes())
+
+		_, _ = s.Read(random)
+		sharedKey, ciphertext, err := mlkemtest.Encapsulate768(ek, random)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = o.Write(ciphertext)
+		_, _ = o.Write(sharedKey)
+
+		got, err := dk.Decapsulate(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, sharedKey) {
+			t.Fatalf("decapsulated key = %x, want %x", got, sharedKey)
+		}
+
+		_, _ = s.Read(garbage)
+		rejected, err := dk.Decapsulate(garbage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = o.Write(rejected)
+	}
+
+	got := make([]byte, 32)
+	_, _ = o.Read(got)
+	if hex.EncodeToString(got) != want {
+		t.Fatalf("accumulated hash = %x, want %s", got, want)
+	}
+}
+
+type xwingVector struct {
+	Seed       string `json:"seed"`
+	ESeed      string `json:"eseed"`
+	PublicKey  string `json:"public_key"`
+	Ciphertext string `json:"ciphertext"`
+	SharedKey  string `json:"shared_key"`
+}
+
+func loadXWingVectors(t *testing.T) []xwingVector {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/xwing.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vectors []xwingVector
+	err = json.Unmarshal(data, &vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+
+	return vectors
+}
+
+func TestXWingVectors(t *testing.T) {
+	kem := NewKEM()
+
+	for i, vector := range loadXWingVectors(t) {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			privateKey := mustHex(t, vector.Seed)
+			eseed := mustHex(t, vector.ESeed)
+			publicKey := mustHex(t, vector.PublicKey)
+			ciphertext := mustHex(t, vector.Ciphertext)
+			want := mustHex(t, vector.SharedKey)
+
+			gotPublicKey, err := publicKeyOf(privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(gotPublicKey, publicKey) {
+				t.Fatalf("public key = %x, want %x", gotPublicKey, publicKey)
+			}
+
+			got, err := kem.Decapsulate(privateKey, ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("decapsulated key = %x, want %x", got, want)
+			}
+
+			ek, err := mlkem.NewEncapsulationKey768(publicKey[:mlkem.EncapsulationKeySize768])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sharedKeyM, ciphertextM, err := mlkemtest.Encapsulate768(ek, eseed[:32])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ephemeral, err := ecdh.X25519().NewPrivateKey(eseed[32:64])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			peer, err := ecdh.X25519().NewPublicKey(publicKey[mlkem.EncapsulationKeySize768:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sharedKeyX, err := ephemeral.ECDH(peer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertextX := ephemeral.PublicKey().Bytes()
+			if !bytes.Equal(append(ciphertextM, ciphertextX...), ciphertext) {
+				t.Fatal("encapsulated ciphertext does not match the vector")
+			}
+
+			got = combine(sharedKeyM, sharedKeyX, ciphertextX, peer.Bytes())
+			if !bytes.Equal(got, want) {
+				t.Fatalf("encapsulated key = %x, want %x", got, want)
+			}
+		})
+	}
+}
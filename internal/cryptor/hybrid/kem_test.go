@@ -0,0 +1,181 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestX25519RFC7748(t *testing.T) {
+	alicePrivate := mustHex(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	alicePublic := mustHex(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+	bobPublic := mustHex(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	shared := mustHex(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	key, err := ecdh.X25519().NewPrivateKey(alicePrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key.PublicKey().Bytes(), alicePublic) {
+		t.Fatalf("public key = %x, want %x", key.PublicKey().Bytes(), alicePublic)
+	}
+
+	peer, err := ecdh.X25519().NewPublicKey(bobPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := key.ECDH(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, shared) {
+		t.Fatalf("shared secret = %x, want %x", got, shared)
+	}
+}
+
+func TestKEMRoundTrip(t *testing.T) {
+	kem := NewKEM()
+
+	publicKey, privateKey, err := kem.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(publicKey) != PublicKeySize || len(privateKey) != PrivateKeySize {
+		t.Fatalf("key sizes = %d/%d, want %d/%d", len(publicKey), len(privateKey), PublicKeySize, PrivateKeySize)
+	}
+
+	sharedKey, ciphertext, err := kem.Encapsulate(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ciphertext) != CiphertextSize {
+		t.Fatalf("ciphertext size = %d, want %d", len(ciphertext), CiphertextSize)
+	}
+
+	got, err := kem.Decapsulate(privateKey, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, sharedKey) {
+		t.Fatal("decapsulated key does not match encapsulated key")
+	}
+
+	_, otherPrivateKey, err := kem.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = kem.Decapsulate(otherPrivateKey, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, sharedKey) {
+		t.Fatal("another private key decapsulated the same key")
+	}
+}
+
+func TestDecapsulateTamperedCiphertext(t *testing.T) {
+	kem := NewKEM()
+
+	publicKey, privateKey, err := kem.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sharedKey, ciphertext, err := kem.Encapsulate(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		index int
+	}{
+		{name: "ml-kem part", index: 0},
+		{name: "ml-kem part end", index: mlkem.CiphertextSize768 - 1},
+		{name: "x25519 part", index: mlkem.CiphertextSize768},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := bytes.Clone(ciphertext)
+			tampered[tt.index] ^= 0x01
+
+			got, err := kem.Decapsulate(privateKey, tampered)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(got, sharedKey) {
+				t.Fatal("tampered ciphertext decapsulated to the original key")
+			}
+		})
+	}
+
+	t.Run("low order x25519 point", func(t *testing.T) {
+		tampered := bytes.Clone(ciphertext)
+		clear(tampered[mlkem.CiphertextSize768:])
+
+		_, err := kem.Decapsulate(privateKey, tampered)
+		if err == nil {
+			t.Fatal("Decapsulate() accepted an all-zero x25519 share")
+		}
+	})
+}
+
+func TestKEMRejectsMalformedInput(t *testing.T) {
+	kem := NewKEM()
+
+	publicKey, privateKey, err := kem.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ciphertext, err := kem.Encapsulate(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = kem.Encapsulate(publicKey[:PublicKeySize-1])
+	if !errors.Is(err, cryptor.ErrInvalidPublicKey) {
+		t.Fatalf("Encapsulate() error = %v, want %v", err, cryptor.ErrInvalidPublicKey)
+	}
+
+	_, err = kem.Decapsulate(privateKey[:PrivateKeySize-1], ciphertext)
+	if !errors.Is(err, cryptor.ErrInvalidSecretKey) {
+		t.Fatalf("Decapsulate() error = %v, want %v", err, cryptor.ErrInvalidSecretKey)
+	}
+
+	_, err = kem.Decapsulate(privateKey, ciphertext[:CiphertextSize-1])
+	if !errors.Is(err, cryptor.ErrInvalidData) {
+		t.Fatalf("Decapsulate() error = %v, want %v", err, cryptor.ErrInvalidData)
+	}
+
+	_, err = kem.Decapsulate(privateKey, append(bytes.Clone(ciphertext), 0))
+	if !errors.Is(err, cryptor.ErrInvalidData) {
+		t.Fatalf("Decapsulate() error = %v, want %v", err, cryptor.ErrInvalidData)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	err := SelfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
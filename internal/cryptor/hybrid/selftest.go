@@ -0,0 +1,81 @@
+package hybrid
+
+import (
+	"bytes"
+	"crypto/sha3"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+const (
+	katPrivateKey      = "b3f98b03126a431ccecc62ae0f68e102c2d8e1cc7b21ba85d821d8e31761e0f8"
+	katPublicKeyDigest = "9341ea5055dd2d881a61b7057f9ea202115cf01665472c4b122337cb2b707990"
+	katCiphertext      = "b440cb006466e8ee9d161b371b6fa1ec419d6a7589492378dc678fedbcf9e7debfb47f7e0b5368b0e77ef5b5866686b65231dbd1c1a42e0af9b0abb06c795a1af0734b450dbb60fe0486b1497d7b09d0c46617a40c5f8c8ab51c2e8e1f48023f73b7c4716bba2e905d5fb42c3dedff166553ecf033305a57bf436317e6513deea2f65537065bb5d82dc4b8a965c3e939b910dc6b027e01673a6e1399b93976292ef9fd81120ef2f6c47d94a1c77d9fe16ba7107a8a6a4ce9ce0d302847d602167de077e17dbb7e0154202f76c381c4b6d8bca51680dab4dbf373da8f09aa23d2174fb36681ce42108f7baadcb35626baf30a416bd79b3e249585079c277b79b7b31108ef061f25b5d4e548f6f5cc3d4c24fa0f1716843bb63ad00a78f37d2e2b81517810abe9853829bed7b3ba309ad697d8a5f66af4dd237c25725e9c6263744bf8641d475d4792ab0535d2b4fdfcf0c5d95118f5779521023016d49751794a1ce66f2a652436843978937562a4a5e8628d2b720890d7f3b21c151399ba7db03cd15516c6a94b84f6d01a37ba92cc7ac6c480dc9f67c3a066378180bcd2922d3f5c65d69fd0b96aadc055d6b05ebb1105acc609f200e0c945a10e4e11371e23369de2069ccd7175a652c3cd09eb7f17c9b65b4aa79b26468f9b21f8c0aa8f7471d5cfbf3697d3eedea9351597ce981e7cf745c2950070c1f82f132b48584d03ba1262cb856ff6b5ae25992df8612d24f068b4325d3360673ed3ef6e2a57de297d5482c5cc355bc07f1d975fc6d60cd7109bf5a77a0ff7b2c5d9f4a276d30cb49da48b8b90b644b15a5b68fcc67c25f09a8e567cbe4fa2e2ba11c02993e9e9b4116a7c60da64a71932800aec2fb4d2eceef57c6fc2308f3adcd9b46a28748516284bdb4b3a36851512c5e0e6ed37ef5f00b07dc3c42667cf95cad764e47f48a994d17c103f8225755c76008013897c03c31043df0eb39a603e09caeaa41ae24488fe96e4d83b4ae5481045f4a7cfd7c80b31ce9eeb8fdecd34be1245f368ab5a3215cbcdfbe0529e1fbc4ba0041cfaba09836c25dd6219e75fbc6f143e74d686ecd9e1a416881bc21a9129fb865e82332985798f701f7952c4e69e7b4e6bd03bffdc0c65e2a2fde89f73b8659fd2cc7dfb070d3e95581d1bc587a2d9c4bf142fdc1f20856d3cfb64d35744ee279b829184723221e9fb19f012ab99c4bb1a904a116727b667c5a11a0e11f3e31682b0c114345ecc3ee153bccd884654bd5a8a023aa3db878148736f6a090f92785423a9ba2b037b3b90ee91657ba48a125360dae75a6fddfea406ca823a5e4fbb54aa8909fbd85d95d2ed256ed5d6a9194fad0d81a44d3172abf6b90cecd1ed2080762d670db4d3437ef8e9e7d39db4b4215c33f8d19240ed4bf2de8b1076b345707043a735bf9e96e16c8b670cf2df0ce8db638c7d84a13ee7b35266c7f0e60d2cb2e5734e9d646a871d0dfd8b4ee5f825bf799a1251ed21e54510e9c605bc83a0bd9673aee80e8d064a95c3c3151ffd27608173637fb9de30b3c02d96eecac05dbf7c2fbc98b4a1f6972ce928322a22e2b75c"
+	katSharedKey       = "b90cf181d95351d1091569487caaf6c3434eeb181a2c4c04631980ce139afa67"
+)
+
+func SelfTest() error {
+	const op = "hybrid.SelfTest"
+
+	kem := NewKEM()
+
+	privateKey, err := hex.DecodeString(katPrivateKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	ciphertext, err := hex.DecodeString(katCiphertext)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	publicKey, err := publicKeyOf(privateKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	digest := sha3.Sum256(publicKey)
+	if hex.EncodeToString(digest[:]) != katPublicKeyDigest {
+		return fmt.Errorf("%s: %w: public key mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	sharedKey, err := kem.Decapsulate(privateKey, ciphertext)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if hex.EncodeToString(sharedKey) != katSharedKey {
+		return fmt.Errorf("%s: %w: shared key mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	sharedKey, ciphertext, err = kem.Encapsulate(publicKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	decapsulated, err := kem.Decapsulate(privateKey, ciphertext)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if !bytes.Equal(sharedKey, decapsulated) {
+		return fmt.Errorf("%s: %w: pairwise consistency", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
+
+func publicKeyOf(privateKey []byte) ([]byte, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, cryptor.ErrInvalidSecretKey
+	}
+
+	dk, xk, err := expandPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(dk.EncapsulationKey().Bytes(), xk.PublicKey().Bytes()...), nil
+}
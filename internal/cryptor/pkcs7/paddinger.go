@@ -23,9 +23,12 @@ func (p *Paddinger) Padding(data []byte, blockSize int) ([]byte, error) {
 	}
 
 	paddingSize := blockSize - len(data)%blockSize
-	padding := bytes.Repeat([]byte{byte(paddingSize)}, paddingSize)
 
-	return append(data, padding...), nil
+	padded := make([]byte, len(data)+paddingSize)
+	copy(padded, data)
+	copy(padded[len(data):], bytes.Repeat([]byte{byte(paddingSize)}, paddingSize))
+
+	return padded, nil
 }
 
 func (p *Paddinger) Unpadding(data []byte, blockSize int) ([]byte, error) {
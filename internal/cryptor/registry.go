@@ -0,0 +1,163 @@
+package cryptor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown algorithm")
+	ErrNotApproved      = errors.New("algorithm not approved")
+	ErrUnknownMode      = errors.New("unknown crypto mode")
+)
+
+type Mode byte
+
+const (
+	ModeStandard Mode = iota
+	ModeFIPS
+)
+
+func ParseMode(name string) (Mode, error) {
+	switch strings.ToLower(name) {
+	case "", "standard":
+		return ModeStandard, nil
+	case "fips":
+		return ModeFIPS, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownMode, name)
+	}
+}
+
+func (m Mode) String() string {
+	if m == ModeFIPS {
+		return "fips"
+	}
+
+	return "standard"
+}
+
+type SelfTester interface {
+	SelfTest() error
+}
+
+type Registry[T any] struct {
+	mode    Mode
+	entries map[string]registryEntry[T]
+}
+
+type registryEntry[T any] struct {
+	name     string
+	impl     T
+	approved bool
+}
+
+func NewRegistry[T any](mode Mode) *Registry[T] {
+	return &Registry[T]{
+		mode:    mode,
+		entries: make(map[string]registryEntry[T]),
+	}
+}
+
+func (r *Registry[T]) Register(name string, impl T, approved bool) {
+	r.entries[strings.ToLower(name)] = registryEntry[T]{
+		name:     name,
+		impl:     impl,
+		approved: approved,
+	}
+}
+
+func (r *Registry[T]) Get(name string) (T, error) {
+	var zero T
+
+	entry, ok := r.entries[strings.ToLower(name)]
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+
+	if !r.allows(entry) {
+		return zero, fmt.Errorf("%w in %s mode: %q", ErrNotApproved, r.mode, name)
+	}
+
+	return entry.impl, nil
+}
+
+func (r *Registry[T]) Names() []string {
+	names := make([]string, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if r.allows(entry) {
+			names = append(names, entry.name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func (r *Registry[T]) Allowed() []T {
+	names := r.Names()
+
+	impls := make([]T, 0, len(names))
+	for _, name := range names {
+		impls = append(impls, r.entries[strings.ToLower(name)].impl)
+	}
+
+	return impls
+}
+
+func (r *Registry[T]) SelfTest() error {
+	for _, name := range r.Names() {
+		tester, ok := any(r.entries[strings.ToLower(name)].impl).(SelfTester)
+		if !ok {
+			continue
+		}
+
+		err := tester.SelfTest()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Registry[T]) allows(entry registryEntry[T]) bool {
+	return r.mode != ModeFIPS || entry.approved
+}
+
+type KEMRegistry struct {
+	kems map[string]KEM
+}
+
+func NewKEMRegistry(kems ...KEM) *KEMRegistry {
+	registry := &KEMRegistry{
+		kems: make(map[string]KEM, len(kems)),
+	}
+
+	for _, kem := range kems {
+		registry.kems[strings.ToLower(kem.Name())] = kem
+	}
+
+	return registry
+}
+
+func (r *KEMRegistry) KEM(name string) (KEM, error) {
+	kem, ok := r.kems[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+
+	return kem, nil
+}
+
+func (r *KEMRegistry) Names() []string {
+	names := make([]string, 0, len(r.kems))
+	for _, kem := range r.kems {
+		names = append(names, kem.Name())
+	}
+	slices.Sort(names)
+
+	return names
+}
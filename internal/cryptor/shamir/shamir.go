@@ -0,0 +1,137 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+const MaxShares = 255
+
+var (
+	ErrInvalidThreshold = errors.New("invalid share threshold")
+	ErrInvalidShares    = errors.New("invalid shares")
+)
+
+func Split(secret []byte, total, threshold int) ([][]byte, error) {
+	if threshold < 2 || threshold > total || total > MaxShares {
+		return nil, ErrInvalidThreshold
+	}
+
+	if len(secret) == 0 {
+		return nil, ErrInvalidShares
+	}
+
+	shares := make([][]byte, total)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	coeffs := make([]byte, threshold)
+	for idx, b := range secret {
+		coeffs[0] = b
+		_, err := rand.Read(coeffs[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, share := range shares {
+			share[idx+1] = evaluate(coeffs, share[0])
+		}
+	}
+
+	clear(coeffs)
+
+	return shares, nil
+}
+
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrInvalidShares
+	}
+
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrInvalidShares
+	}
+
+	xs := make([]byte, len(shares))
+	for i, share := range shares {
+		if len(share) != size || share[0] == 0 {
+			return nil, ErrInvalidShares
+		}
+
+		for _, x := range xs[:i] {
+			if x == share[0] {
+				return nil, ErrInvalidShares
+			}
+		}
+
+		xs[i] = share[0]
+	}
+
+	secret := make([]byte, size-1)
+	for idx := range secret {
+		var acc byte
+		for i, share := range shares {
+			acc ^= mul(share[idx+1], basis(xs, i))
+		}
+
+		secret[idx] = acc
+	}
+
+	return secret, nil
+}
+
+func Index(share []byte) int {
+	if len(share) == 0 {
+		return 0
+	}
+
+	return int(share[0])
+}
+
+func evaluate(coeffs []byte, x byte) byte {
+	var acc byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		acc = mul(acc, x) ^ coeffs[i]
+	}
+
+	return acc
+}
+
+func basis(xs []byte, i int) byte {
+	num, den := byte(1), byte(1)
+	for j, x := range xs {
+		if j == i {
+			continue
+		}
+
+		num = mul(num, x)
+		den = mul(den, x^xs[i])
+	}
+
+	return mul(num, inv(den))
+}
+
+func mul(a, b byte) byte {
+	var p byte
+	for range 8 {
+		p ^= -(b & 1) & a
+		carry := -(a >> 7)
+		a = (a << 1) ^ (carry & 0x1b)
+		b >>= 1
+	}
+
+	return p
+}
+
+func inv(a byte) byte {
+	res := a
+	for range 6 {
+		a = mul(a, a)
+		res = mul(res, a)
+	}
+
+	return mul(res, res)
+}
@@ -0,0 +1,133 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestFieldArithmetic(t *testing.T) {
+	if got := mul(0x57, 0x83); got != 0xc1 {
+		t.Fatalf("mul(0x57, 0x83) = %#x, want 0xc1", got)
+	}
+
+	if got := inv(0x53); got != 0xca {
+		t.Fatalf("inv(0x53) = %#x, want 0xca", got)
+	}
+
+	for a := 1; a < 256; a++ {
+		if got := mul(byte(a), inv(byte(a))); got != 1 {
+			t.Fatalf("mul(%#x, inv(%#x)) = %#x, want 1", a, a, got)
+		}
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("got %d shares, want 5", len(shares))
+	}
+
+	for i, share := range shares {
+		if Index(share) != i+1 {
+			t.Fatalf("share %d has index %d", i, Index(share))
+		}
+	}
+
+	subsets := [][]int{
+		{0, 1, 2},
+		{2, 3, 4},
+		{4, 0, 2},
+		{0, 1, 2, 3, 4},
+	}
+	for _, subset := range subsets {
+		picked := make([][]byte, 0, len(subset))
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+
+		got, err := Combine(picked)
+		if err != nil {
+			t.Fatalf("Combine(%v) error = %v", subset, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Fatalf("Combine(%v) = %q, want %q", subset, got, secret)
+		}
+	}
+}
+
+func TestCombineBelowThreshold(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, secret) {
+		t.Fatal("two of three shares recovered the secret")
+	}
+}
+
+func TestSplitValidatesParameters(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    []byte
+		total     int
+		threshold int
+		wantErr   error
+	}{
+		{name: "threshold below two", secret: []byte("s"), total: 3, threshold: 1, wantErr: ErrInvalidThreshold},
+		{name: "threshold above total", secret: []byte("s"), total: 3, threshold: 4, wantErr: ErrInvalidThreshold},
+		{name: "too many shares", secret: []byte("s"), total: MaxShares + 1, threshold: 2, wantErr: ErrInvalidThreshold},
+		{name: "empty secret", secret: nil, total: 3, threshold: 2, wantErr: ErrInvalidShares},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Split(tt.secret, tt.total, tt.threshold)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Split() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCombineValidatesShares(t *testing.T) {
+	shares, err := Split([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zeroIndex := bytes.Clone(shares[1])
+	zeroIndex[0] = 0
+
+	tests := []struct {
+		name   string
+		shares [][]byte
+	}{
+		{name: "single share", shares: shares[:1]},
+		{name: "duplicate index", shares: [][]byte{shares[0], shares[0]}},
+		{name: "zero index", shares: [][]byte{shares[0], zeroIndex}},
+		{name: "length mismatch", shares: [][]byte{shares[0], shares[1][:3]}},
+		{name: "empty share", shares: [][]byte{{1}, {2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Combine(tt.shares)
+			if !errors.Is(err, ErrInvalidShares) {
+				t.Fatalf("Combine() error = %v, want %v", err, ErrInvalidShares)
+			}
+		})
+	}
+}
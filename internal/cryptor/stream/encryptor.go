@@ -0,0 +1,314 @@
+package stream
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"golang.org/x/crypto/hkdf"
+)
+
+var _ cryptor.StreamEncryptor = (*Encryptor)(nil)
+
+const (
+	DefaultChunkSize = 64 << 10
+	MaxChunkSize     = 4 << 20
+
+	version     = 1
+	saltSize    = 16
+	headerSize  = 1 + 4 + saltSize
+	keySize     = 32
+	prefixSize  = 7
+	counterSize = 4
+)
+
+var info = []byte("secrets-keeper stream v1")
+
+type Encryptor struct {
+	chunkSize int
+}
+
+func NewEncryptor(chunkSize int) (*Encryptor, error) {
+	const op = "stream.NewEncryptor"
+
+	if chunkSize <= 0 || chunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidChunkSize)
+	}
+
+	return &Encryptor{
+		chunkSize: chunkSize,
+	}, nil
+}
+
+func (e *Encryptor) EncryptWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	const op = "stream.EncryptWriter"
+
+	header := make([]byte, headerSize)
+	header[0] = version
+	binary.BigEndian.PutUint32(header[1:5], uint32(e.chunkSize))
+
+	_, err := rand.Read(header[5:])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	aead, prefix, err := newAEAD(key, header)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &writer{
+		w:      w,
+		aead:   aead,
+		header: header,
+		nonce:  newNonce(prefix),
+		buf:    make([]byte, 0, e.chunkSize),
+		sealed: make([]byte, 0, e.chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (e *Encryptor) DecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	const op = "stream.DecryptReader"
+
+	header := make([]byte, headerSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidData)
+	}
+
+	if header[0] != version {
+		return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidData)
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(header[1:5]))
+	if chunkSize <= 0 || chunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("%s: %w", op, cryptor.ErrInvalidChunkSize)
+	}
+
+	aead, prefix, err := newAEAD(key, header)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &reader{
+		r:      bufio.NewReaderSize(r, chunkSize+aead.Overhead()),
+		aead:   aead,
+		header: header,
+		nonce:  newNonce(prefix),
+		sealed: make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+type writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	nonce   *nonce
+	buf     []byte
+	sealed  []byte
+	closed  bool
+	lastErr error
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	const op = "stream.Write"
+
+	if w.lastErr != nil {
+		return 0, w.lastErr
+	}
+
+	if w.closed {
+		return 0, fmt.Errorf("%s: %w", op, io.ErrClosedPipe)
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if len(w.buf) == cap(w.buf) {
+			err := w.flush(false)
+			if err != nil {
+				w.lastErr = fmt.Errorf("%s: %w", op, err)
+				return written, w.lastErr
+			}
+		}
+
+		n := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (w *writer) Close() error {
+	const op = "stream.Close"
+
+	if w.closed {
+		return w.lastErr
+	}
+	w.closed = true
+
+	if w.lastErr != nil {
+		return w.lastErr
+	}
+
+	err := w.flush(true)
+	if err != nil {
+		w.lastErr = fmt.Errorf("%s: %w", op, err)
+		return w.lastErr
+	}
+
+	return nil
+}
+
+func (w *writer) flush(last bool) error {
+	nonce, err := w.nonce.next(last)
+	if err != nil {
+		return err
+	}
+
+	w.sealed = w.aead.Seal(w.sealed[:0], nonce, w.buf, w.header)
+	w.buf = w.buf[:0]
+
+	_, err = w.w.Write(w.sealed)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type reader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	nonce   *nonce
+	sealed  []byte
+	plain   []byte
+	done    bool
+	lastErr error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	const op = "stream.Read"
+
+	for len(r.plain) == 0 {
+		if r.lastErr != nil {
+			return 0, r.lastErr
+		}
+
+		if r.done {
+			return 0, io.EOF
+		}
+
+		err := r.open()
+		if err != nil {
+			r.lastErr = fmt.Errorf("%s: %w", op, err)
+			return 0, r.lastErr
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+func (r *reader) open() error {
+	n, err := io.ReadFull(r.r, r.sealed)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	last := n < len(r.sealed)
+	if !last {
+		_, err = r.r.Peek(1)
+		last = errors.Is(err, io.EOF)
+		if err != nil && !last {
+			return err
+		}
+	}
+
+	if n < r.aead.Overhead() {
+		return cryptor.ErrInvalidData
+	}
+
+	nonce, err := r.nonce.next(last)
+	if err != nil {
+		return err
+	}
+
+	r.plain, err = r.aead.Open(r.sealed[:0], nonce, r.sealed[:n], r.header)
+	if err != nil {
+		return cryptor.ErrInvalidData
+	}
+	r.done = last
+
+	return nil
+}
+
+type nonce struct {
+	buf     []byte
+	counter uint64
+}
+
+func newNonce(prefix []byte) *nonce {
+	buf := make([]byte, prefixSize+counterSize+1)
+	copy(buf, prefix)
+
+	return &nonce{
+		buf: buf,
+	}
+}
+
+func (n *nonce) next(last bool) ([]byte, error) {
+	if n.counter > math.MaxUint32 {
+		return nil, cryptor.ErrInvalidDataSize
+	}
+
+	binary.BigEndian.PutUint32(n.buf[prefixSize:], uint32(n.counter))
+	n.buf[len(n.buf)-1] = 0
+	if last {
+		n.buf[len(n.buf)-1] = 1
+	}
+	n.counter++
+
+	return n.buf, nil
+}
+
+func newAEAD(key []byte, header []byte) (cipher.AEAD, []byte, error) {
+	if len(key) == 0 {
+		return nil, nil, cryptor.ErrInvalidKeySize
+	}
+
+	derived := make([]byte, keySize+prefixSize)
+	_, err := io.ReadFull(hkdf.New(sha256.New, key, header[5:], info), derived)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(derived[:keySize])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return aead, derived[keySize:], nil
+}
@@ -0,0 +1,244 @@
+package stream
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+const testChunkSize = 64
+
+func newTestEncryptor(t *testing.T) *Encryptor {
+	t.Helper()
+
+	e, err := NewEncryptor(testChunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func encrypt(t *testing.T, e *Encryptor, plaintext, key []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := e.EncryptWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = w.Write(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func decrypt(e *Encryptor, ciphertext, key []byte) ([]byte, error) {
+	r, err := e.DecryptReader(bytes.NewReader(ciphertext), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	e := newTestEncryptor(t)
+	key := []byte("stream test key")
+
+	for _, size := range []int{0, 1, testChunkSize - 1, testChunkSize, testChunkSize + 1, 3 * testChunkSize, 5*testChunkSize + 7} {
+		plaintext := make([]byte, size)
+		_, _ = rand.Read(plaintext)
+
+		ciphertext := encrypt(t, e, plaintext, key)
+
+		got, err := decrypt(e, ciphertext, key)
+		if err != nil {
+			t.Fatalf("size %d: decrypt error = %v", size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("size %d: decrypted data does not match", size)
+		}
+	}
+}
+
+func TestRoundTripSmallWrites(t *testing.T) {
+	e := newTestEncryptor(t)
+	key := []byte("stream test key")
+
+	plaintext := make([]byte, 4*testChunkSize+3)
+	_, _ = rand.Read(plaintext)
+
+	var buf bytes.Buffer
+	w, err := e.EncryptWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range plaintext {
+		_, err = w.Write([]byte{b})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := e.DecryptReader(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]byte, 0, len(plaintext))
+	chunk := make([]byte, 5)
+	for {
+		n, err := r.Read(chunk)
+		got = append(got, chunk[:n]...)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !bytes.Equal(got, plaintext) {
+		t.Fatal("decrypted data does not match")
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	e := newTestEncryptor(t)
+	key := []byte("stream test key")
+
+	plaintext := make([]byte, 3*testChunkSize)
+	_, _ = rand.Read(plaintext)
+
+	ciphertext := encrypt(t, e, plaintext, key)
+	sealedChunk := testChunkSize + 16
+
+	tests := []struct {
+		name   string
+		key    []byte
+		mutate func(c []byte) []byte
+	}{
+		{
+			name: "wrong key",
+			key:  []byte("another key"),
+		},
+		{
+			name: "flipped payload bit",
+			mutate: func(c []byte) []byte {
+				c[headerSize+sealedChunk+3] ^= 0x01
+				return c
+			},
+		},
+		{
+			name: "flipped salt bit",
+			mutate: func(c []byte) []byte {
+				c[headerSize-1] ^= 0x01
+				return c
+			},
+		},
+		{
+			name: "truncated at chunk boundary",
+			mutate: func(c []byte) []byte {
+				return c[:headerSize+2*sealedChunk]
+			},
+		},
+		{
+			name: "truncated mid chunk",
+			mutate: func(c []byte) []byte {
+				return c[:len(c)-5]
+			},
+		},
+		{
+			name: "reordered chunks",
+			mutate: func(c []byte) []byte {
+				first := bytes.Clone(c[headerSize : headerSize+sealedChunk])
+				copy(c[headerSize:], c[headerSize+sealedChunk:headerSize+2*sealedChunk])
+				copy(c[headerSize+sealedChunk:], first)
+				return c
+			},
+		},
+		{
+			name: "appended data",
+			mutate: func(c []byte) []byte {
+				return append(c, c[headerSize:headerSize+sealedChunk]...)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := bytes.Clone(ciphertext)
+			if tt.mutate != nil {
+				tampered = tt.mutate(tampered)
+			}
+
+			decryptKey := key
+			if tt.key != nil {
+				decryptKey = tt.key
+			}
+
+			_, err := decrypt(e, tampered, decryptKey)
+			if !errors.Is(err, cryptor.ErrInvalidData) {
+				t.Fatalf("decrypt error = %v, want %v", err, cryptor.ErrInvalidData)
+			}
+		})
+	}
+}
+
+func TestDecryptRejectsBadHeader(t *testing.T) {
+	e := newTestEncryptor(t)
+	key := []byte("stream test key")
+	ciphertext := encrypt(t, e, []byte("data"), key)
+
+	_, err := e.DecryptReader(bytes.NewReader(ciphertext[:headerSize-1]), key)
+	if !errors.Is(err, cryptor.ErrInvalidData) {
+		t.Fatalf("short header error = %v, want %v", err, cryptor.ErrInvalidData)
+	}
+
+	badVersion := bytes.Clone(ciphertext)
+	badVersion[0] = version + 1
+	_, err = e.DecryptReader(bytes.NewReader(badVersion), key)
+	if !errors.Is(err, cryptor.ErrInvalidData) {
+		t.Fatalf("bad version error = %v, want %v", err, cryptor.ErrInvalidData)
+	}
+
+	badChunkSize := bytes.Clone(ciphertext)
+	badChunkSize[1] = 0xff
+	_, err = e.DecryptReader(bytes.NewReader(badChunkSize), key)
+	if !errors.Is(err, cryptor.ErrInvalidChunkSize) {
+		t.Fatalf("bad chunk size error = %v, want %v", err, cryptor.ErrInvalidChunkSize)
+	}
+}
+
+func TestNewEncryptorValidatesChunkSize(t *testing.T) {
+	for _, size := range []int{0, -1, MaxChunkSize + 1} {
+		_, err := NewEncryptor(size)
+		if !errors.Is(err, cryptor.ErrInvalidChunkSize) {
+			t.Fatalf("NewEncryptor(%d) error = %v, want %v", size, err, cryptor.ErrInvalidChunkSize)
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	err := newTestEncryptor(t).SelfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+const (
+	katKey        = "secrets-keeper stream kat key"
+	katPlaintext  = "secrets-keeper stream kat"
+	katCiphertext = "0100000010215ffd45338eb0cce452dd83f7ae7e7a80a784497babac3218cb3760b53eb34841b0db46c6bcc262e3cea7147d6f3590eb22350189177e652192df70cfec3a095c8adcb5a12faf0a93"
+)
+
+func (e *Encryptor) SelfTest() error {
+	const op = "stream.SelfTest"
+
+	ciphertext, err := hex.DecodeString(katCiphertext)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	decrypted, err := e.decryptAll(ciphertext, []byte(katKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if !bytes.Equal(decrypted, []byte(katPlaintext)) {
+		return fmt.Errorf("%s: %w: plaintext mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	var encrypted bytes.Buffer
+	w, err := e.EncryptWriter(&encrypted, []byte(katKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	_, err = w.Write([]byte(katPlaintext))
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	decrypted, err = e.decryptAll(encrypted.Bytes(), []byte(katKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if !bytes.Equal(decrypted, []byte(katPlaintext)) {
+		return fmt.Errorf("%s: %w: round trip mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
+
+func (e *Encryptor) decryptAll(ciphertext []byte, key []byte) ([]byte, error) {
+	r, err := e.DecryptReader(bytes.NewReader(ciphertext), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
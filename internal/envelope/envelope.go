@@ -0,0 +1,180 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+	ErrUnknownCodec    = errors.New("unknown codec")
+	ErrPayloadTooLarge = errors.New("payload too large")
+)
+
+type Codec byte
+
+const (
+	CodecNone Codec = iota
+	CodecZstd
+)
+
+func ParseCodec(name string) (Codec, error) {
+	switch name {
+	case "", "none":
+		return CodecNone, nil
+	case "zstd":
+		return CodecZstd, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCodec, name)
+	}
+}
+
+const (
+	versionCompressed = 1
+	versionPadded     = 2
+
+	headerSize       = 6
+	paddedHeaderSize = headerSize + 4
+)
+
+var magic = []byte("\x00SKE")
+
+type Options struct {
+	Codec     Codec
+	Threshold int
+	MaxSize   int64
+	Padding   Padding
+}
+
+type Envelope struct {
+	opts    Options
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func New(opts Options) (*Envelope, error) {
+	const op = "envelope.New"
+
+	if opts.MaxSize <= 0 || opts.MaxSize > math.MaxUint32 {
+		return nil, fmt.Errorf("%s: %w: max size out of range", op, ErrPayloadTooLarge)
+	}
+
+	if opts.Padding == nil {
+		opts.Padding = noPadding{}
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	decoder, err := zstd.NewReader(nil,
+		zstd.WithDecoderConcurrency(0),
+		zstd.WithDecoderMaxMemory(uint64(opts.MaxSize)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Envelope{
+		opts:    opts,
+		encoder: encoder,
+		decoder: decoder,
+	}, nil
+}
+
+func (e *Envelope) Seal(payload []byte) ([]byte, error) {
+	const op = "envelope.Seal"
+
+	if int64(len(payload)) > e.opts.MaxSize {
+		return nil, fmt.Errorf("%s: %w", op, ErrPayloadTooLarge)
+	}
+
+	codec, body := CodecNone, payload
+	if e.opts.Codec == CodecZstd && len(payload) >= e.opts.Threshold {
+		compressed := e.encoder.EncodeAll(payload, nil)
+		if len(compressed) < len(payload) {
+			codec, body = CodecZstd, compressed
+		}
+	}
+
+	size := e.opts.Padding.Size(paddedHeaderSize + len(body))
+
+	sealed := make([]byte, size)
+	copy(sealed, magic)
+	sealed[len(magic)] = versionPadded
+	sealed[len(magic)+1] = byte(codec)
+	binary.BigEndian.PutUint32(sealed[headerSize:], uint32(len(body)))
+	copy(sealed[paddedHeaderSize:], body)
+
+	if codec == CodecZstd {
+		clear(body)
+	}
+
+	return sealed, nil
+}
+
+func (e *Envelope) OpenLegacy(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return data, nil
+	}
+
+	return e.Open(data)
+}
+
+func (e *Envelope) Open(data []byte) ([]byte, error) {
+	const op = "envelope.Open"
+
+	if !bytes.HasPrefix(data, magic) || len(data) < headerSize {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidEnvelope)
+	}
+
+	codec, body := Codec(data[len(magic)+1]), data[headerSize:]
+	switch data[len(magic)] {
+	case versionCompressed:
+	case versionPadded:
+		if len(body) < 4 {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidEnvelope)
+		}
+
+		size := binary.BigEndian.Uint32(body)
+		if uint64(size) > uint64(len(body)-4) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidEnvelope)
+		}
+
+		body = body[4 : 4+size]
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidEnvelope)
+	}
+
+	switch codec {
+	case CodecNone:
+		if int64(len(body)) > e.opts.MaxSize {
+			return nil, fmt.Errorf("%s: %w", op, ErrPayloadTooLarge)
+		}
+
+		return body, nil
+	case CodecZstd:
+		payload, err := e.decoder.DecodeAll(body, nil)
+		if err != nil {
+			if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+				return nil, fmt.Errorf("%s: %w", op, ErrPayloadTooLarge)
+			}
+
+			return nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidEnvelope, err)
+		}
+
+		if int64(len(payload)) > e.opts.MaxSize {
+			return nil, fmt.Errorf("%s: %w", op, ErrPayloadTooLarge)
+		}
+
+		return payload, nil
+	default:
+		return nil, fmt.Errorf("%s: %w: %d", op, ErrUnknownCodec, codec)
+	}
+}
@@ -0,0 +1,227 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func newTestEnvelope(t *testing.T, opts Options) *Envelope {
+	t.Helper()
+
+	if opts.MaxSize == 0 {
+		opts.MaxSize = 1 << 20
+	}
+
+	e, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestSealOpen(t *testing.T) {
+	compressible := bytes.Repeat([]byte("secret "), 512)
+	random := make([]byte, 4096)
+	_, _ = rand.Read(random)
+
+	tests := []struct {
+		name      string
+		opts      Options
+		payload   []byte
+		wantCodec Codec
+	}{
+		{name: "no codec", opts: Options{}, payload: compressible, wantCodec: CodecNone},
+		{name: "zstd", opts: Options{Codec: CodecZstd}, payload: compressible, wantCodec: CodecZstd},
+		{name: "zstd below threshold", opts: Options{Codec: CodecZstd, Threshold: 1 << 16}, payload: compressible, wantCodec: CodecNone},
+		{name: "zstd incompressible", opts: Options{Codec: CodecZstd}, payload: random, wantCodec: CodecNone},
+		{name: "empty", opts: Options{Codec: CodecZstd}, payload: []byte{}, wantCodec: CodecNone},
+		{name: "padded", opts: Options{Padding: powerOfTwoPadding{}}, payload: []byte("short"), wantCodec: CodecNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnvelope(t, tt.opts)
+
+			sealed, err := e.Seal(bytes.Clone(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if Codec(sealed[len(magic)+1]) != tt.wantCodec {
+				t.Fatalf("codec = %d, want %d", sealed[len(magic)+1], tt.wantCodec)
+			}
+
+			got, err := e.Open(sealed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.payload) {
+				t.Fatal("opened payload does not match")
+			}
+		})
+	}
+}
+
+func TestOpenLegacyPayloads(t *testing.T) {
+	e := newTestEnvelope(t, Options{Codec: CodecZstd})
+
+	plain := []byte("stored before envelopes existed")
+	got, err := e.OpenLegacy(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Fatal("plain payload was changed")
+	}
+
+	_, err = e.Open(plain)
+	if !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("Open() error = %v, want %v", err, ErrInvalidEnvelope)
+	}
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := bytes.Repeat([]byte("compressed "), 100)
+	compressed := append(append(bytes.Clone(magic), versionCompressed, byte(CodecZstd)), encoder.EncodeAll(payload, nil)...)
+
+	got, err = e.OpenLegacy(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatal("compressed payload does not match")
+	}
+}
+
+func TestPaddingHidesLength(t *testing.T) {
+	e := newTestEnvelope(t, Options{Padding: powerOfTwoPadding{}})
+
+	short, err := e.Seal([]byte("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	long, err := e.Seal(bytes.Repeat([]byte("a"), 200))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(short) != len(long) {
+		t.Fatalf("sealed sizes = %d/%d, want equal", len(short), len(long))
+	}
+}
+
+func TestPaddingSize(t *testing.T) {
+	buckets, err := ParsePadding("buckets:1024, 256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		padding Padding
+		n       int
+		want    int
+	}{
+		{name: "none", padding: noPadding{}, n: 17, want: 17},
+		{name: "pow2 minimum", padding: powerOfTwoPadding{}, n: 1, want: minPowerOfTwoBucket},
+		{name: "pow2 exact", padding: powerOfTwoPadding{}, n: 512, want: 512},
+		{name: "pow2 round up", padding: powerOfTwoPadding{}, n: 513, want: 1024},
+		{name: "bucket smallest", padding: buckets, n: 10, want: 256},
+		{name: "bucket next", padding: buckets, n: 257, want: 1024},
+		{name: "bucket multiple of largest", padding: buckets, n: 1025, want: 2048},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.padding.Size(tt.n); got != tt.want {
+				t.Fatalf("Size(%d) = %d, want %d", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenRejectsMalformedEnvelopes(t *testing.T) {
+	e := newTestEnvelope(t, Options{Codec: CodecZstd})
+
+	sealed, err := e.Seal([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	badVersion := bytes.Clone(sealed)
+	badVersion[len(magic)] = 0xff
+
+	badSize := bytes.Clone(sealed)
+	badSize[headerSize] = 0xff
+
+	badCodec := bytes.Clone(sealed)
+	badCodec[len(magic)+1] = 0xff
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "no magic", data: []byte("garbage from a wrong key"), wantErr: ErrInvalidEnvelope},
+		{name: "short header", data: sealed[:headerSize-1], wantErr: ErrInvalidEnvelope},
+		{name: "missing size", data: sealed[:headerSize+2], wantErr: ErrInvalidEnvelope},
+		{name: "unknown version", data: badVersion, wantErr: ErrInvalidEnvelope},
+		{name: "size past end", data: badSize, wantErr: ErrInvalidEnvelope},
+		{name: "unknown codec", data: badCodec, wantErr: ErrUnknownCodec},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.Open(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Open() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	large := newTestEnvelope(t, Options{Codec: CodecZstd, MaxSize: 1 << 20})
+	small := newTestEnvelope(t, Options{Codec: CodecZstd, MaxSize: 1 << 10})
+
+	sealed, err := large.Seal(make([]byte, 1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = small.Open(sealed)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Open() error = %v, want %v", err, ErrPayloadTooLarge)
+	}
+
+	_, err = small.Seal(make([]byte, 1<<10+1))
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Seal() error = %v, want %v", err, ErrPayloadTooLarge)
+	}
+
+	_, err = New(Options{})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("New() error = %v, want %v", err, ErrPayloadTooLarge)
+	}
+}
+
+func TestParse(t *testing.T) {
+	_, err := ParseCodec("gzip")
+	if !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("ParseCodec() error = %v, want %v", err, ErrUnknownCodec)
+	}
+
+	for _, spec := range []string{"buckets:", "buckets:0", "buckets:a", "fixed"} {
+		_, err = ParsePadding(spec)
+		if !errors.Is(err, ErrInvalidPadding) {
+			t.Fatalf("ParsePadding(%q) error = %v, want %v", spec, err, ErrInvalidPadding)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package envelope
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPadding = errors.New("invalid padding")
+
+const minPowerOfTwoBucket = 256
+
+type Padding interface {
+	Size(n int) int
+}
+
+func ParsePadding(spec string) (Padding, error) {
+	name, args, _ := strings.Cut(spec, ":")
+	switch name {
+	case "", "none":
+		return noPadding{}, nil
+	case "pow2":
+		return powerOfTwoPadding{}, nil
+	case "buckets":
+		sizes := make([]int, 0)
+		for _, arg := range strings.Split(args, ",") {
+			size, err := strconv.Atoi(strings.TrimSpace(arg))
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("%w: bucket %q", ErrInvalidPadding, arg)
+			}
+
+			sizes = append(sizes, size)
+		}
+		slices.Sort(sizes)
+
+		return bucketPadding{sizes: sizes}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPadding, spec)
+	}
+}
+
+type noPadding struct{}
+
+func (noPadding) Size(n int) int {
+	return n
+}
+
+type powerOfTwoPadding struct{}
+
+func (powerOfTwoPadding) Size(n int) int {
+	if n <= minPowerOfTwoBucket {
+		return minPowerOfTwoBucket
+	}
+
+	return 1 << bits.Len(uint(n-1))
+}
+
+type bucketPadding struct {
+	sizes []int
+}
+
+func (p bucketPadding) Size(n int) int {
+	for _, size := range p.sizes {
+		if n <= size {
+			return size
+		}
+	}
+
+	largest := p.sizes[len(p.sizes)-1]
+
+	return (n + largest - 1) / largest * largest
+}
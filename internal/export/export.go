@@ -0,0 +1,266 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+type Format string
+
+const (
+	FormatDotenv     Format = "dotenv"
+	FormatJSON       Format = "json"
+	FormatKubernetes Format = "k8s"
+	FormatShell      Format = "shell"
+)
+
+const messageKey = "SECRET"
+
+type Document struct {
+	ContentType string
+	Filename    string
+	Data        []byte
+}
+
+func ParseFormat(format string) (Format, error) {
+	switch Format(strings.ToLower(format)) {
+	case FormatDotenv, "env", ".env":
+		return FormatDotenv, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatKubernetes, "kubernetes", "yaml":
+		return FormatKubernetes, nil
+	case FormatShell, "sh", "export":
+		return FormatShell, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+func Negotiate(accept string) (Format, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "application/x-dotenv", "text/x-dotenv":
+			return FormatDotenv, true
+		case "application/yaml", "application/x-yaml", "text/yaml":
+			return FormatKubernetes, true
+		case "text/x-shellscript", "application/x-sh":
+			return FormatShell, true
+		}
+	}
+
+	return "", false
+}
+
+func Render(format Format, name string, secret model.Secret) (Document, error) {
+	const op = "export.Render"
+
+	entries := secretEntries(secret)
+
+	switch format {
+	case FormatDotenv:
+		return Document{
+			ContentType: "text/plain; charset=utf-8",
+			Filename:    name + ".env",
+			Data:        renderDotenv(uniqueKeys(entries, envKey)),
+		}, nil
+	case FormatJSON:
+		data, err := renderJSON(uniqueKeys(entries, jsonKey))
+		if err != nil {
+			return Document{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return Document{
+			ContentType: "application/json",
+			Filename:    name + ".json",
+			Data:        data,
+		}, nil
+	case FormatKubernetes:
+		return Document{
+			ContentType: "application/yaml",
+			Filename:    name + ".yaml",
+			Data:        renderKubernetes(name, uniqueKeys(entries, kubernetesKey)),
+		}, nil
+	case FormatShell:
+		return Document{
+			ContentType: "text/x-shellscript; charset=utf-8",
+			Filename:    name + ".sh",
+			Data:        renderShell(uniqueKeys(entries, envKey)),
+		}, nil
+	default:
+		return Document{}, fmt.Errorf("%s: %w: %q", op, ErrUnknownFormat, format)
+	}
+}
+
+type entry struct {
+	name  string
+	value string
+}
+
+func secretEntries(secret model.Secret) []entry {
+	if len(secret.Fields) == 0 {
+		return []entry{{name: messageKey, value: string(secret.Message)}}
+	}
+
+	entries := make([]entry, 0, len(secret.Fields))
+	for _, field := range secret.Fields {
+		entries = append(entries, entry{name: field.Name, value: field.Value})
+	}
+
+	return entries
+}
+
+func renderDotenv(entries []entry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		value := strings.NewReplacer(
+			`\`, `\\`,
+			`"`, `\"`,
+			"\n", `\n`,
+			"\r", `\r`,
+			"$", `\$`,
+		).Replace(e.value)
+
+		fmt.Fprintf(&buf, "%s=\"%s\"\n", e.name, value)
+	}
+
+	return buf.Bytes()
+}
+
+func renderJSON(entries []entry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, e := range entries {
+		key, err := json.Marshal(e.name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(&buf, "  %s: %s", key, value)
+		if i < len(entries)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+func renderKubernetes(name string, entries []entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("apiVersion: v1\n")
+	buf.WriteString("kind: Secret\n")
+	buf.WriteString("metadata:\n")
+	fmt.Fprintf(&buf, "  name: %s\n", kubernetesName(name))
+	buf.WriteString("type: Opaque\n")
+	buf.WriteString("data:\n")
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "  %s: %s\n", e.name, base64.StdEncoding.EncodeToString([]byte(e.value)))
+	}
+
+	return buf.Bytes()
+}
+
+func renderShell(entries []entry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		value := strings.ReplaceAll(e.value, `'`, `'\''`)
+		fmt.Fprintf(&buf, "export %s='%s'\n", e.name, value)
+	}
+
+	return buf.Bytes()
+}
+
+func uniqueKeys(entries []entry, key func(string) string) []entry {
+	keyed := make([]entry, len(entries))
+	taken := make(map[string]bool, len(entries))
+	for i, e := range entries {
+		keyed[i] = entry{name: key(e.name), value: e.value}
+		taken[keyed[i].name] = true
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for i, e := range keyed {
+		if !seen[e.name] {
+			seen[e.name] = true
+			continue
+		}
+
+		for n := 2; ; n++ {
+			suffixed := fmt.Sprintf("%s_%d", e.name, n)
+			if !taken[suffixed] {
+				keyed[i].name = suffixed
+				taken[suffixed] = true
+				seen[suffixed] = true
+				break
+			}
+		}
+	}
+
+	return keyed
+}
+
+func jsonKey(name string) string {
+	return name
+}
+
+func envKey(name string) string {
+	key := []byte(strings.ToUpper(name))
+	for i, c := range key {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			key[i] = '_'
+		}
+	}
+
+	if len(key) == 0 || (key[0] >= '0' && key[0] <= '9') {
+		key = append([]byte{'_'}, key...)
+	}
+
+	return string(key)
+}
+
+func kubernetesKey(name string) string {
+	key := []byte(name)
+	for i, c := range key {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' && c != '.' {
+			key[i] = '_'
+		}
+	}
+
+	if len(key) == 0 {
+		return "_"
+	}
+
+	return string(key)
+}
+
+func kubernetesName(name string) string {
+	key := []byte(strings.ToLower(name))
+	for i, c := range key {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '.' {
+			key[i] = '-'
+		}
+	}
+
+	return strings.Trim(string(key), "-.")
+}
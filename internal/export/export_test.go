@@ -0,0 +1,256 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{
+		"dotenv":     FormatDotenv,
+		".ENV":       FormatDotenv,
+		"json":       FormatJSON,
+		"kubernetes": FormatKubernetes,
+		"yaml":       FormatKubernetes,
+		"sh":         FormatShell,
+		"export":     FormatShell,
+	}
+
+	for input, want := range tests {
+		got, err := ParseFormat(input)
+		if err != nil {
+			t.Fatalf("ParseFormat(%q) error = %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ParseFormat(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	_, err := ParseFormat("toml")
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("ParseFormat() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Format
+		ok     bool
+	}{
+		{accept: "text/x-dotenv", want: FormatDotenv, ok: true},
+		{accept: "application/json, application/yaml;q=0.9", want: FormatKubernetes, ok: true},
+		{accept: "text/x-shellscript", want: FormatShell, ok: true},
+		{accept: "application/json", ok: false},
+		{accept: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Negotiate(tt.accept)
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("Negotiate(%q) = %q, %t, want %q, %t", tt.accept, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	secret := model.Secret{
+		Fields: []model.SecretField{
+			{Name: "db.user", Value: "admin"},
+			{Name: "password", Value: "it's \"$HOME\"\nnext"},
+		},
+	}
+
+	tests := []struct {
+		format      Format
+		filename    string
+		contentType string
+		want        []string
+	}{
+		{
+			format:      FormatDotenv,
+			filename:    "app.env",
+			contentType: "text/plain; charset=utf-8",
+			want: []string{
+				"DB_USER=\"admin\"\n",
+				`PASSWORD="it's \"\$HOME\"\nnext"` + "\n",
+			},
+		},
+		{
+			format:      FormatShell,
+			filename:    "app.sh",
+			contentType: "text/x-shellscript; charset=utf-8",
+			want: []string{
+				"export DB_USER='admin'\n",
+				"export PASSWORD='it'\\''s \"$HOME\"\nnext'\n",
+			},
+		},
+		{
+			format:      FormatKubernetes,
+			filename:    "app.yaml",
+			contentType: "application/yaml",
+			want: []string{
+				"kind: Secret\n",
+				"  name: app\n",
+				"  db.user: YWRtaW4=\n",
+			},
+		},
+		{
+			format:      FormatJSON,
+			filename:    "app.json",
+			contentType: "application/json",
+			want:        []string{`"db.user": "admin"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			doc, err := Render(tt.format, "app", secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if doc.Filename != tt.filename || doc.ContentType != tt.contentType {
+				t.Fatalf("document = %q, %q, want %q, %q", doc.Filename, doc.ContentType, tt.filename, tt.contentType)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(string(doc.Data), want) {
+					t.Fatalf("document does not contain %q:\n%s", want, doc.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderJSONRoundTrip(t *testing.T) {
+	secret := model.Secret{
+		Fields: []model.SecretField{
+			{Name: "a", Value: "1"},
+			{Name: "b\"c", Value: "line\nbreak"},
+		},
+	}
+
+	doc, err := Render(FormatJSON, "app", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]string
+	err = json.Unmarshal(doc.Data, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != "1" || got["b\"c"] != "line\nbreak" {
+		t.Fatalf("decoded = %v", got)
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	doc, err := Render(FormatDotenv, "app", model.Secret{Message: []byte("plain")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(doc.Data) != "SECRET=\"plain\"\n" {
+		t.Fatalf("document = %q", doc.Data)
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	_, err := Render("toml", "app", model.Secret{Message: []byte("plain")})
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Render() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		kubernetes string
+	}{
+		{name: "db-user", env: "DB_USER", kubernetes: "db-user"},
+		{name: "api.key", env: "API_KEY", kubernetes: "api.key"},
+		{name: "1st", env: "_1ST", kubernetes: "1st"},
+		{name: "", env: "_", kubernetes: "_"},
+		{name: "key with spaces", env: "KEY_WITH_SPACES", kubernetes: "key_with_spaces"},
+	}
+
+	for _, tt := range tests {
+		if got := envKey(tt.name); got != tt.env {
+			t.Fatalf("envKey(%q) = %q, want %q", tt.name, got, tt.env)
+		}
+		if got := kubernetesKey(tt.name); got != tt.kubernetes {
+			t.Fatalf("kubernetesKey(%q) = %q, want %q", tt.name, got, tt.kubernetes)
+		}
+	}
+
+	if got := kubernetesName("My App!"); got != "my-app" {
+		t.Fatalf("kubernetesName() = %q, want %q", got, "my-app")
+	}
+}
+
+func TestRenderDisambiguatesKeys(t *testing.T) {
+	secret := model.Secret{Fields: []model.SecretField{
+		{Name: "db-user", Value: "a"},
+		{Name: "db_user", Value: "b"},
+		{Name: "DB_USER_2", Value: "c"},
+		{Name: "db user", Value: "d"},
+	}}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatDotenv,
+			want:   "DB_USER=\"a\"\nDB_USER_3=\"b\"\nDB_USER_2=\"c\"\nDB_USER_4=\"d\"\n",
+		},
+		{
+			format: FormatShell,
+			want:   "export DB_USER='a'\nexport DB_USER_3='b'\nexport DB_USER_2='c'\nexport DB_USER_4='d'\n",
+		},
+		{
+			format: FormatKubernetes,
+			want: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: app\ntype: Opaque\ndata:\n" +
+				"  db-user: YQ==\n  db_user: Yg==\n  DB_USER_2: Yw==\n  db_user_2: ZA==\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			doc, err := Render(tt.format, "app", secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(doc.Data) != tt.want {
+				t.Fatalf("document = %q, want %q", doc.Data, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderJSONDisambiguatesKeys(t *testing.T) {
+	doc, err := Render(FormatJSON, "app", model.Secret{Fields: []model.SecretField{
+		{Name: "key", Value: "a"},
+		{Name: "key", Value: "b"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(doc.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || got["key"] != "a" || got["key_2"] != "b" {
+		t.Fatalf("document = %v", got)
+	}
+}
@@ -0,0 +1,12 @@
+package geoip
+
+import (
+	"errors"
+	"net/netip"
+)
+
+var ErrUnknownCountry = errors.New("unknown country")
+
+type Locator interface {
+	Country(addr netip.Addr) (string, error)
+}
@@ -0,0 +1,144 @@
+package maxmind
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+var _ geoip.Locator = (*Locator)(nil)
+
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+type Locator struct {
+	logger logging.Logger
+	path   string
+
+	mux     sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+}
+
+func NewLocator(logger logging.Logger, path string) (*Locator, error) {
+	const op = "maxmind.NewLocator"
+
+	l := &Locator{
+		logger: logger.With("module", "geoip"),
+		path:   path,
+	}
+
+	err := l.reload()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return l, nil
+}
+
+func (l *Locator) Country(addr netip.Addr) (string, error) {
+	const op = "maxmind.Country"
+
+	if !addr.IsValid() {
+		return "", fmt.Errorf("%s: %w", op, geoip.ErrUnknownCountry)
+	}
+
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	var rec record
+	err := l.reader.Lookup(net.IP(addr.Unmap().AsSlice()), &rec)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if rec.Country.ISOCode == "" {
+		return "", fmt.Errorf("%s: %w", op, geoip.ErrUnknownCountry)
+	}
+
+	return rec.Country.ISOCode, nil
+}
+
+func (l *Locator) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(l.path)
+		if err != nil {
+			l.logger.Error("failed to stat database", "error", err)
+			continue
+		}
+
+		l.mux.RLock()
+		changed := !info.ModTime().Equal(l.modTime)
+		l.mux.RUnlock()
+
+		if !changed {
+			continue
+		}
+
+		err = l.reload()
+		if err != nil {
+			l.logger.Error("failed to reload database", "error", err)
+			continue
+		}
+
+		l.logger.Info("database reloaded", "path", l.path)
+	}
+}
+
+func (l *Locator) Close(_ context.Context) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	err := l.reader.Close()
+	if err != nil {
+		return fmt.Errorf("maxmind.Close: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Locator) reload() error {
+	const op = "reload"
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	reader, err := maxminddb.Open(l.path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.reader != nil {
+		_ = l.reader.Close()
+	}
+
+	l.reader = reader
+	l.modTime = info.ModTime()
+
+	return nil
+}
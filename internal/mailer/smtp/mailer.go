@@ -0,0 +1,187 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/mailer"
+)
+
+var _ mailer.Mailer = (*Mailer)(nil)
+
+var (
+	ErrUnknownTLSMode      = errors.New("unknown smtp tls mode")
+	ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+)
+
+type TLSMode byte
+
+const (
+	TLSStartTLS TLSMode = iota
+	TLSImplicit
+	TLSNone
+)
+
+func ParseTLSMode(name string) (TLSMode, error) {
+	switch name {
+	case "", "starttls":
+		return TLSStartTLS, nil
+	case "tls":
+		return TLSImplicit, nil
+	case "none":
+		return TLSNone, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownTLSMode, name)
+	}
+}
+
+type Options struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	TLS      TLSMode
+	Timeout  time.Duration
+}
+
+type Mailer struct {
+	opts Options
+	host string
+	from *mail.Address
+}
+
+func NewMailer(opts Options) (*Mailer, error) {
+	const op = "smtp.NewMailer"
+
+	host, _, err := net.SplitHostPort(opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	from, err := mail.ParseAddress(opts.From)
+	if err != nil {
+		return nil, fmt.Errorf("%s: from: %w", op, err)
+	}
+
+	return &Mailer{
+		opts: opts,
+		host: host,
+		from: from,
+	}, nil
+}
+
+func (m *Mailer) Send(ctx context.Context, msg mailer.Mail) error {
+	const op = "smtp.Send"
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%s: to: %w", op, err)
+	}
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(m.opts.Timeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if m.opts.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s: %w", op, ErrStartTLSUnsupported)
+		}
+
+		err = client.StartTLS(&tls.Config{ServerName: m.host})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if m.opts.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", m.opts.Username, m.opts.Password, m.host))
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = client.Mail(m.from.Address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = client.Rcpt(to.Address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = w.Write(m.message(to, msg))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = client.Quit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Mailer) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: m.opts.Timeout}
+
+	if m.opts.TLS == TLSImplicit {
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config:    &tls.Config{ServerName: m.host},
+		}
+
+		return tlsDialer.DialContext(ctx, "tcp", m.opts.Addr)
+	}
+
+	return dialer.DialContext(ctx, "tcp", m.opts.Addr)
+}
+
+func (m *Mailer) message(to *mail.Address, msg mailer.Mail) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", m.from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,94 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/mailer"
+	"github.com/protomem/secrets-keeper/internal/mailer/smtp/smtptest"
+)
+
+func newTestServer(t *testing.T) *smtptest.Server {
+	t.Helper()
+
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	return server
+}
+
+func TestMailerSend(t *testing.T) {
+	server := newTestServer(t)
+
+	m, err := NewMailer(Options{
+		Addr:    server.Addr,
+		From:    "Secrets Keeper <noreply@example.com>",
+		TLS:     TLSNone,
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Send(context.Background(), mailer.Mail{
+		To:      "alice@example.com",
+		Subject: "Your one-time code",
+		Body:    "Your one-time code is 123456.\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+
+	msg := messages[0]
+	if msg.From != "noreply@example.com" {
+		t.Fatalf("from = %q, want %q", msg.From, "noreply@example.com")
+	}
+	if len(msg.To) != 1 || msg.To[0] != "alice@example.com" {
+		t.Fatalf("to = %q, want [alice@example.com]", msg.To)
+	}
+
+	for _, want := range []string{
+		"To: <alice@example.com>",
+		"Subject: Your one-time code",
+		"Content-Type: text/plain; charset=utf-8",
+		"Your one-time code is 123456.",
+	} {
+		if !strings.Contains(msg.Data, want) {
+			t.Fatalf("message does not contain %q:\n%s", want, msg.Data)
+		}
+	}
+}
+
+func TestMailerRequiresStartTLS(t *testing.T) {
+	server := newTestServer(t)
+
+	m, err := NewMailer(Options{
+		Addr:    server.Addr,
+		From:    "noreply@example.com",
+		TLS:     TLSStartTLS,
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Send(context.Background(), mailer.Mail{To: "alice@example.com", Subject: "s", Body: "b"})
+	if !errors.Is(err, ErrStartTLSUnsupported) {
+		t.Fatalf("Send() error = %v, want %v", err, ErrStartTLSUnsupported)
+	}
+
+	if got := len(server.Messages()); got != 0 {
+		t.Fatalf("got %d messages, want 0", got)
+	}
+}
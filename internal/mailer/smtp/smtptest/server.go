@@ -0,0 +1,143 @@
+package smtptest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+type Server struct {
+	Addr string
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Addr:     listener.Addr().String(),
+		listener: listener,
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]Message, len(s.messages))
+	copy(messages, s.messages)
+
+	return messages
+}
+
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { _ = conn.Close() }()
+
+			_ = s.handle(textproto.NewConn(conn))
+		}()
+	}
+}
+
+func (s *Server) handle(conn *textproto.Conn) error {
+	err := conn.PrintfLine("220 smtptest ESMTP")
+	if err != nil {
+		return err
+	}
+
+	var msg Message
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return err
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			err = conn.PrintfLine("250 smtptest")
+		case "MAIL":
+			msg = Message{From: trimPath(arg, "FROM:")}
+			err = conn.PrintfLine("250 OK")
+		case "RCPT":
+			msg.To = append(msg.To, trimPath(arg, "TO:"))
+			err = conn.PrintfLine("250 OK")
+		case "DATA":
+			err = conn.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			if err != nil {
+				return err
+			}
+
+			var data []byte
+			data, err = io.ReadAll(conn.DotReader())
+			if err != nil {
+				return err
+			}
+			msg.Data = string(data)
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			err = conn.PrintfLine("250 OK")
+		case "RSET", "NOOP":
+			err = conn.PrintfLine("250 OK")
+		case "QUIT":
+			err = conn.PrintfLine("221 Bye")
+			return errors.Join(err, conn.Close())
+		default:
+			err = conn.PrintfLine("502 Command not implemented")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func trimPath(arg, prefix string) string {
+	if len(arg) >= len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+		arg = arg[len(prefix):]
+	}
+
+	path, _, _ := strings.Cut(strings.TrimSpace(arg), " ")
+	return strings.Trim(path, "<>")
+}
@@ -5,18 +5,288 @@ import (
 	"time"
 )
 
-var ErrSecretNotFound = errors.New("secret not found")
+var (
+	ErrInvalidSecret           = errors.New("invalid secret")
+	ErrSecretNotFound          = errors.New("secret not found")
+	ErrRequestNotFound         = errors.New("secret request not found")
+	ErrStatusNotFound          = errors.New("secret status not found")
+	ErrSecretNotYetAvailable   = errors.New("secret not yet available")
+	ErrSecretReleased          = errors.New("secret already released")
+	ErrAccessDenied            = errors.New("access denied")
+	ErrAttachmentTooLarge      = errors.New("attachment too large")
+	ErrAttachmentNotFound      = errors.New("attachment not found")
+	ErrUploadNotFound          = errors.New("upload not found")
+	ErrUploadOffsetMismatch    = errors.New("upload offset mismatch")
+	ErrUploadIncomplete        = errors.New("upload incomplete")
+	ErrUploadTooLarge          = errors.New("upload too large")
+	ErrInvalidSigner           = errors.New("invalid signer")
+	ErrSignerNotFound          = errors.New("signer not found")
+	ErrSignerExists            = errors.New("signer already registered")
+	ErrSignatureNonceNotFound  = errors.New("signature nonce not found")
+	ErrShareAlreadySubmitted   = errors.New("share already submitted")
+	ErrPakeSessionNotFound     = errors.New("pake session not found")
+	ErrWebAuthnSessionNotFound = errors.New("webauthn session not found")
+	ErrEmailCodeNotFound       = errors.New("email code not found")
+	ErrEmailCodeRecentlySent   = errors.New("email code recently sent")
+)
+
+type SecretNotYetAvailableError struct {
+	AvailableFrom time.Time
+}
+
+func (*SecretNotYetAvailableError) Error() string {
+	return ErrSecretNotYetAvailable.Error()
+}
+
+func (*SecretNotYetAvailableError) Unwrap() error {
+	return ErrSecretNotYetAvailable
+}
 
 type Secret struct {
 	ID int `json:"id"`
 
+	CreatedAt     time.Time `json:"createdAt"`
+	ExpiredAt     time.Time `json:"expiredAt"`
+	AvailableFrom time.Time `json:"availableFrom"`
+
+	AccessKey  string `json:"-"`
+	SigningKey string `json:"-"`
+	KeyCheck   string `json:"-"`
+
+	SecretPhrase   string          `json:"-"`
+	PhraseVerifier *PhraseVerifier `json:"-"`
+	Passkeys       []Passkey       `json:"-"`
+	RecipientEmail string          `json:"-"`
+	FailedAttempts int             `json:"-"`
+
+	CheckInInterval time.Duration `json:"-"`
+	CheckInToken    string        `json:"-"`
+	Released        bool          `json:"-"`
+
+	AllowedCIDRs     []string `json:"-"`
+	AllowedCountries []string `json:"-"`
+	DeniedCountries  []string `json:"-"`
+
+	ReplyKey string `json:"replyKey,omitempty"`
+
+	ShareThreshold int           `json:"-"`
+	ShareTotal     int           `json:"-"`
+	ShareWindow    time.Duration `json:"-"`
+	ShareDigests   []string      `json:"-"`
+
+	Sealed      bool          `json:"-"`
+	PayloadType PayloadType   `json:"payloadType"`
+	Message     SecretText    `json:"message"`
+	Fields      []SecretField `json:"fields,omitempty"`
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	Signature *SecretSignature `json:"-"`
+	Signer    *SecretSigner    `json:"signer,omitempty"`
+}
+
+type SecretSignature struct {
+	Fingerprint   string
+	Value         string
+	TTL           int64
+	AvailableFrom time.Time
+	Nonce         string
+}
+
+type SecretSigner struct {
+	Name          string `json:"name"`
+	Fingerprint   string `json:"fingerprint"`
+	Signature     string `json:"signature"`
+	TTL           int64  `json:"ttl"`
+	AvailableFrom string `json:"availableFrom,omitempty"`
+	Nonce         string `json:"nonce,omitempty"`
+	Verified      bool   `json:"verified"`
+}
+
+type Signer struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	Name        string `json:"name"`
+	PublicKey   string `json:"publicKey"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+type SignatureNonce struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	Fingerprint string `json:"fingerprint"`
+	Nonce       string `json:"nonce"`
+}
+
+type Attachment struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+
+	BlobKey     string `json:"-"`
+	Streamed    bool   `json:"-"`
+	DownloadKey string `json:"downloadKey,omitempty"`
+}
+
+type AttachmentDownload struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	DownloadKey string `json:"-"`
+	BlobKey     string `json:"-"`
+	Streamed    bool   `json:"-"`
+
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+type PayloadType string
+
+const (
+	PayloadText   PayloadType = "text"
+	PayloadFields PayloadType = "fields"
+	PayloadE2E    PayloadType = "e2e"
+	PayloadAge    PayloadType = "age"
+)
+
+type SecretField struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Sensitive   bool   `json:"sensitive"`
+	ContentType string `json:"contentType"`
+}
+
+type PhraseVerifier struct {
+	Salt    []byte
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	W0      []byte
+	L       []byte
+}
+
+type Passkey struct {
+	ID        []byte
+	PublicKey []byte
+}
+
+type EmailCode struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	AccessKey string `json:"-"`
+	CodeHash  string `json:"-"`
+	Attempts  int    `json:"-"`
+}
+
+type WebAuthnSession struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	SessionKey string `json:"-"`
+	AccessKey  string `json:"-"`
+	Challenge  []byte `json:"-"`
+}
+
+type PakeSession struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	SessionKey string `json:"-"`
+	AccessKey  string `json:"-"`
+	ConfirmKey []byte `json:"-"`
+	SharedKey  []byte `json:"-"`
+}
+
+type SecretShare struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	AccessKey string `json:"-"`
+	Index     int    `json:"index"`
+	Share     []byte `json:"-"`
+}
+
+type Upload struct {
+	ID int `json:"id"`
+
 	CreatedAt time.Time `json:"createdAt"`
 	ExpiredAt time.Time `json:"expiredAt"`
 
 	AccessKey  string `json:"-"`
 	SigningKey string `json:"-"`
 
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+
+	Length int64    `json:"length"`
+	Offset int64    `json:"offset"`
+	Chunks []string `json:"-"`
+}
+
+type SecretRequest struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	RequestKey string `json:"-"`
+	AccessKey  string `json:"-"`
+	SigningKey string `json:"-"`
+
 	SecretPhrase string `json:"-"`
 
-	Message string `json:"message"`
+	Note string `json:"note"`
+}
+
+type SecretStatus struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiredAt time.Time `json:"expiredAt"`
+
+	StatusKey    string `json:"-"`
+	StatusSecret string `json:"-"`
+
+	AccessKey      string `json:"-"`
+	ReplyAccessKey string `json:"-"`
+	ReplySecretKey string `json:"-"`
+}
+
+type SecretEventKind string
+
+const (
+	SecretCreated       SecretEventKind = "created"
+	SecretRead          SecretEventKind = "read"
+	SecretFailedAttempt SecretEventKind = "failed_attempt"
+	SecretDestroyed     SecretEventKind = "destroyed"
+	SecretCheckedIn     SecretEventKind = "checked_in"
+	SecretReleased      SecretEventKind = "released"
+)
+
+type SecretEvent struct {
+	ID int `json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	AccessKey string          `json:"-"`
+	Kind      SecretEventKind `json:"kind"`
+	Details   string          `json:"details"`
 }
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var ErrInvalidText = errors.New("invalid text")
+
+type SecretText []byte
+
+func (t SecretText) MarshalJSON() ([]byte, error) {
+	const hex = "0123456789abcdef"
+
+	out := make([]byte, 0, len(t)+2)
+	out = append(out, '"')
+	for i := 0; i < len(t); {
+		c := t[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(t[i:])
+			if r == utf8.RuneError && size == 1 {
+				out = append(out, `\ufffd`...)
+			} else if r == '\u2028' || r == '\u2029' {
+				out = append(out, `\u202`...)
+				out = append(out, hex[r&0xf])
+			} else {
+				out = append(out, t[i:i+size]...)
+			}
+			i += size
+
+			continue
+		}
+
+		switch {
+		case c == '"' || c == '\\':
+			out = append(out, '\\', c)
+		case c == '\n':
+			out = append(out, '\\', 'n')
+		case c == '\r':
+			out = append(out, '\\', 'r')
+		case c == '\t':
+			out = append(out, '\\', 't')
+		case c == '\b':
+			out = append(out, '\\', 'b')
+		case c == '\f':
+			out = append(out, '\\', 'f')
+		case c < 0x20 || c == '<' || c == '>' || c == '&':
+			out = append(out, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		default:
+			out = append(out, c)
+		}
+		i++
+	}
+	out = append(out, '"')
+
+	return out, nil
+}
+
+func (t *SecretText) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return ErrInvalidText
+	}
+	data = data[1 : len(data)-1]
+
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c != '\\' {
+			out = append(out, c)
+			i++
+
+			continue
+		}
+
+		if i+1 >= len(data) {
+			clear(out)
+			return ErrInvalidText
+		}
+
+		switch data[i+1] {
+		case '"', '\\', '/':
+			out = append(out, data[i+1])
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'u':
+			r, size := decodeEscapedRune(data[i:])
+			if size == 0 {
+				clear(out)
+				return ErrInvalidText
+			}
+
+			out = utf8.AppendRune(out, r)
+			i += size
+
+			continue
+		default:
+			clear(out)
+			return ErrInvalidText
+		}
+		i += 2
+	}
+
+	*t = out
+
+	return nil
+}
+
+func decodeEscapedRune(data []byte) (rune, int) {
+	r, ok := decodeHex4(data)
+	if !ok {
+		return 0, 0
+	}
+
+	if !utf16.IsSurrogate(r) {
+		return r, 6
+	}
+
+	low, ok := decodeHex4(data[6:])
+	if !ok {
+		return utf8.RuneError, 6
+	}
+
+	combined := utf16.DecodeRune(r, low)
+	if combined == utf8.RuneError {
+		return utf8.RuneError, 6
+	}
+
+	return combined, 12
+}
+
+func decodeHex4(data []byte) (rune, bool) {
+	if len(data) < 6 || data[0] != '\\' || data[1] != 'u' {
+		return 0, false
+	}
+
+	var r rune
+	for _, c := range data[2:6] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+
+	return r, true
+}
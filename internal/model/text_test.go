@@ -0,0 +1,107 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSecretTextMarshalJSON(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"plain",
+		"quote \" backslash \\ slash /",
+		"line\nbreak\r\ttab\b\f",
+		"html <b>&</b>",
+		"control \x00\x1f",
+		"unicode привет 🔑",
+		"separators \u2028 \u2029",
+	} {
+		got, err := json.Marshal(SecretText(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want, err := json.Marshal(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != string(want) {
+			t.Fatalf("Marshal(%q) = %s, want %s", text, got, want)
+		}
+	}
+}
+
+func TestSecretTextMarshalInvalidUTF8(t *testing.T) {
+	got, err := json.Marshal(SecretText("bad \xff byte"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != `"bad \ufffd byte"` {
+		t.Fatalf("Marshal() = %s", got)
+	}
+}
+
+func TestSecretTextUnmarshalJSON(t *testing.T) {
+	for _, data := range []string{
+		`""`,
+		`"plain"`,
+		`"quote \" backslash \\ slash \/"`,
+		`"line\nbreak\r\ttab\b\f"`,
+		`"escaped \u043f\u0440\u0438 \u00E9"`,
+		`"pair \ud83d\udd11"`,
+		`"lone \ud83d surrogate"`,
+		`"raw привет 🔑"`,
+	} {
+		var got SecretText
+		err := json.Unmarshal([]byte(data), &got)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+
+		var want string
+		err = json.Unmarshal([]byte(data), &want)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Fatalf("Unmarshal(%s) = %q, want %q", data, got, want)
+		}
+	}
+}
+
+func TestSecretTextUnmarshalCopiesInput(t *testing.T) {
+	data := []byte(`{"message":"correct horse"}`)
+
+	var req struct {
+		Message SecretText `json:"message"`
+	}
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clear(data)
+	if string(req.Message) != "correct horse" {
+		t.Fatalf("message = %q after the input was wiped", req.Message)
+	}
+}
+
+func TestSecretTextUnmarshalRejectsNonStrings(t *testing.T) {
+	for _, data := range []string{`1`, `true`, `{}`, `["a"]`} {
+		var got SecretText
+		err := got.UnmarshalJSON([]byte(data))
+		if !errors.Is(err, ErrInvalidText) {
+			t.Fatalf("UnmarshalJSON(%s) error = %v, want %v", data, err, ErrInvalidText)
+		}
+	}
+
+	var got SecretText
+	err := json.Unmarshal([]byte(`null`), &got)
+	if err != nil || got != nil {
+		t.Fatalf("Unmarshal(null) = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,17 @@
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+type Notification struct {
+	Event     string    `json:"event"`
+	SecretID  int       `json:"secretId"`
+	CreatedAt time.Time `json:"createdAt"`
+	Message   string    `json:"message"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
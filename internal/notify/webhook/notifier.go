@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/notify"
+)
+
+var _ notify.Notifier = (*Notifier)(nil)
+
+type Notifier struct {
+	client *http.Client
+	urls   []string
+}
+
+func NewNotifier(timeout time.Duration, urls ...string) *Notifier {
+	return &Notifier{
+		client: &http.Client{Timeout: timeout},
+		urls:   urls,
+	}
+}
+
+func (n *Notifier) Notify(ctx context.Context, notification notify.Notification) error {
+	const op = "webhook.Notify"
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	msgs := make([]string, 0, len(n.urls))
+	for _, url := range n.urls {
+		err = n.send(ctx, url, body)
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+
+	if len(msgs) > 0 {
+		return fmt.Errorf("%s: %w", op, errors.New(strings.Join(msgs, "; ")))
+	}
+
+	return nil
+}
+
+func (n *Notifier) send(ctx context.Context, url string, body []byte) error {
+	const op = "send"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s: %s: unexpected status %d", op, url, res.StatusCode)
+	}
+
+	return nil
+}
@@ -12,7 +12,10 @@ import (
 	"golang.org/x/crypto/argon2"
 )
 
-var _ passhash.Hasher = (*Hasher)(nil)
+var (
+	_ passhash.Hasher     = (*Hasher)(nil)
+	_ passhash.Identifier = (*Hasher)(nil)
+)
 
 var DefaultOptions = Options{
 	Memory:     64 * 1024,
@@ -42,12 +45,12 @@ func NewHasher(encoder cryptor.Encoder, opts Options) *Hasher {
 	}
 }
 
-func (h *Hasher) Generate(password string) (string, error) {
+func (h *Hasher) Generate(password []byte) (string, error) {
 	const op = "argon2.Generate"
 
 	salt := []byte(randstr.Gen(int(h.opts.SaltLength)))
 	hash := argon2.IDKey(
-		[]byte(password), salt,
+		password, salt,
 		h.opts.Iterations, h.opts.Memory, h.opts.Parallel, h.opts.KeyLength,
 	)
 
@@ -59,7 +62,7 @@ func (h *Hasher) Generate(password string) (string, error) {
 	return encodedHash, nil
 }
 
-func (h *Hasher) Compare(password string, hash string) error {
+func (h *Hasher) Compare(password []byte, hash string) error {
 	const op = "argon2.Compare"
 
 	decodedHash, decodedSalt, err := h.decode(hash)
@@ -68,7 +71,7 @@ func (h *Hasher) Compare(password string, hash string) error {
 	}
 
 	newHash := argon2.IDKey(
-		[]byte(password), []byte(decodedSalt),
+		password, []byte(decodedSalt),
 		h.opts.Iterations, h.opts.Memory, h.opts.Parallel, h.opts.KeyLength,
 	)
 
@@ -79,6 +82,10 @@ func (h *Hasher) Compare(password string, hash string) error {
 	return fmt.Errorf("%s: %w", op, passhash.ErrWrongPassword)
 }
 
+func (*Hasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
 func (h *Hasher) encode(hash []byte, salt []byte) (string, error) {
 	const op = "encode"
 	var err error
@@ -0,0 +1,26 @@
+package argon2
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	katPassword = "secrets-keeper"
+	katSalt     = "argon2 kat salt!"
+	katKey      = "295f86b7d82f8ed1726b3f1bc3e228b8f1b3bfb2d2223dfe74e55f30498f9109"
+)
+
+func (h *Hasher) SelfTest() error {
+	const op = "argon2.SelfTest"
+
+	key := argon2.IDKey([]byte(katPassword), []byte(katSalt), 1, 64, 1, 32)
+	if hex.EncodeToString(key) != katKey {
+		return fmt.Errorf("%s: %w: derived key mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
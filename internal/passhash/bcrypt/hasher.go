@@ -3,6 +3,7 @@ package bcrypt
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/protomem/secrets-keeper/internal/passhash"
 	"golang.org/x/crypto/bcrypt"
@@ -14,7 +15,10 @@ const (
 	MaxCost     = bcrypt.MaxCost
 )
 
-var _ passhash.Hasher = (*Hasher)(nil)
+var (
+	_ passhash.Hasher     = (*Hasher)(nil)
+	_ passhash.Identifier = (*Hasher)(nil)
+)
 
 type Hasher struct {
 	cost int
@@ -26,10 +30,10 @@ func NewHasher(cost int) *Hasher {
 	}
 }
 
-func (h *Hasher) Generate(password string) (string, error) {
+func (h *Hasher) Generate(password []byte) (string, error) {
 	const op = "bcrypt.Generate"
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	hash, err := bcrypt.GenerateFromPassword(password, h.cost)
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
@@ -37,10 +41,10 @@ func (h *Hasher) Generate(password string) (string, error) {
 	return string(hash), nil
 }
 
-func (h *Hasher) Compare(password string, hash string) error {
+func (h *Hasher) Compare(password []byte, hash string) error {
 	const op = "bcrypt.Compare"
 
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	err := bcrypt.CompareHashAndPassword([]byte(hash), password)
 	if err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			return fmt.Errorf("%s: %w", op, passhash.ErrWrongPassword)
@@ -51,3 +55,13 @@ func (h *Hasher) Compare(password string, hash string) error {
 
 	return nil
 }
+
+func (*Hasher) Identify(hash string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
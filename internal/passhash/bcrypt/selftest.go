@@ -0,0 +1,30 @@
+package bcrypt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+)
+
+const (
+	katPassword = "secrets-keeper"
+	katHash     = "$2a$04$Dl52s/SzKcm0E1wjI.E3UeeIi7mwaqBTqYjXsVoh8X7HokzWpGd8u"
+)
+
+func (h *Hasher) SelfTest() error {
+	const op = "bcrypt.SelfTest"
+
+	err := h.Compare([]byte(katPassword), katHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	err = h.Compare([]byte(katPassword+"!"), katHash)
+	if !errors.Is(err, passhash.ErrWrongPassword) {
+		return fmt.Errorf("%s: %w: wrong password accepted", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package passhash
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownHash = errors.New("unknown hash format")
+
+type Identifier interface {
+	Identify(hash string) bool
+}
+
+var _ Hasher = (*Dispatcher)(nil)
+
+type Dispatcher struct {
+	generator Hasher
+	verifiers []Hasher
+}
+
+func NewDispatcher(generator Hasher, verifiers ...Hasher) *Dispatcher {
+	return &Dispatcher{
+		generator: generator,
+		verifiers: verifiers,
+	}
+}
+
+func (d *Dispatcher) Generate(password []byte) (string, error) {
+	return d.generator.Generate(password)
+}
+
+func (d *Dispatcher) Compare(password []byte, hash string) error {
+	const op = "passhash.Compare"
+
+	for _, verifier := range d.verifiers {
+		identifier, ok := verifier.(Identifier)
+		if !ok || !identifier.Identify(hash) {
+			continue
+		}
+
+		return verifier.Compare(password, hash)
+	}
+
+	return fmt.Errorf("%s: %w", op, ErrUnknownHash)
+}
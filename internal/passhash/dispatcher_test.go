@@ -0,0 +1,124 @@
+package passhash_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/cryptor/base64"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+	"github.com/protomem/secrets-keeper/internal/passhash/argon2"
+	"github.com/protomem/secrets-keeper/internal/passhash/bcrypt"
+	"github.com/protomem/secrets-keeper/internal/passhash/pbkdf2"
+)
+
+func TestDispatcherCompare(t *testing.T) {
+	encoder := base64.NewEncoder(false)
+	argon2Hasher := argon2.NewHasher(encoder, argon2.Options{Memory: 1024, Iterations: 1, Parallel: 1, SaltLength: 16, KeyLength: 32})
+	bcryptHasher := bcrypt.NewHasher(bcrypt.MinCost)
+	pbkdf2Hasher := pbkdf2.NewHasher(encoder, pbkdf2.Options{Iterations: 1000, SaltLength: 16, KeyLength: 32})
+
+	dispatcher := passhash.NewDispatcher(pbkdf2Hasher, argon2Hasher, bcryptHasher, pbkdf2Hasher)
+
+	for name, hasher := range map[string]passhash.Hasher{
+		"argon2id":      argon2Hasher,
+		"bcrypt":        bcryptHasher,
+		"pbkdf2-sha256": pbkdf2Hasher,
+	} {
+		t.Run(name, func(t *testing.T) {
+			hash, err := hasher.Generate([]byte("correct horse"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = dispatcher.Compare([]byte("correct horse"), hash)
+			if err != nil {
+				t.Fatalf("compare: %v", err)
+			}
+
+			err = dispatcher.Compare([]byte("wrong horse"), hash)
+			if !errors.Is(err, passhash.ErrWrongPassword) {
+				t.Fatalf("expected ErrWrongPassword, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDispatcherGenerate(t *testing.T) {
+	encoder := base64.NewEncoder(false)
+	pbkdf2Hasher := pbkdf2.NewHasher(encoder, pbkdf2.Options{Iterations: 1000, SaltLength: 16, KeyLength: 32})
+
+	dispatcher := passhash.NewDispatcher(pbkdf2Hasher, bcrypt.NewHasher(bcrypt.MinCost), pbkdf2Hasher)
+
+	hash, err := dispatcher.Generate([]byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pbkdf2Hasher.Identify(hash) {
+		t.Fatalf("hash %q was not made by the generator", hash)
+	}
+
+	err = dispatcher.Compare([]byte("correct horse"), hash)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+}
+
+func TestDispatcherUnknownHash(t *testing.T) {
+	encoder := base64.NewEncoder(false)
+	pbkdf2Hasher := pbkdf2.NewHasher(encoder, pbkdf2.Options{Iterations: 1000, SaltLength: 16, KeyLength: 32})
+
+	dispatcher := passhash.NewDispatcher(pbkdf2Hasher, pbkdf2Hasher)
+
+	for _, hash := range []string{
+		"",
+		"plain",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$2a$04$abcdefghijklmnopqrstuu",
+	} {
+		err := dispatcher.Compare([]byte("correct horse"), hash)
+		if !errors.Is(err, passhash.ErrUnknownHash) {
+			t.Fatalf("Compare(%q): expected ErrUnknownHash, got %v", hash, err)
+		}
+	}
+}
+
+func TestDispatcherFIPSRejectsUnapprovedHashes(t *testing.T) {
+	encoder := base64.NewEncoder(false)
+	argon2Hasher := argon2.NewHasher(encoder, argon2.Options{Memory: 1024, Iterations: 1, Parallel: 1, SaltLength: 16, KeyLength: 32})
+	bcryptHasher := bcrypt.NewHasher(bcrypt.MinCost)
+	pbkdf2Hasher := pbkdf2.NewHasher(encoder, pbkdf2.Options{Iterations: 1000, SaltLength: 16, KeyLength: 32})
+
+	hashers := passhash.NewRegistry(cryptor.ModeFIPS)
+	hashers.Register("argon2id", argon2Hasher, false)
+	hashers.Register("bcrypt", bcryptHasher, false)
+	hashers.Register("pbkdf2-sha256", pbkdf2Hasher, true)
+
+	dispatcher := passhash.NewDispatcher(pbkdf2Hasher, hashers.Allowed()...)
+
+	for name, hasher := range map[string]passhash.Hasher{
+		"argon2id": argon2Hasher,
+		"bcrypt":   bcryptHasher,
+	} {
+		hash, err := hasher.Generate([]byte("correct horse"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = dispatcher.Compare([]byte("correct horse"), hash)
+		if !errors.Is(err, passhash.ErrUnknownHash) {
+			t.Fatalf("%s: expected ErrUnknownHash, got %v", name, err)
+		}
+	}
+
+	hash, err := pbkdf2Hasher.Generate([]byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dispatcher.Compare([]byte("correct horse"), hash)
+	if err != nil {
+		t.Fatalf("pbkdf2-sha256: %v", err)
+	}
+}
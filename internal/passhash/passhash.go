@@ -5,6 +5,6 @@ import "errors"
 var ErrWrongPassword = errors.New("wrong password")
 
 type Hasher interface {
-	Generate(password string) (string, error)
-	Compare(password string, hash string) error
+	Generate(password []byte) (string, error)
+	Compare(password []byte, hash string) error
 }
@@ -0,0 +1,147 @@
+package pbkdf2
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+)
+
+const algorithm = "pbkdf2-sha256"
+
+var (
+	_ passhash.Hasher     = (*Hasher)(nil)
+	_ passhash.Identifier = (*Hasher)(nil)
+)
+
+var DefaultOptions = Options{
+	Iterations: 600_000,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+type Options struct {
+	Iterations int
+	SaltLength int
+	KeyLength  int
+}
+
+type Hasher struct {
+	encoder cryptor.Encoder
+	opts    Options
+}
+
+func NewHasher(encoder cryptor.Encoder, opts Options) *Hasher {
+	return &Hasher{
+		encoder: encoder,
+		opts:    opts,
+	}
+}
+
+func (h *Hasher) Generate(password []byte) (string, error) {
+	const op = "pbkdf2.Generate"
+
+	salt := make([]byte, h.opts.SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, string(password), salt, h.opts.Iterations, h.opts.KeyLength)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	encodedHash, err := h.encode(hash, salt)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return encodedHash, nil
+}
+
+func (h *Hasher) Compare(password []byte, hash string) error {
+	const op = "pbkdf2.Compare"
+
+	decodedHash, decodedSalt, err := h.decode(hash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	newHash, err := pbkdf2.Key(sha256.New, string(password), decodedSalt, h.opts.Iterations, len(decodedHash))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if subtle.ConstantTimeCompare(newHash, decodedHash) == 1 {
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", op, passhash.ErrWrongPassword)
+}
+
+func (*Hasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$"+algorithm+"$")
+}
+
+func (h *Hasher) encode(hash []byte, salt []byte) (string, error) {
+	const op = "encode"
+	var err error
+
+	encodedSalt, err := h.encoder.Encode(salt)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	encodedHash, err := h.encoder.Encode(hash)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fmt.Sprintf(
+		"$%s$i=%d$%s$%s",
+		algorithm, h.opts.Iterations, encodedSalt, encodedHash,
+	), nil
+}
+
+func (h *Hasher) decode(encodedHash string) ([]byte, []byte, error) {
+	const op = "decode"
+	var err error
+
+	vals := strings.Split(encodedHash, "$")
+	if len(vals) != 5 || vals[1] != algorithm {
+		return nil, nil, fmt.Errorf("%s: %w", op, errors.New("invalid hash"))
+	}
+
+	var iterations int
+	_, err = fmt.Sscanf(vals[2], "i=%d", &iterations)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if iterations != h.opts.Iterations {
+		return nil, nil, fmt.Errorf("%s: %w", op, errors.New("incorrect options"))
+	}
+
+	salt, err := h.encoder.Decode([]byte(vals[3]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	hash, err := h.encoder.Decode([]byte(vals[4]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(hash) == 0 {
+		return nil, nil, fmt.Errorf("%s: %w", op, errors.New("invalid hash"))
+	}
+
+	return hash, salt, nil
+}
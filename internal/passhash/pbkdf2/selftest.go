@@ -0,0 +1,52 @@
+package pbkdf2
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+)
+
+const (
+	katPassword   = "passwd"
+	katSalt       = "salt"
+	katIterations = 1
+	katKey        = "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+)
+
+func (h *Hasher) SelfTest() error {
+	const op = "pbkdf2.SelfTest"
+
+	key, err := pbkdf2.Key(sha256.New, katPassword, []byte(katSalt), katIterations, len(katKey)/2)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	if hex.EncodeToString(key) != katKey {
+		return fmt.Errorf("%s: %w: derived key mismatch", op, cryptor.ErrSelfTestFailed)
+	}
+
+	kat := NewHasher(h.encoder, Options{Iterations: katIterations, SaltLength: h.opts.SaltLength, KeyLength: h.opts.KeyLength})
+
+	hash, err := kat.Generate([]byte(katPassword))
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	err = kat.Compare([]byte(katPassword), hash)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, cryptor.ErrSelfTestFailed, err)
+	}
+
+	err = kat.Compare([]byte(katSalt), hash)
+	if !errors.Is(err, passhash.ErrWrongPassword) {
+		return fmt.Errorf("%s: %w: wrong password accepted", op, cryptor.ErrSelfTestFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+package passhash
+
+import "github.com/protomem/secrets-keeper/internal/cryptor"
+
+type Registry = cryptor.Registry[Hasher]
+
+func NewRegistry(mode cryptor.Mode) *Registry {
+	return cryptor.NewRegistry[Hasher](mode)
+}
@@ -0,0 +1,148 @@
+package recipient
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	kemKeySeparator    = ":"
+	kemIdentitySuffix  = "-SECRET"
+	kemWrapInfoPrefix  = "secrets-keeper/kem/"
+	kemFileKeySize     = 16
+	kemWrappedFileSize = kemFileKeySize + chacha20poly1305.Overhead
+)
+
+var _ age.Recipient = (*kemRecipient)(nil)
+
+type kemRecipient struct {
+	kem       cryptor.KEM
+	publicKey []byte
+}
+
+func (r *kemRecipient) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	sharedKey, ciphertext, err := r.kem.Encapsulate(r.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newKEMWrapAEAD(r.kem, sharedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil)
+
+	return []*age.Stanza{{
+		Type: r.kem.Name(),
+		Body: append(ciphertext, wrapped...),
+	}}, nil
+}
+
+var _ age.Identity = (*KEMIdentity)(nil)
+
+type KEMIdentity struct {
+	kem        cryptor.KEM
+	privateKey []byte
+}
+
+func ParseKEMIdentity(identity string, kems *cryptor.KEMRegistry) (*KEMIdentity, error) {
+	name, encoded, ok := strings.Cut(strings.TrimSpace(identity), kemKeySeparator)
+	if !ok || !strings.HasSuffix(name, kemIdentitySuffix) {
+		return nil, fmt.Errorf("%w: malformed identity", ErrInvalidRecipient)
+	}
+
+	kem, err := kems.KEM(strings.TrimSuffix(name, kemIdentitySuffix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecipient, err)
+	}
+
+	privateKey, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecipient, err)
+	}
+
+	return &KEMIdentity{
+		kem:        kem,
+		privateKey: privateKey,
+	}, nil
+}
+
+func (i *KEMIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, stanza := range stanzas {
+		if stanza.Type != i.kem.Name() || len(stanza.Body) < kemWrappedFileSize {
+			continue
+		}
+
+		split := len(stanza.Body) - kemWrappedFileSize
+
+		sharedKey, err := i.kem.Decapsulate(i.privateKey, stanza.Body[:split])
+		if err != nil {
+			continue
+		}
+
+		aead, err := newKEMWrapAEAD(i.kem, sharedKey)
+		if err != nil {
+			return nil, err
+		}
+
+		fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), stanza.Body[split:], nil)
+		if err != nil {
+			continue
+		}
+
+		return fileKey, nil
+	}
+
+	return nil, age.ErrIncorrectIdentity
+}
+
+func FormatKEMKeys(kem cryptor.KEM, publicKey, privateKey []byte) (string, string) {
+	recipient := strings.ToLower(kem.Name()) + kemKeySeparator + base64.RawURLEncoding.EncodeToString(publicKey)
+	identity := strings.ToUpper(kem.Name()) + kemIdentitySuffix + kemKeySeparator + base64.RawURLEncoding.EncodeToString(privateKey)
+
+	return recipient, identity
+}
+
+func parseKEMRecipient(key string, kems *cryptor.KEMRegistry) (age.Recipient, error) {
+	name, encoded, _ := strings.Cut(key, kemKeySeparator)
+
+	kem, err := kems.KEM(name)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = kem.Encapsulate(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kemRecipient{
+		kem:       kem,
+		publicKey: publicKey,
+	}, nil
+}
+
+func newKEMWrapAEAD(kem cryptor.KEM, sharedKey []byte) (cipher.AEAD, error) {
+	wrapKey := make([]byte, chacha20poly1305.KeySize)
+	_, err := io.ReadFull(hkdf.New(sha256.New, sharedKey, nil, []byte(kemWrapInfoPrefix+kem.Name())), wrapKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return chacha20poly1305.New(wrapKey)
+}
@@ -0,0 +1,138 @@
+package recipient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+var ErrInvalidRecipient = errors.New("invalid recipient")
+
+const (
+	agePrefix         = "age1"
+	ageIdentityPrefix = "AGE-SECRET-KEY-1"
+	sshEd25519Prefix  = "ssh-ed25519 "
+	pemPrefix         = "-----BEGIN"
+	commentPrefix     = "#"
+)
+
+func Parse(keys []string, kems *cryptor.KEMRegistry) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+
+		var (
+			recipient age.Recipient
+			err       error
+		)
+		switch {
+		case strings.HasPrefix(key, agePrefix):
+			recipient, err = age.ParseX25519Recipient(key)
+		case strings.HasPrefix(key, sshEd25519Prefix):
+			recipient, err = agessh.ParseRecipient(key)
+		case strings.Contains(key, kemKeySeparator):
+			recipient, err = parseKEMRecipient(key, kems)
+		default:
+			err = errors.New("unsupported key type")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidRecipient, key, err)
+		}
+
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, nil
+}
+
+func Encrypt(plaintext []byte, recipients []age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+
+	armorWriter := armor.NewWriter(&buf)
+
+	w, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(w, bytes.NewReader(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = armorWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func ParseIdentities(data []byte, kems *cryptor.KEMRegistry) ([]age.Identity, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(pemPrefix)) {
+		identity, err := agessh.ParseIdentity(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRecipient, err)
+		}
+
+		return []age.Identity{identity}, nil
+	}
+
+	var identities []age.Identity
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, commentPrefix) {
+			continue
+		}
+
+		var (
+			identity age.Identity
+			err      error
+		)
+		if strings.HasPrefix(line, ageIdentityPrefix) {
+			identity, err = age.ParseX25519Identity(line)
+			if err != nil {
+				err = fmt.Errorf("%w: %w", ErrInvalidRecipient, err)
+			}
+		} else {
+			identity, err = ParseKEMIdentity(line, kems)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, identity)
+	}
+
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("%w: no identities", ErrInvalidRecipient)
+	}
+
+	return identities, nil
+}
+
+func Decrypt(ciphertext []byte, identities []age.Identity) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(ciphertext)
+	if bytes.HasPrefix(bytes.TrimSpace(ciphertext), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(ciphertext)))
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	AttachmentDownloadTable struct {
+		ID          int
+		CreatedAt   string
+		ExpiredAt   string
+		DownloadKey string
+		BlobKey     string
+		Streamed    bool
+		Name        string
+		ContentType string
+		Size        int64
+	}
+
+	DownloadRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) DownloadRepo() *DownloadRepository {
+	return &DownloadRepository{
+		logger: s.logger.With("repository", "download"),
+		db:     s.db,
+	}
+}
+
+func (r *DownloadRepository) GetDownload(ctx context.Context, downloadKey string) (model.AttachmentDownload, error) {
+	const op = "storage.GetDownload"
+	var err error
+
+	query := `
+        SELECT * FROM attachment_downloads WHERE download_key = $1 LIMIT 1
+    `
+
+	downloadTable, err := scanAttachmentDownloadTable(r.db.QueryRowContext(ctx, query, downloadKey))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.AttachmentDownload{}, fmt.Errorf("%s: %w", op, model.ErrAttachmentNotFound)
+		}
+
+		return model.AttachmentDownload{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	download, err := mapAttachmentDownloadTableToModel(downloadTable)
+	if err != nil {
+		return model.AttachmentDownload{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return download, nil
+}
+
+func (r *DownloadRepository) SaveDownload(ctx context.Context, download model.AttachmentDownload) (int, error) {
+	const op = "storage.SaveDownload"
+	var err error
+
+	query := `
+        INSERT INTO
+            attachment_downloads (created_at, expired_at, download_key, blob_key, streamed, name, content_type, size)
+        VALUES
+            ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			download.CreatedAt.UTC().Format(time.RFC3339),
+			download.ExpiredAt.UTC().Format(time.RFC3339),
+			download.DownloadKey,
+			download.BlobKey,
+			download.Streamed,
+			download.Name,
+			download.ContentType,
+			download.Size,
+		).
+		Scan(&download.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return download.ID, nil
+}
+
+func (r *DownloadRepository) RemoveDownload(ctx context.Context, downloadKey string) (bool, error) {
+	const op = "storage.RemoveDownload"
+	var err error
+
+	query := `
+        DELETE FROM attachment_downloads WHERE download_key = $1
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, downloadKey)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *DownloadRepository) TakeExpiredDownloads(ctx context.Context, now time.Time) ([]model.AttachmentDownload, error) {
+	const op = "storage.TakeExpiredDownloads"
+	var err error
+
+	query := `
+        DELETE FROM attachment_downloads WHERE expired_at < $1 RETURNING *
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	downloads := make([]model.AttachmentDownload, 0)
+	for rows.Next() {
+		downloadTable, err := scanAttachmentDownloadTable(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		download, err := mapAttachmentDownloadTableToModel(downloadTable)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		downloads = append(downloads, download)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return downloads, nil
+}
+
+func scanAttachmentDownloadTable(row scanner) (AttachmentDownloadTable, error) {
+	var downloadTable AttachmentDownloadTable
+	err := row.Scan(
+		&downloadTable.ID,
+		&downloadTable.CreatedAt,
+		&downloadTable.ExpiredAt,
+		&downloadTable.DownloadKey,
+		&downloadTable.BlobKey,
+		&downloadTable.Streamed,
+		&downloadTable.Name,
+		&downloadTable.ContentType,
+		&downloadTable.Size,
+	)
+	if err != nil {
+		return AttachmentDownloadTable{}, err
+	}
+
+	return downloadTable, nil
+}
+
+func mapAttachmentDownloadTableToModel(download AttachmentDownloadTable) (model.AttachmentDownload, error) {
+	createdAt, err := time.Parse(time.RFC3339, download.CreatedAt)
+	if err != nil {
+		return model.AttachmentDownload{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, download.ExpiredAt)
+	if err != nil {
+		return model.AttachmentDownload{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	return model.AttachmentDownload{
+		ID:          download.ID,
+		CreatedAt:   createdAt,
+		ExpiredAt:   expiredAt,
+		DownloadKey: download.DownloadKey,
+		BlobKey:     download.BlobKey,
+		Streamed:    download.Streamed,
+		Name:        download.Name,
+		ContentType: download.ContentType,
+		Size:        download.Size,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func TestDownloadRepository(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := store.DownloadRepo()
+	now := time.Now().Truncate(time.Second)
+
+	for key, expiredAt := range map[string]time.Time{
+		"fresh":   now.Add(time.Hour),
+		"expired": now.Add(-time.Hour),
+	} {
+		_, err := repo.SaveDownload(ctx, model.AttachmentDownload{
+			CreatedAt:   now,
+			ExpiredAt:   expiredAt,
+			DownloadKey: key,
+			BlobKey:     "blob-" + key,
+			Streamed:    true,
+			Name:        key + ".txt",
+			ContentType: "text/plain",
+			Size:        42,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expired, err := repo.TakeExpiredDownloads(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].BlobKey != "blob-expired" {
+		t.Fatalf("expired downloads = %+v", expired)
+	}
+
+	download, err := repo.GetDownload(ctx, "fresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !download.Streamed || download.Size != 42 || !download.ExpiredAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected download: %+v", download)
+	}
+
+	for _, want := range []bool{true, false} {
+		removed, err := repo.RemoveDownload(ctx, "fresh")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if removed != want {
+			t.Fatalf("removed = %v, want %v", removed, want)
+		}
+	}
+
+	_, err = repo.GetDownload(ctx, "fresh")
+	if !errors.Is(err, model.ErrAttachmentNotFound) {
+		t.Fatalf("expected ErrAttachmentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	EmailCodeTable struct {
+		ID        int
+		CreatedAt string
+		ExpiredAt string
+		AccessKey string
+		CodeHash  string
+		Attempts  int
+	}
+
+	EmailCodeRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) EmailCodeRepo() *EmailCodeRepository {
+	return &EmailCodeRepository{
+		logger: s.logger.With("repository", "email_code"),
+		db:     s.db,
+	}
+}
+
+func (r *EmailCodeRepository) GetCode(ctx context.Context, accessKey string) (model.EmailCode, error) {
+	const op = "storage.GetCode"
+	var err error
+
+	query := `
+        SELECT * FROM email_codes WHERE access_key = $1 LIMIT 1
+    `
+
+	var codeTable EmailCodeTable
+	err = r.db.
+		QueryRowContext(ctx, query, accessKey).
+		Scan(
+			&codeTable.ID,
+			&codeTable.CreatedAt,
+			&codeTable.ExpiredAt,
+			&codeTable.AccessKey,
+			&codeTable.CodeHash,
+			&codeTable.Attempts,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.EmailCode{}, fmt.Errorf("%s: %w", op, model.ErrEmailCodeNotFound)
+		}
+
+		return model.EmailCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	code, err := mapEmailCodeTableToEmailCodeModel(codeTable)
+	if err != nil {
+		return model.EmailCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code, nil
+}
+
+func (r *EmailCodeRepository) SaveCode(ctx context.Context, code model.EmailCode) (int, error) {
+	const op = "storage.SaveCode"
+	var err error
+
+	query := `
+        INSERT INTO
+            email_codes (created_at, expired_at, access_key, code_hash)
+        VALUES
+            ($1, $2, $3, $4)
+        ON CONFLICT (access_key) DO UPDATE SET
+            created_at = excluded.created_at,
+            expired_at = excluded.expired_at,
+            code_hash  = excluded.code_hash,
+            attempts   = 0
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			code.CreatedAt.UTC().Format(time.RFC3339),
+			code.ExpiredAt.UTC().Format(time.RFC3339),
+			code.AccessKey,
+			code.CodeHash,
+		).
+		Scan(&code.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code.ID, nil
+}
+
+func (r *EmailCodeRepository) ReserveAttempt(ctx context.Context, accessKey string, maxAttempts int) (int, error) {
+	const op = "storage.ReserveAttempt"
+	var err error
+
+	query := `
+        UPDATE email_codes SET attempts = attempts + 1
+        WHERE access_key = $1 AND ($2 <= 0 OR attempts < $2)
+        RETURNING attempts
+    `
+
+	var attempts int
+	err = r.db.
+		QueryRowContext(ctx, query, accessKey, maxAttempts).
+		Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, model.ErrEmailCodeNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return attempts, nil
+}
+
+func (r *EmailCodeRepository) RemoveCode(ctx context.Context, accessKey string) error {
+	const op = "storage.RemoveCode"
+	var err error
+
+	query := `
+        DELETE FROM email_codes WHERE access_key = $1
+    `
+
+	_, err = r.db.
+		ExecContext(ctx, query, accessKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *EmailCodeRepository) RemoveExpiredCodes(ctx context.Context, now time.Time) (int, error) {
+	const op = "storage.RemoveExpiredCodes"
+	var err error
+
+	query := `
+        DELETE FROM email_codes WHERE expired_at < $1
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(affected), nil
+}
+
+func mapEmailCodeTableToEmailCodeModel(code EmailCodeTable) (model.EmailCode, error) {
+	createdAt, err := time.Parse(time.RFC3339, code.CreatedAt)
+	if err != nil {
+		return model.EmailCode{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, code.ExpiredAt)
+	if err != nil {
+		return model.EmailCode{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	return model.EmailCode{
+		ID:        code.ID,
+		CreatedAt: createdAt,
+		ExpiredAt: expiredAt,
+		AccessKey: code.AccessKey,
+		CodeHash:  code.CodeHash,
+		Attempts:  code.Attempts,
+	}, nil
+}
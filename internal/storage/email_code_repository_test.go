@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func TestReserveEmailCodeAttemptIsBounded(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	_, err := store.EmailCodeRepo().SaveCode(ctx, model.EmailCode{
+		CreatedAt: now,
+		ExpiredAt: now.Add(time.Minute),
+		AccessKey: "access",
+		CodeHash:  "hash",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const maxAttempts = 3
+
+	var (
+		wg       sync.WaitGroup
+		reserved atomic.Int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := store.EmailCodeRepo().ReserveAttempt(ctx, "access", maxAttempts)
+			switch {
+			case err == nil:
+				reserved.Add(1)
+			case !errors.Is(err, model.ErrEmailCodeNotFound):
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved.Load() != maxAttempts {
+		t.Fatalf("reserved = %d, want %d", reserved.Load(), maxAttempts)
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	SecretEventTable struct {
+		ID        int
+		CreatedAt string
+		AccessKey string
+		Kind      string
+		Details   string
+	}
+
+	EventRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) EventRepo() *EventRepository {
+	return &EventRepository{
+		logger: s.logger.With("repository", "event"),
+		db:     s.db,
+	}
+}
+
+func (r *EventRepository) FindEvents(ctx context.Context, accessKey string) ([]model.SecretEvent, error) {
+	const op = "storage.FindEvents"
+	var err error
+
+	query := `
+        SELECT * FROM secret_events WHERE access_key = $1 ORDER BY id
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, accessKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := make([]model.SecretEvent, 0)
+	for rows.Next() {
+		var eventTable SecretEventTable
+		err = rows.Scan(
+			&eventTable.ID,
+			&eventTable.CreatedAt,
+			&eventTable.AccessKey,
+			&eventTable.Kind,
+			&eventTable.Details,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		event, err := mapSecretEventTableToSecretEventModel(eventTable)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		events = append(events, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+func (r *EventRepository) SaveEvent(ctx context.Context, event model.SecretEvent) (int, error) {
+	const op = "storage.SaveEvent"
+	var err error
+
+	query := `
+        INSERT INTO 
+            secret_events (created_at, access_key, kind, details) 
+        VALUES 
+            ($1, $2, $3, $4) 
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			event.CreatedAt.Format(time.RFC3339),
+			event.AccessKey,
+			string(event.Kind),
+			event.Details,
+		).
+		Scan(&event.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return event.ID, nil
+}
+
+func mapSecretEventTableToSecretEventModel(event SecretEventTable) (model.SecretEvent, error) {
+	createdAt, err := time.Parse(time.RFC3339, event.CreatedAt)
+	if err != nil {
+		return model.SecretEvent{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	return model.SecretEvent{
+		ID:        event.ID,
+		CreatedAt: createdAt,
+		AccessKey: event.AccessKey,
+		Kind:      model.SecretEventKind(event.Kind),
+		Details:   event.Details,
+	}, nil
+}
@@ -2,11 +2,18 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
+	"time"
 
 	"github.com/protomem/secrets-keeper/assets"
 )
 
+const migrationVersionsDir = "migrations/versions"
+
 func (s *Storage) Migrate(ctx context.Context) error {
 	const op = "storage.Migrate"
 	var err error
@@ -21,5 +28,62 @@ func (s *Storage) Migrate(ctx context.Context) error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	versions, err := fs.ReadDir(assets.Assets, migrationVersionsDir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, version := range versions {
+		if version.IsDir() || path.Ext(version.Name()) != ".sql" {
+			continue
+		}
+
+		err = s.applyMigration(ctx, version.Name())
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", op, version.Name(), err)
+		}
+	}
+
 	return nil
 }
+
+func (s *Storage) applyMigration(ctx context.Context, version string) error {
+	var err error
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var applied string
+	err = tx.
+		QueryRowContext(ctx, `SELECT version FROM schema_migrations WHERE version = $1`, version).
+		Scan(&applied)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	migrationFile, err := assets.Assets.ReadFile(path.Join(migrationVersionsDir, version))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, string(migrationFile))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`,
+		version, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging/stdlog"
+)
+
+const baselineSchema = `
+CREATE TABLE IF NOT EXISTS secrets (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    created_at TEXT NOT NULL,
+    expired_at TEXT NOT NULL,
+
+    access_key  TEXT NOT NULL UNIQUE,
+    signing_key TEXT NOT NULL,
+
+    secret_phrase TEXT NOT NULL,
+
+    message TEXT NOT NULL
+);
+`
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	logger, err := stdlog.New("error")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := New(context.Background(), logger, filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close(context.Background()) })
+
+	return store
+}
+
+func secretColumnSet(t *testing.T, store *Storage) map[string]bool {
+	t.Helper()
+
+	rows, err := store.db.Query(`SELECT name FROM pragma_table_info('secrets')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		columns[name] = true
+	}
+
+	return columns
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	for i := 0; i < 2; i++ {
+		if err := store.Migrate(ctx); err != nil {
+			t.Fatalf("migrate #%d: %v", i+1, err)
+		}
+	}
+
+	if !secretColumnSet(t, store)["failed_attempts"] {
+		t.Fatal("failed_attempts column is missing")
+	}
+}
+
+func TestMigrateUpgradesBaselineSchema(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	_, err := store.db.Exec(baselineSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = store.db.Exec(
+		`INSERT INTO secrets (created_at, expired_at, access_key, signing_key, secret_phrase, message)
+         VALUES ('2024-01-01T00:00:00Z', '2024-01-01T00:00:00Z', 'legacy', 'key', '', 'message')`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var failedAttempts int
+	err = store.db.QueryRow(`SELECT failed_attempts FROM secrets WHERE access_key = 'legacy'`).Scan(&failedAttempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failedAttempts != 0 {
+		t.Fatalf("failed_attempts = %d, want 0", failedAttempts)
+	}
+
+	secret, err := store.SecretRepo().GetSecret(ctx, "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Message) != "message" || secret.PayloadType != model.PayloadText || len(secret.Attachments) != 0 {
+		t.Fatalf("unexpected legacy secret: %+v", secret)
+	}
+	if !secret.AvailableFrom.IsZero() {
+		t.Fatalf("available from = %v, want zero", secret.AvailableFrom)
+	}
+
+	_, err = store.SecretRepo().SaveSecret(ctx, model.Secret{
+		CreatedAt:      secret.CreatedAt,
+		ExpiredAt:      secret.ExpiredAt,
+		AccessKey:      "fresh",
+		SigningKey:     "key",
+		RecipientEmail: "bob@example.com",
+		PayloadType:    model.PayloadText,
+		Message:        []byte("message"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err := store.SecretRepo().GetSecret(ctx, "fresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.RecipientEmail != "bob@example.com" {
+		t.Fatalf("recipient email = %q", fresh.RecipientEmail)
+	}
+}
+
+func TestMigrateDropsStoredShares(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	_, err := store.db.Exec(`
+        CREATE TABLE secret_shares (
+            id          INTEGER PRIMARY KEY AUTOINCREMENT,
+            created_at  TEXT    NOT NULL,
+            expired_at  TEXT    NOT NULL,
+            access_key  TEXT    NOT NULL,
+            share_index INTEGER NOT NULL,
+            share       TEXT    NOT NULL,
+            UNIQUE (access_key, share_index)
+        );
+        INSERT INTO secret_shares (created_at, expired_at, access_key, share_index, share)
+        VALUES ('2024-01-01T00:00:00Z', '2024-01-01T01:00:00Z', 'legacy', 1, '01abcdef');
+    `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.Migrate(ctx); err != nil {
+			t.Fatalf("migrate #%d: %v", i+1, err)
+		}
+	}
+
+	var count int
+	err = store.db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'secret_shares'`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatal("secret_shares table is still present")
+	}
+}
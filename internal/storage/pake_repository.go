@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	PakeSessionTable struct {
+		ID         int
+		CreatedAt  string
+		ExpiredAt  string
+		SessionKey string
+		AccessKey  string
+		ConfirmKey string
+		SharedKey  string
+	}
+
+	PakeRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) PakeRepo() *PakeRepository {
+	return &PakeRepository{
+		logger: s.logger.With("repository", "pake"),
+		db:     s.db,
+	}
+}
+
+func (r *PakeRepository) TakeSession(ctx context.Context, sessionKey string) (model.PakeSession, error) {
+	const op = "storage.TakeSession"
+	var err error
+
+	query := `
+        DELETE FROM pake_sessions WHERE session_key = $1 RETURNING *
+    `
+
+	var sessionTable PakeSessionTable
+	err = r.db.
+		QueryRowContext(ctx, query, sessionKey).
+		Scan(
+			&sessionTable.ID,
+			&sessionTable.CreatedAt,
+			&sessionTable.ExpiredAt,
+			&sessionTable.SessionKey,
+			&sessionTable.AccessKey,
+			&sessionTable.ConfirmKey,
+			&sessionTable.SharedKey,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PakeSession{}, fmt.Errorf("%s: %w", op, model.ErrPakeSessionNotFound)
+		}
+
+		return model.PakeSession{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	session, err := mapPakeSessionTableToPakeSessionModel(sessionTable)
+	if err != nil {
+		return model.PakeSession{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session, nil
+}
+
+func (r *PakeRepository) SaveSession(ctx context.Context, session model.PakeSession) (int, error) {
+	const op = "storage.SaveSession"
+	var err error
+
+	query := `
+        INSERT INTO
+            pake_sessions (created_at, expired_at, session_key, access_key, confirm_key, shared_key)
+        VALUES
+            ($1, $2, $3, $4, $5, $6)
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			session.CreatedAt.UTC().Format(time.RFC3339),
+			session.ExpiredAt.UTC().Format(time.RFC3339),
+			session.SessionKey,
+			session.AccessKey,
+			hex.EncodeToString(session.ConfirmKey),
+			hex.EncodeToString(session.SharedKey),
+		).
+		Scan(&session.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session.ID, nil
+}
+
+func (r *PakeRepository) RemoveExpiredSessions(ctx context.Context, now time.Time) (int, error) {
+	const op = "storage.RemoveExpiredSessions"
+	var err error
+
+	query := `
+        DELETE FROM pake_sessions WHERE expired_at < $1
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(affected), nil
+}
+
+func mapPakeSessionTableToPakeSessionModel(session PakeSessionTable) (model.PakeSession, error) {
+	createdAt, err := time.Parse(time.RFC3339, session.CreatedAt)
+	if err != nil {
+		return model.PakeSession{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, session.ExpiredAt)
+	if err != nil {
+		return model.PakeSession{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	confirmKey, err := hex.DecodeString(session.ConfirmKey)
+	if err != nil {
+		return model.PakeSession{}, fmt.Errorf("parse confirm key: %w", err)
+	}
+
+	sharedKey, err := hex.DecodeString(session.SharedKey)
+	if err != nil {
+		return model.PakeSession{}, fmt.Errorf("parse shared key: %w", err)
+	}
+
+	return model.PakeSession{
+		ID:         session.ID,
+		CreatedAt:  createdAt,
+		ExpiredAt:  expiredAt,
+		SessionKey: session.SessionKey,
+		AccessKey:  session.AccessKey,
+		ConfirmKey: confirmKey,
+		SharedKey:  sharedKey,
+	}, nil
+}
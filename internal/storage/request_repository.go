@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	SecretRequestTable struct {
+		ID           int
+		CreatedAt    string
+		ExpiredAt    string
+		RequestKey   string
+		AccessKey    string
+		SigningKey   string
+		SecretPhrase string
+		Note         string
+	}
+
+	RequestRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) RequestRepo() *RequestRepository {
+	return &RequestRepository{
+		logger: s.logger.With("repository", "request"),
+		db:     s.db,
+	}
+}
+
+func (r *RequestRepository) GetRequest(ctx context.Context, requestKey string) (model.SecretRequest, error) {
+	const op = "storage.GetRequest"
+	var err error
+
+	query := `
+        SELECT * FROM secret_requests WHERE request_key = $1 LIMIT 1
+    `
+
+	var requestTable SecretRequestTable
+	err = r.db.
+		QueryRowContext(ctx, query, requestKey).
+		Scan(
+			&requestTable.ID,
+			&requestTable.CreatedAt,
+			&requestTable.ExpiredAt,
+			&requestTable.RequestKey,
+			&requestTable.AccessKey,
+			&requestTable.SigningKey,
+			&requestTable.SecretPhrase,
+			&requestTable.Note,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.SecretRequest{}, fmt.Errorf("%s: %w", op, model.ErrRequestNotFound)
+		}
+
+		return model.SecretRequest{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	request, err := mapSecretRequestTableToSecretRequestModel(requestTable)
+	if err != nil {
+		return model.SecretRequest{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return request, nil
+}
+
+func (r *RequestRepository) SaveRequest(ctx context.Context, request model.SecretRequest) (int, error) {
+	const op = "storage.SaveRequest"
+	var err error
+
+	query := `
+        INSERT INTO 
+            secret_requests (created_at, expired_at, request_key, access_key, signing_key, secret_phrase, note) 
+        VALUES 
+            ($1, $2, $3, $4, $5, $6, $7) 
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			request.CreatedAt.Format(time.RFC3339),
+			request.ExpiredAt.Format(time.RFC3339),
+			request.RequestKey,
+			request.AccessKey,
+			request.SigningKey,
+			request.SecretPhrase,
+			request.Note,
+		).
+		Scan(&request.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return request.ID, nil
+}
+
+func (r *RequestRepository) RemoveRequest(ctx context.Context, requestKey string) error {
+	const op = "storage.RemoveRequest"
+	var err error
+
+	query := `
+        DELETE FROM secret_requests WHERE request_key = $1
+    `
+
+	_, err = r.db.
+		ExecContext(ctx, query, requestKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func mapSecretRequestTableToSecretRequestModel(request SecretRequestTable) (model.SecretRequest, error) {
+	createdAt, err := time.Parse(time.RFC3339, request.CreatedAt)
+	if err != nil {
+		return model.SecretRequest{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, request.ExpiredAt)
+	if err != nil {
+		return model.SecretRequest{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	return model.SecretRequest{
+		ID:           request.ID,
+		CreatedAt:    createdAt,
+		ExpiredAt:    expiredAt,
+		RequestKey:   request.RequestKey,
+		AccessKey:    request.AccessKey,
+		SigningKey:   request.SigningKey,
+		SecretPhrase: request.SecretPhrase,
+		Note:         request.Note,
+	}, nil
+}
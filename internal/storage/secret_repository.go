@@ -3,8 +3,10 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/protomem/secrets-keeper/internal/model"
@@ -13,13 +15,34 @@ import (
 
 type (
 	SecretTable struct {
-		ID           int
-		CreatedAt    string
-		ExpiredAt    string
-		AccessKey    string
-		SigningKey   string
-		SecretPhrase string
-		Message      string
+		ID               int
+		CreatedAt        string
+		ExpiredAt        string
+		AvailableFrom    string
+		AccessKey        string
+		SigningKey       string
+		KeyCheck         string
+		SecretPhrase     string
+		PhraseVerifier   string
+		Passkeys         string
+		RecipientEmail   string
+		FailedAttempts   int
+		CheckInInterval  int64
+		CheckInToken     string
+		Released         bool
+		AllowedCIDRs     string
+		AllowedCountries string
+		DeniedCountries  string
+		ReplyKey         string
+		ShareThreshold   int
+		ShareTotal       int
+		ShareWindow      int64
+		ShareDigests     string
+		Attachments      string
+		Signature        string
+		Sealed           bool
+		PayloadType      string
+		Message          string
 	}
 
 	SecretRepository struct {
@@ -40,21 +63,10 @@ func (r *SecretRepository) GetSecret(ctx context.Context, accessKey string) (mod
 	var err error
 
 	query := `
-        SELECT * FROM secrets WHERE access_key = $1 LIMIT 1
+        SELECT ` + secretColumns + ` FROM secrets WHERE access_key = $1 LIMIT 1
     `
 
-	var secretTable SecretTable
-	err = r.db.
-		QueryRowContext(ctx, query, accessKey).
-		Scan(
-			&secretTable.ID,
-			&secretTable.CreatedAt,
-			&secretTable.ExpiredAt,
-			&secretTable.AccessKey,
-			&secretTable.SigningKey,
-			&secretTable.SecretPhrase,
-			&secretTable.Message,
-		)
+	secretTable, err := scanSecretTable(r.db.QueryRowContext(ctx, query, accessKey))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
@@ -75,11 +87,39 @@ func (r *SecretRepository) SaveSecret(ctx context.Context, secret model.Secret)
 	const op = "storage.SaveSecret"
 	var err error
 
+	attachments, err := marshalAttachments(secret.Attachments)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	signature, err := marshalSignature(secret.Signature)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	phraseVerifier, err := marshalPhraseVerifier(secret.PhraseVerifier)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	passkeys, err := marshalPasskeys(secret.Passkeys)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
 	query := `
         INSERT INTO 
-            secrets (created_at, expired_at, access_key, signing_key, secret_phrase, message) 
+            secrets (
+                created_at, expired_at, available_from, access_key, signing_key, key_check, secret_phrase,
+                phrase_verifier, passkeys, recipient_email, check_in_interval, check_in_token, allowed_cidrs,
+                allowed_countries, denied_countries, reply_key, share_threshold, share_total, share_window,
+                share_digests, attachments, signature, sealed, payload_type, message
+            ) 
         VALUES 
-            ($1, $2, $3, $4, $5, $6) 
+            (
+                $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+                $21, $22, $23, $24, $25
+            ) 
         RETURNING id
     `
 
@@ -88,10 +128,29 @@ func (r *SecretRepository) SaveSecret(ctx context.Context, secret model.Secret)
 			ctx, query,
 			secret.CreatedAt.Format(time.RFC3339),
 			secret.ExpiredAt.Format(time.RFC3339),
+			secret.AvailableFrom.Format(time.RFC3339),
 			secret.AccessKey,
 			secret.SigningKey,
+			secret.KeyCheck,
 			secret.SecretPhrase,
-			secret.Message,
+			phraseVerifier,
+			passkeys,
+			secret.RecipientEmail,
+			int64(secret.CheckInInterval/time.Second),
+			secret.CheckInToken,
+			strings.Join(secret.AllowedCIDRs, ","),
+			strings.Join(secret.AllowedCountries, ","),
+			strings.Join(secret.DeniedCountries, ","),
+			secret.ReplyKey,
+			secret.ShareThreshold,
+			secret.ShareTotal,
+			int64(secret.ShareWindow/time.Second),
+			strings.Join(secret.ShareDigests, ","),
+			attachments,
+			signature,
+			secret.Sealed,
+			string(secret.PayloadType),
+			string(secret.Message),
 		).
 		Scan(&secret.ID)
 	if err != nil {
@@ -118,6 +177,248 @@ func (r *SecretRepository) RemoveSecret(ctx context.Context, accessKey string) e
 	return nil
 }
 
+func (r *SecretRepository) IncrementFailedAttempts(ctx context.Context, accessKey string) (int, error) {
+	const op = "storage.IncrementFailedAttempts"
+	var err error
+
+	query := `
+        UPDATE secrets SET failed_attempts = failed_attempts + 1 WHERE access_key = $1 RETURNING failed_attempts
+    `
+
+	var failedAttempts int
+	err = r.db.
+		QueryRowContext(ctx, query, accessKey).
+		Scan(&failedAttempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return failedAttempts, nil
+}
+
+func (r *SecretRepository) ReserveAttempt(ctx context.Context, accessKey string, maxAttempts int) (int, error) {
+	const op = "storage.ReserveAttempt"
+	var err error
+
+	query := `
+        UPDATE secrets SET failed_attempts = failed_attempts + 1
+        WHERE access_key = $1 AND ($2 <= 0 OR failed_attempts < $2)
+        RETURNING failed_attempts
+    `
+
+	var failedAttempts int
+	err = r.db.
+		QueryRowContext(ctx, query, accessKey, maxAttempts).
+		Scan(&failedAttempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return failedAttempts, nil
+}
+
+func (r *SecretRepository) ReleaseAttempt(ctx context.Context, accessKey string) error {
+	const op = "storage.ReleaseAttempt"
+	var err error
+
+	query := `
+        UPDATE secrets SET failed_attempts = failed_attempts - 1 WHERE access_key = $1 AND failed_attempts > 0
+    `
+
+	_, err = r.db.
+		ExecContext(ctx, query, accessKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *SecretRepository) FindUnreleasedCheckInSecrets(ctx context.Context) ([]model.Secret, error) {
+	const op = "storage.FindUnreleasedCheckInSecrets"
+	var err error
+
+	query := `
+        SELECT ` + secretColumns + ` FROM secrets WHERE check_in_interval > 0 AND released = 0
+    `
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	secrets := make([]model.Secret, 0)
+	for rows.Next() {
+		secretTable, err := scanSecretTable(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		secret, err := mapSeacretTableToSecretModel(secretTable)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return secrets, nil
+}
+
+func (r *SecretRepository) FindExpiredSecrets(ctx context.Context, now time.Time) ([]model.Secret, error) {
+	const op = "storage.FindExpiredSecrets"
+	var err error
+
+	query := `
+        SELECT access_key, attachments FROM secrets
+        WHERE julianday(expired_at) > julianday(created_at) AND julianday(expired_at) < julianday($1)
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	secrets := make([]model.Secret, 0)
+	for rows.Next() {
+		var accessKey, attachmentsData string
+		err = rows.Scan(&accessKey, &attachmentsData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		attachments, err := unmarshalAttachments(attachmentsData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		secrets = append(secrets, model.Secret{
+			AccessKey:   accessKey,
+			Attachments: attachments,
+		})
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return secrets, nil
+}
+
+func (r *SecretRepository) CheckInSecret(ctx context.Context, accessKey string, availableFrom time.Time) error {
+	const op = "storage.CheckInSecret"
+	var err error
+
+	query := `
+        UPDATE secrets SET available_from = $1 WHERE access_key = $2 AND released = 0
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, availableFrom.Format(time.RFC3339), accessKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, model.ErrSecretReleased)
+	}
+
+	return nil
+}
+
+func (r *SecretRepository) ReleaseSecret(ctx context.Context, accessKey string) (bool, error) {
+	const op = "storage.ReleaseSecret"
+	var err error
+
+	query := `
+        UPDATE secrets SET released = 1 WHERE access_key = $1 AND released = 0
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, accessKey)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return affected > 0, nil
+}
+
+const secretColumns = `
+            id, created_at, expired_at, available_from, access_key, signing_key, key_check, secret_phrase,
+            phrase_verifier, passkeys, recipient_email, failed_attempts, check_in_interval, check_in_token,
+            released, allowed_cidrs, allowed_countries, denied_countries, reply_key, share_threshold,
+            share_total, share_window, share_digests, attachments, signature, sealed, payload_type, message
+        `
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSecretTable(row scanner) (SecretTable, error) {
+	var secretTable SecretTable
+	err := row.Scan(
+		&secretTable.ID,
+		&secretTable.CreatedAt,
+		&secretTable.ExpiredAt,
+		&secretTable.AvailableFrom,
+		&secretTable.AccessKey,
+		&secretTable.SigningKey,
+		&secretTable.KeyCheck,
+		&secretTable.SecretPhrase,
+		&secretTable.PhraseVerifier,
+		&secretTable.Passkeys,
+		&secretTable.RecipientEmail,
+		&secretTable.FailedAttempts,
+		&secretTable.CheckInInterval,
+		&secretTable.CheckInToken,
+		&secretTable.Released,
+		&secretTable.AllowedCIDRs,
+		&secretTable.AllowedCountries,
+		&secretTable.DeniedCountries,
+		&secretTable.ReplyKey,
+		&secretTable.ShareThreshold,
+		&secretTable.ShareTotal,
+		&secretTable.ShareWindow,
+		&secretTable.ShareDigests,
+		&secretTable.Attachments,
+		&secretTable.Signature,
+		&secretTable.Sealed,
+		&secretTable.PayloadType,
+		&secretTable.Message,
+	)
+	if err != nil {
+		return SecretTable{}, err
+	}
+
+	return secretTable, nil
+}
+
 func mapSeacretTableToSecretModel(secret SecretTable) (model.Secret, error) {
 	createdAt, err := time.Parse(time.RFC3339, secret.CreatedAt)
 	if err != nil {
@@ -129,13 +430,258 @@ func mapSeacretTableToSecretModel(secret SecretTable) (model.Secret, error) {
 		return model.Secret{}, fmt.Errorf("parse expired at: %w", err)
 	}
 
+	availableFrom, err := time.Parse(time.RFC3339, secret.AvailableFrom)
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("parse available from: %w", err)
+	}
+
+	attachments, err := unmarshalAttachments(secret.Attachments)
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	signature, err := unmarshalSignature(secret.Signature)
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	phraseVerifier, err := unmarshalPhraseVerifier(secret.PhraseVerifier)
+	if err != nil {
+		return model.Secret{}, err
+	}
+
+	passkeys, err := unmarshalPasskeys(secret.Passkeys)
+	if err != nil {
+		return model.Secret{}, err
+	}
+
 	return model.Secret{
-		ID:           secret.ID,
-		CreatedAt:    createdAt,
-		ExpiredAt:    expiredAt,
-		AccessKey:    secret.AccessKey,
-		SigningKey:   secret.SigningKey,
-		SecretPhrase: secret.SecretPhrase,
-		Message:      secret.Message,
+		ID:               secret.ID,
+		CreatedAt:        createdAt,
+		ExpiredAt:        expiredAt,
+		AvailableFrom:    availableFrom,
+		AccessKey:        secret.AccessKey,
+		SigningKey:       secret.SigningKey,
+		KeyCheck:         secret.KeyCheck,
+		SecretPhrase:     secret.SecretPhrase,
+		PhraseVerifier:   phraseVerifier,
+		Passkeys:         passkeys,
+		RecipientEmail:   secret.RecipientEmail,
+		FailedAttempts:   secret.FailedAttempts,
+		CheckInInterval:  time.Duration(secret.CheckInInterval) * time.Second,
+		CheckInToken:     secret.CheckInToken,
+		Released:         secret.Released,
+		AllowedCIDRs:     splitList(secret.AllowedCIDRs),
+		AllowedCountries: splitList(secret.AllowedCountries),
+		DeniedCountries:  splitList(secret.DeniedCountries),
+		ReplyKey:         secret.ReplyKey,
+		ShareThreshold:   secret.ShareThreshold,
+		ShareTotal:       secret.ShareTotal,
+		ShareWindow:      time.Duration(secret.ShareWindow) * time.Second,
+		ShareDigests:     splitList(secret.ShareDigests),
+		Attachments:      attachments,
+		Signature:        signature,
+		Sealed:           secret.Sealed,
+		PayloadType:      model.PayloadType(secret.PayloadType),
+		Message:          model.SecretText(secret.Message),
 	}, nil
 }
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	return strings.Split(list, ",")
+}
+
+type attachmentTable struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	BlobKey     string `json:"blobKey"`
+	Streamed    bool   `json:"streamed,omitempty"`
+}
+
+func marshalAttachments(attachments []model.Attachment) (string, error) {
+	tables := make([]attachmentTable, 0, len(attachments))
+	for _, attachment := range attachments {
+		tables = append(tables, attachmentTable{
+			ID:          attachment.ID,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			BlobKey:     attachment.BlobKey,
+			Streamed:    attachment.Streamed,
+		})
+	}
+
+	data, err := json.Marshal(tables)
+	if err != nil {
+		return "", fmt.Errorf("marshal attachments: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func unmarshalAttachments(data string) ([]model.Attachment, error) {
+	var tables []attachmentTable
+	err := json.Unmarshal([]byte(data), &tables)
+	if err != nil {
+		return nil, fmt.Errorf("parse attachments: %w", err)
+	}
+
+	attachments := make([]model.Attachment, 0, len(tables))
+	for _, table := range tables {
+		attachments = append(attachments, model.Attachment{
+			ID:          table.ID,
+			Name:        table.Name,
+			ContentType: table.ContentType,
+			Size:        table.Size,
+			BlobKey:     table.BlobKey,
+			Streamed:    table.Streamed,
+		})
+	}
+
+	return attachments, nil
+}
+
+type signatureTable struct {
+	Fingerprint   string `json:"fingerprint"`
+	Value         string `json:"value"`
+	TTL           int64  `json:"ttl"`
+	AvailableFrom string `json:"availableFrom,omitempty"`
+	Nonce         string `json:"nonce,omitempty"`
+}
+
+func marshalSignature(signature *model.SecretSignature) (string, error) {
+	if signature == nil {
+		return "", nil
+	}
+
+	table := signatureTable{
+		Fingerprint: signature.Fingerprint,
+		Value:       signature.Value,
+		TTL:         signature.TTL,
+		Nonce:       signature.Nonce,
+	}
+
+	if !signature.AvailableFrom.IsZero() {
+		table.AvailableFrom = signature.AvailableFrom.UTC().Format(time.RFC3339)
+	}
+
+	data, err := json.Marshal(table)
+	if err != nil {
+		return "", fmt.Errorf("marshal signature: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func unmarshalSignature(data string) (*model.SecretSignature, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var table signatureTable
+	err := json.Unmarshal([]byte(data), &table)
+	if err != nil {
+		return nil, fmt.Errorf("parse signature: %w", err)
+	}
+
+	signature := &model.SecretSignature{
+		Fingerprint: table.Fingerprint,
+		Value:       table.Value,
+		TTL:         table.TTL,
+		Nonce:       table.Nonce,
+	}
+
+	if table.AvailableFrom != "" {
+		signature.AvailableFrom, err = time.Parse(time.RFC3339, table.AvailableFrom)
+		if err != nil {
+			return nil, fmt.Errorf("parse signature available from: %w", err)
+		}
+	}
+
+	return signature, nil
+}
+
+type phraseVerifierTable struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	W0      []byte `json:"w0"`
+	L       []byte `json:"l"`
+}
+
+func marshalPhraseVerifier(verifier *model.PhraseVerifier) (string, error) {
+	if verifier == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(phraseVerifierTable(*verifier))
+	if err != nil {
+		return "", fmt.Errorf("marshal phrase verifier: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func unmarshalPhraseVerifier(data string) (*model.PhraseVerifier, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var table phraseVerifierTable
+	err := json.Unmarshal([]byte(data), &table)
+	if err != nil {
+		return nil, fmt.Errorf("parse phrase verifier: %w", err)
+	}
+
+	verifier := model.PhraseVerifier(table)
+	return &verifier, nil
+}
+
+type passkeyTable struct {
+	ID        []byte `json:"id"`
+	PublicKey []byte `json:"publicKey"`
+}
+
+func marshalPasskeys(passkeys []model.Passkey) (string, error) {
+	if len(passkeys) == 0 {
+		return "", nil
+	}
+
+	tables := make([]passkeyTable, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		tables = append(tables, passkeyTable(passkey))
+	}
+
+	data, err := json.Marshal(tables)
+	if err != nil {
+		return "", fmt.Errorf("marshal passkeys: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func unmarshalPasskeys(data string) ([]model.Passkey, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var tables []passkeyTable
+	err := json.Unmarshal([]byte(data), &tables)
+	if err != nil {
+		return nil, fmt.Errorf("parse passkeys: %w", err)
+	}
+
+	passkeys := make([]model.Passkey, 0, len(tables))
+	for _, table := range tables {
+		passkeys = append(passkeys, model.Passkey(table))
+	}
+
+	return passkeys, nil
+}
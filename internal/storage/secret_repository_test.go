@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func saveTestSecret(t *testing.T, store *Storage, accessKey string) {
+	t.Helper()
+
+	now := time.Now()
+	_, err := store.SecretRepo().SaveSecret(context.Background(), model.Secret{
+		CreatedAt:   now,
+		ExpiredAt:   now.Add(time.Hour),
+		AccessKey:   accessKey,
+		SigningKey:  "key",
+		PayloadType: model.PayloadText,
+		Message:     []byte("message"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReserveAttemptIsBounded(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	saveTestSecret(t, store, "access")
+
+	const maxAttempts = 3
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+		refused  int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := store.SecretRepo().ReserveAttempt(ctx, "access", maxAttempts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				reserved++
+			case errors.Is(err, model.ErrSecretNotFound):
+				refused++
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != maxAttempts || refused != 20-maxAttempts {
+		t.Fatalf("reserved = %d, refused = %d", reserved, refused)
+	}
+}
+
+func TestReleaseAttemptRollsBackReservation(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	saveTestSecret(t, store, "access")
+
+	repo := store.SecretRepo()
+	for i := 0; i < 5; i++ {
+		failedAttempts, err := repo.ReserveAttempt(ctx, "access", 1)
+		if err != nil {
+			t.Fatalf("reservation #%d: %v", i+1, err)
+		}
+		if failedAttempts != 1 {
+			t.Fatalf("failed attempts = %d, want 1", failedAttempts)
+		}
+
+		if err := repo.ReleaseAttempt(ctx, "access"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secret, err := repo.GetSecret(ctx, "access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.FailedAttempts != 0 {
+		t.Fatalf("failed attempts = %d, want 0", secret.FailedAttempts)
+	}
+}
+
+func TestFindExpiredSecrets(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	east := time.FixedZone("UTC+5", 5*60*60)
+	attachments := []model.Attachment{{ID: "a", Name: "a.txt", BlobKey: "blob"}}
+
+	for _, secret := range []model.Secret{
+		{AccessKey: "expired", CreatedAt: now.Add(-2 * time.Hour), ExpiredAt: now.Add(-time.Hour), Attachments: attachments},
+		{AccessKey: "expired-offset", CreatedAt: now.Add(-2 * time.Hour).In(east), ExpiredAt: now.Add(-time.Minute).In(east)},
+		{AccessKey: "live", CreatedAt: now, ExpiredAt: now.Add(time.Hour)},
+		{AccessKey: "live-offset", CreatedAt: now.In(east), ExpiredAt: now.Add(time.Hour).In(east)},
+		{AccessKey: "no-ttl", CreatedAt: now.Add(-time.Hour), ExpiredAt: now.Add(-time.Hour)},
+	} {
+		secret.SigningKey = "key"
+		secret.PayloadType = model.PayloadText
+		secret.Message = []byte("message")
+
+		_, err := store.SecretRepo().SaveSecret(ctx, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secrets, err := store.SecretRepo().FindExpiredSecrets(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]model.Secret, len(secrets))
+	for _, secret := range secrets {
+		found[secret.AccessKey] = secret
+	}
+
+	if len(found) != 2 {
+		t.Fatalf("found %d secrets, want expired and expired-offset: %v", len(found), found)
+	}
+
+	expired, ok := found["expired"]
+	if !ok || len(expired.Attachments) != 1 || expired.Attachments[0].BlobKey != "blob" {
+		t.Fatalf("expired = %+v, want its attachments", expired)
+	}
+
+	if _, ok := found["expired-offset"]; !ok {
+		t.Fatal("expired-offset was not found")
+	}
+}
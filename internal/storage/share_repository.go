@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	pendingShares struct {
+		mux    sync.Mutex
+		lastID int
+		shares map[string][]model.SecretShare
+	}
+
+	ShareRepository struct {
+		logger  logging.Logger
+		pending *pendingShares
+	}
+)
+
+func newPendingShares() *pendingShares {
+	return &pendingShares{
+		shares: make(map[string][]model.SecretShare),
+	}
+}
+
+func (s *Storage) ShareRepo() *ShareRepository {
+	return &ShareRepository{
+		logger:  s.logger.With("repository", "share"),
+		pending: s.shares,
+	}
+}
+
+func (r *ShareRepository) FindShares(_ context.Context, accessKey string) ([]model.SecretShare, error) {
+	r.pending.mux.Lock()
+	defer r.pending.mux.Unlock()
+
+	stored := r.pending.shares[accessKey]
+
+	shares := make([]model.SecretShare, 0, len(stored))
+	for _, share := range stored {
+		share.Share = bytes.Clone(share.Share)
+		shares = append(shares, share)
+	}
+
+	return shares, nil
+}
+
+func (r *ShareRepository) SaveShare(_ context.Context, share model.SecretShare) (int, error) {
+	const op = "storage.SaveShare"
+
+	r.pending.mux.Lock()
+	defer r.pending.mux.Unlock()
+
+	stored := r.pending.shares[share.AccessKey]
+
+	idx, found := slices.BinarySearchFunc(stored, share.Index, func(s model.SecretShare, index int) int {
+		return s.Index - index
+	})
+	if found {
+		return 0, fmt.Errorf("%s: %w", op, model.ErrShareAlreadySubmitted)
+	}
+
+	r.pending.lastID++
+	share.ID = r.pending.lastID
+	share.Share = bytes.Clone(share.Share)
+
+	r.pending.shares[share.AccessKey] = slices.Insert(stored, idx, share)
+
+	return share.ID, nil
+}
+
+func (r *ShareRepository) RemoveShares(_ context.Context, accessKey string) error {
+	r.pending.mux.Lock()
+	defer r.pending.mux.Unlock()
+
+	r.pending.remove(accessKey)
+
+	return nil
+}
+
+func (r *ShareRepository) RemoveExpiredShares(_ context.Context, now time.Time) (int, error) {
+	r.pending.mux.Lock()
+	defer r.pending.mux.Unlock()
+
+	removed := 0
+	for accessKey, shares := range r.pending.shares {
+		if len(shares) == 0 || !shares[0].ExpiredAt.Before(now) {
+			continue
+		}
+
+		removed += len(shares)
+		r.pending.remove(accessKey)
+	}
+
+	return removed, nil
+}
+
+func (p *pendingShares) remove(accessKey string) {
+	for _, share := range p.shares[accessKey] {
+		clear(share.Share)
+	}
+
+	delete(p.shares, accessKey)
+}
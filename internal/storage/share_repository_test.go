@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func TestShareRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestStorage(t).ShareRepo()
+	now := time.Now()
+
+	share := []byte{2, 0xaa, 0xbb}
+	for _, s := range []model.SecretShare{
+		{CreatedAt: now, ExpiredAt: now.Add(time.Hour), AccessKey: "pending", Index: 2, Share: share},
+		{CreatedAt: now, ExpiredAt: now.Add(time.Hour), AccessKey: "pending", Index: 1, Share: []byte{1, 0xcc, 0xdd}},
+		{CreatedAt: now, ExpiredAt: now.Add(-time.Minute), AccessKey: "expired", Index: 1, Share: []byte{1, 0xee, 0xff}},
+	} {
+		_, err := repo.SaveShare(ctx, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	clear(share)
+
+	_, err := repo.SaveShare(ctx, model.SecretShare{ExpiredAt: now.Add(time.Hour), AccessKey: "pending", Index: 2, Share: []byte{2}})
+	if !errors.Is(err, model.ErrShareAlreadySubmitted) {
+		t.Fatalf("SaveShare() of a duplicate index error = %v, want %v", err, model.ErrShareAlreadySubmitted)
+	}
+
+	shares, err := repo.FindShares(ctx, "pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 2 || shares[0].Index != 1 || shares[1].Index != 2 {
+		t.Fatalf("shares = %+v, want indexes 1 and 2", shares)
+	}
+	if shares[1].Share[1] != 0xaa {
+		t.Fatal("stored share aliases the caller's slice")
+	}
+
+	clear(shares[0].Share)
+	again, err := repo.FindShares(ctx, "pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].Share[1] != 0xcc {
+		t.Fatal("found share aliases the stored share")
+	}
+
+	removed, err := repo.RemoveExpiredShares(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Fatalf("removed = %d, want 1", removed)
+	}
+
+	if shares, _ = repo.FindShares(ctx, "expired"); len(shares) != 0 {
+		t.Fatalf("expired shares = %d, want 0", len(shares))
+	}
+
+	err = repo.RemoveShares(ctx, "pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares, _ = repo.FindShares(ctx, "pending"); len(shares) != 0 {
+		t.Fatalf("pending shares = %d, want 0", len(shares))
+	}
+}
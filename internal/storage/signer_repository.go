@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	SignerTable struct {
+		ID          int
+		CreatedAt   string
+		Name        string
+		PublicKey   string
+		Fingerprint string
+	}
+
+	SignatureNonceTable struct {
+		ID          int
+		CreatedAt   string
+		ExpiredAt   string
+		Fingerprint string
+		Nonce       string
+	}
+
+	SignerRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) SignerRepo() *SignerRepository {
+	return &SignerRepository{
+		logger: s.logger.With("repository", "signer"),
+		db:     s.db,
+	}
+}
+
+func (r *SignerRepository) GetSigner(ctx context.Context, fingerprint string) (model.Signer, error) {
+	const op = "storage.GetSigner"
+	var err error
+
+	query := `
+        SELECT * FROM signers WHERE fingerprint = $1 LIMIT 1
+    `
+
+	var signerTable SignerTable
+	err = r.db.
+		QueryRowContext(ctx, query, fingerprint).
+		Scan(
+			&signerTable.ID,
+			&signerTable.CreatedAt,
+			&signerTable.Name,
+			&signerTable.PublicKey,
+			&signerTable.Fingerprint,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Signer{}, fmt.Errorf("%s: %w", op, model.ErrSignerNotFound)
+		}
+
+		return model.Signer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	signer, err := mapSignerTableToSignerModel(signerTable)
+	if err != nil {
+		return model.Signer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return signer, nil
+}
+
+func (r *SignerRepository) SaveSigner(ctx context.Context, signer model.Signer) (int, error) {
+	const op = "storage.SaveSigner"
+	var err error
+
+	query := `
+        INSERT INTO 
+            signers (created_at, name, public_key, fingerprint) 
+        VALUES 
+            ($1, $2, $3, $4) 
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			signer.CreatedAt.Format(time.RFC3339),
+			signer.Name,
+			signer.PublicKey,
+			signer.Fingerprint,
+		).
+		Scan(&signer.ID)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return 0, fmt.Errorf("%s: %w", op, model.ErrSignerExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return signer.ID, nil
+}
+
+func (r *SignerRepository) SaveNonce(ctx context.Context, nonce model.SignatureNonce) (int, error) {
+	const op = "storage.SaveNonce"
+	var err error
+
+	query := `
+        INSERT INTO
+            signature_nonces (created_at, expired_at, fingerprint, nonce)
+        VALUES
+            ($1, $2, $3, $4)
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			nonce.CreatedAt.UTC().Format(time.RFC3339),
+			nonce.ExpiredAt.UTC().Format(time.RFC3339),
+			nonce.Fingerprint,
+			nonce.Nonce,
+		).
+		Scan(&nonce.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nonce.ID, nil
+}
+
+func (r *SignerRepository) TakeNonce(ctx context.Context, nonce string) (model.SignatureNonce, error) {
+	const op = "storage.TakeNonce"
+	var err error
+
+	query := `
+        DELETE FROM signature_nonces WHERE nonce = $1 RETURNING *
+    `
+
+	var nonceTable SignatureNonceTable
+	err = r.db.
+		QueryRowContext(ctx, query, nonce).
+		Scan(
+			&nonceTable.ID,
+			&nonceTable.CreatedAt,
+			&nonceTable.ExpiredAt,
+			&nonceTable.Fingerprint,
+			&nonceTable.Nonce,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.SignatureNonce{}, fmt.Errorf("%s: %w", op, model.ErrSignatureNonceNotFound)
+		}
+
+		return model.SignatureNonce{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	taken, err := mapSignatureNonceTableToSignatureNonceModel(nonceTable)
+	if err != nil {
+		return model.SignatureNonce{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return taken, nil
+}
+
+func (r *SignerRepository) RemoveExpiredNonces(ctx context.Context, now time.Time) (int, error) {
+	const op = "storage.RemoveExpiredNonces"
+	var err error
+
+	query := `
+        DELETE FROM signature_nonces WHERE expired_at < $1
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(affected), nil
+}
+
+func mapSignerTableToSignerModel(signer SignerTable) (model.Signer, error) {
+	createdAt, err := time.Parse(time.RFC3339, signer.CreatedAt)
+	if err != nil {
+		return model.Signer{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	return model.Signer{
+		ID:          signer.ID,
+		CreatedAt:   createdAt,
+		Name:        signer.Name,
+		PublicKey:   signer.PublicKey,
+		Fingerprint: signer.Fingerprint,
+	}, nil
+}
+
+func mapSignatureNonceTableToSignatureNonceModel(nonce SignatureNonceTable) (model.SignatureNonce, error) {
+	createdAt, err := time.Parse(time.RFC3339, nonce.CreatedAt)
+	if err != nil {
+		return model.SignatureNonce{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, nonce.ExpiredAt)
+	if err != nil {
+		return model.SignatureNonce{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	return model.SignatureNonce{
+		ID:          nonce.ID,
+		CreatedAt:   createdAt,
+		ExpiredAt:   expiredAt,
+		Fingerprint: nonce.Fingerprint,
+		Nonce:       nonce.Nonce,
+	}, nil
+}
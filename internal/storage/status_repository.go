@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	SecretStatusTable struct {
+		ID             int
+		CreatedAt      string
+		ExpiredAt      string
+		StatusKey      string
+		StatusSecret   string
+		AccessKey      string
+		ReplyAccessKey string
+		ReplySecretKey string
+	}
+
+	StatusRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) StatusRepo() *StatusRepository {
+	return &StatusRepository{
+		logger: s.logger.With("repository", "status"),
+		db:     s.db,
+	}
+}
+
+func (r *StatusRepository) GetStatus(ctx context.Context, statusKey string) (model.SecretStatus, error) {
+	const op = "storage.GetStatus"
+	var err error
+
+	query := `
+        SELECT * FROM secret_statuses WHERE status_key = $1 LIMIT 1
+    `
+
+	var statusTable SecretStatusTable
+	err = r.db.
+		QueryRowContext(ctx, query, statusKey).
+		Scan(
+			&statusTable.ID,
+			&statusTable.CreatedAt,
+			&statusTable.ExpiredAt,
+			&statusTable.StatusKey,
+			&statusTable.StatusSecret,
+			&statusTable.AccessKey,
+			&statusTable.ReplyAccessKey,
+			&statusTable.ReplySecretKey,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.SecretStatus{}, fmt.Errorf("%s: %w", op, model.ErrStatusNotFound)
+		}
+
+		return model.SecretStatus{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	status, err := mapSecretStatusTableToSecretStatusModel(statusTable)
+	if err != nil {
+		return model.SecretStatus{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return status, nil
+}
+
+func (r *StatusRepository) SaveStatus(ctx context.Context, status model.SecretStatus) (int, error) {
+	const op = "storage.SaveStatus"
+	var err error
+
+	query := `
+        INSERT INTO 
+            secret_statuses (
+                created_at, expired_at, status_key, status_secret, access_key, reply_access_key, reply_secret_key
+            ) 
+        VALUES 
+            ($1, $2, $3, $4, $5, $6, $7) 
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			status.CreatedAt.Format(time.RFC3339),
+			status.ExpiredAt.Format(time.RFC3339),
+			status.StatusKey,
+			status.StatusSecret,
+			status.AccessKey,
+			status.ReplyAccessKey,
+			status.ReplySecretKey,
+		).
+		Scan(&status.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return status.ID, nil
+}
+
+func (r *StatusRepository) RemoveSecretStatuses(ctx context.Context, accessKey string) error {
+	const op = "storage.RemoveSecretStatuses"
+	var err error
+
+	query := `
+        DELETE FROM secret_statuses WHERE access_key = $1
+    `
+
+	_, err = r.db.
+		ExecContext(ctx, query, accessKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func mapSecretStatusTableToSecretStatusModel(status SecretStatusTable) (model.SecretStatus, error) {
+	createdAt, err := time.Parse(time.RFC3339, status.CreatedAt)
+	if err != nil {
+		return model.SecretStatus{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, status.ExpiredAt)
+	if err != nil {
+		return model.SecretStatus{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	return model.SecretStatus{
+		ID:             status.ID,
+		CreatedAt:      createdAt,
+		ExpiredAt:      expiredAt,
+		StatusKey:      status.StatusKey,
+		StatusSecret:   status.StatusSecret,
+		AccessKey:      status.AccessKey,
+		ReplyAccessKey: status.ReplyAccessKey,
+		ReplySecretKey: status.ReplySecretKey,
+	}, nil
+}
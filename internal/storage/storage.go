@@ -12,6 +12,7 @@ import (
 type Storage struct {
 	logger logging.Logger
 	db     *sql.DB
+	shares *pendingShares
 }
 
 func New(ctx context.Context, logger logging.Logger, database string) (*Storage, error) {
@@ -31,6 +32,7 @@ func New(ctx context.Context, logger logging.Logger, database string) (*Storage,
 	return &Storage{
 		logger: logger.With("module", "storage"),
 		db:     db,
+		shares: newPendingShares(),
 	}, nil
 }
 
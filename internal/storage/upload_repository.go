@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	UploadTable struct {
+		ID           int
+		CreatedAt    string
+		ExpiredAt    string
+		AccessKey    string
+		SigningKey   string
+		Name         string
+		ContentType  string
+		UploadLength int64
+		UploadOffset int64
+		Chunks       string
+	}
+
+	UploadRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) UploadRepo() *UploadRepository {
+	return &UploadRepository{
+		logger: s.logger.With("repository", "upload"),
+		db:     s.db,
+	}
+}
+
+func (r *UploadRepository) GetUpload(ctx context.Context, accessKey string) (model.Upload, error) {
+	const op = "storage.GetUpload"
+	var err error
+
+	query := `
+        SELECT * FROM uploads WHERE access_key = $1 LIMIT 1
+    `
+
+	uploadTable, err := scanUploadTable(r.db.QueryRowContext(ctx, query, accessKey))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Upload{}, fmt.Errorf("%s: %w", op, model.ErrUploadNotFound)
+		}
+
+		return model.Upload{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	upload, err := mapUploadTableToUploadModel(uploadTable)
+	if err != nil {
+		return model.Upload{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return upload, nil
+}
+
+func (r *UploadRepository) SaveUpload(ctx context.Context, upload model.Upload) (int, error) {
+	const op = "storage.SaveUpload"
+	var err error
+
+	query := `
+        INSERT INTO
+            uploads (created_at, expired_at, access_key, signing_key, name, content_type, upload_length)
+        VALUES
+            ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			upload.CreatedAt.UTC().Format(time.RFC3339),
+			upload.ExpiredAt.UTC().Format(time.RFC3339),
+			upload.AccessKey,
+			upload.SigningKey,
+			upload.Name,
+			upload.ContentType,
+			upload.Length,
+		).
+		Scan(&upload.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return upload.ID, nil
+}
+
+func (r *UploadRepository) AppendUploadChunk(
+	ctx context.Context,
+	upload model.Upload,
+	offset int64,
+	blobKey string,
+) error {
+	const op = "storage.AppendUploadChunk"
+	var err error
+
+	chunks, err := json.Marshal(append(upload.Chunks, blobKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `
+        UPDATE uploads SET upload_offset = $1, chunks = $2 WHERE access_key = $3 AND upload_offset = $4
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, offset, string(chunks), upload.AccessKey, upload.Offset)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, model.ErrUploadOffsetMismatch)
+	}
+
+	return nil
+}
+
+func (r *UploadRepository) RemoveUpload(ctx context.Context, accessKey string) error {
+	const op = "storage.RemoveUpload"
+	var err error
+
+	query := `
+        DELETE FROM uploads WHERE access_key = $1
+    `
+
+	_, err = r.db.
+		ExecContext(ctx, query, accessKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *UploadRepository) FindExpiredUploads(ctx context.Context, now time.Time) ([]model.Upload, error) {
+	const op = "storage.FindExpiredUploads"
+	var err error
+
+	query := `
+        SELECT * FROM uploads WHERE expired_at < $1
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	uploads := make([]model.Upload, 0)
+	for rows.Next() {
+		uploadTable, err := scanUploadTable(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		upload, err := mapUploadTableToUploadModel(uploadTable)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		uploads = append(uploads, upload)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return uploads, nil
+}
+
+func scanUploadTable(row scanner) (UploadTable, error) {
+	var uploadTable UploadTable
+	err := row.Scan(
+		&uploadTable.ID,
+		&uploadTable.CreatedAt,
+		&uploadTable.ExpiredAt,
+		&uploadTable.AccessKey,
+		&uploadTable.SigningKey,
+		&uploadTable.Name,
+		&uploadTable.ContentType,
+		&uploadTable.UploadLength,
+		&uploadTable.UploadOffset,
+		&uploadTable.Chunks,
+	)
+
+	return uploadTable, err
+}
+
+func mapUploadTableToUploadModel(upload UploadTable) (model.Upload, error) {
+	createdAt, err := time.Parse(time.RFC3339, upload.CreatedAt)
+	if err != nil {
+		return model.Upload{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, upload.ExpiredAt)
+	if err != nil {
+		return model.Upload{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	var chunks []string
+	err = json.Unmarshal([]byte(upload.Chunks), &chunks)
+	if err != nil {
+		return model.Upload{}, fmt.Errorf("parse chunks: %w", err)
+	}
+
+	return model.Upload{
+		ID:          upload.ID,
+		CreatedAt:   createdAt,
+		ExpiredAt:   expiredAt,
+		AccessKey:   upload.AccessKey,
+		SigningKey:  upload.SigningKey,
+		Name:        upload.Name,
+		ContentType: upload.ContentType,
+		Length:      upload.UploadLength,
+		Offset:      upload.UploadOffset,
+		Chunks:      chunks,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/logging"
+)
+
+type (
+	WebAuthnSessionTable struct {
+		ID         int
+		CreatedAt  string
+		ExpiredAt  string
+		SessionKey string
+		AccessKey  string
+		Challenge  string
+	}
+
+	WebAuthnRepository struct {
+		logger logging.Logger
+		db     *sql.DB
+	}
+)
+
+func (s *Storage) WebAuthnRepo() *WebAuthnRepository {
+	return &WebAuthnRepository{
+		logger: s.logger.With("repository", "webauthn"),
+		db:     s.db,
+	}
+}
+
+func (r *WebAuthnRepository) TakeSession(ctx context.Context, sessionKey string) (model.WebAuthnSession, error) {
+	const op = "storage.TakeSession"
+	var err error
+
+	query := `
+        DELETE FROM webauthn_sessions WHERE session_key = $1 RETURNING *
+    `
+
+	var sessionTable WebAuthnSessionTable
+	err = r.db.
+		QueryRowContext(ctx, query, sessionKey).
+		Scan(
+			&sessionTable.ID,
+			&sessionTable.CreatedAt,
+			&sessionTable.ExpiredAt,
+			&sessionTable.SessionKey,
+			&sessionTable.AccessKey,
+			&sessionTable.Challenge,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.WebAuthnSession{}, fmt.Errorf("%s: %w", op, model.ErrWebAuthnSessionNotFound)
+		}
+
+		return model.WebAuthnSession{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	session, err := mapWebAuthnSessionTableToPakeSessionModel(sessionTable)
+	if err != nil {
+		return model.WebAuthnSession{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session, nil
+}
+
+func (r *WebAuthnRepository) SaveSession(ctx context.Context, session model.WebAuthnSession) (int, error) {
+	const op = "storage.SaveSession"
+	var err error
+
+	query := `
+        INSERT INTO
+            webauthn_sessions (created_at, expired_at, session_key, access_key, challenge)
+        VALUES
+            ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+
+	err = r.db.
+		QueryRowContext(
+			ctx, query,
+			session.CreatedAt.UTC().Format(time.RFC3339),
+			session.ExpiredAt.UTC().Format(time.RFC3339),
+			session.SessionKey,
+			session.AccessKey,
+			hex.EncodeToString(session.Challenge),
+		).
+		Scan(&session.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session.ID, nil
+}
+
+func (r *WebAuthnRepository) RemoveExpiredSessions(ctx context.Context, now time.Time) (int, error) {
+	const op = "storage.RemoveExpiredSessions"
+	var err error
+
+	query := `
+        DELETE FROM webauthn_sessions WHERE expired_at < $1
+    `
+
+	res, err := r.db.
+		ExecContext(ctx, query, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(affected), nil
+}
+
+func mapWebAuthnSessionTableToPakeSessionModel(session WebAuthnSessionTable) (model.WebAuthnSession, error) {
+	createdAt, err := time.Parse(time.RFC3339, session.CreatedAt)
+	if err != nil {
+		return model.WebAuthnSession{}, fmt.Errorf("parse created at: %w", err)
+	}
+
+	expiredAt, err := time.Parse(time.RFC3339, session.ExpiredAt)
+	if err != nil {
+		return model.WebAuthnSession{}, fmt.Errorf("parse expired at: %w", err)
+	}
+
+	challenge, err := hex.DecodeString(session.Challenge)
+	if err != nil {
+		return model.WebAuthnSession{}, fmt.Errorf("parse challenge: %w", err)
+	}
+
+	return model.WebAuthnSession{
+		ID:         session.ID,
+		CreatedAt:  createdAt,
+		ExpiredAt:  expiredAt,
+		SessionKey: session.SessionKey,
+		AccessKey:  session.AccessKey,
+		Challenge:  challenge,
+	}, nil
+}
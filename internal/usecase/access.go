@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/realip"
+)
+
+func checkClientIP(allowedCIDRs []string, clientIP netip.Addr) error {
+	const op = "checkClientIP"
+
+	if len(allowedCIDRs) == 0 {
+		return nil
+	}
+
+	prefixes, err := realip.ParsePrefixes(allowedCIDRs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !clientIP.IsValid() || !realip.Contains(prefixes, clientIP) {
+		return fmt.Errorf("%s: %w: %w: client %s", op, model.ErrSecretNotFound, model.ErrAccessDenied, clientIP)
+	}
+
+	return nil
+}
+
+func checkClientCountry(locator geoip.Locator, allowed, denied []string, clientIP netip.Addr) (string, error) {
+	const op = "checkClientCountry"
+
+	if locator == nil {
+		if len(allowed) > 0 || len(denied) > 0 {
+			return "", fmt.Errorf("%s: %w: %w: geoip is not configured", op, model.ErrSecretNotFound, model.ErrAccessDenied)
+		}
+
+		return "", nil
+	}
+
+	country, err := locator.Country(clientIP)
+	if err != nil {
+		if len(allowed) == 0 && len(denied) == 0 {
+			return "", nil
+		}
+
+		if !errors.Is(err, geoip.ErrUnknownCountry) {
+			return "", fmt.Errorf("%s: %w: %w: %w", op, model.ErrSecretNotFound, model.ErrAccessDenied, err)
+		}
+	}
+
+	if len(allowed) > 0 && !slices.Contains(allowed, country) {
+		return country, fmt.Errorf("%s: %w: %w: country %q", op, model.ErrSecretNotFound, model.ErrAccessDenied, country)
+	}
+
+	if len(denied) > 0 && slices.Contains(denied, country) {
+		return country, fmt.Errorf("%s: %w: %w: country %q", op, model.ErrSecretNotFound, model.ErrAccessDenied, country)
+	}
+
+	return country, nil
+}
+
+func normalizeCountries(countries []string) ([]string, error) {
+	normalized := make([]string, 0, len(countries))
+	for _, country := range countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return nil, fmt.Errorf("invalid country code %q", country)
+		}
+
+		normalized = append(normalized, country)
+	}
+
+	return normalized, nil
+}
+
+func readDetails(country string) string {
+	if country == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("country %s", country)
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+type stubLocator struct {
+	country string
+	err     error
+}
+
+func (l stubLocator) Country(netip.Addr) (string, error) {
+	return l.country, l.err
+}
+
+func TestCheckClientCountry(t *testing.T) {
+	clientIP := netip.MustParseAddr("203.0.113.7")
+	lookupErr := errors.New("database is reloading")
+
+	tests := []struct {
+		name        string
+		locator     geoip.Locator
+		allowed     []string
+		denied      []string
+		wantCountry string
+		wantDenied  bool
+	}{
+		{name: "no rules", locator: stubLocator{country: "DE"}, wantCountry: "DE"},
+		{name: "no rules and lookup error", locator: stubLocator{err: lookupErr}},
+		{name: "no rules and no locator"},
+		{name: "allowed", locator: stubLocator{country: "DE"}, allowed: []string{"DE"}, wantCountry: "DE"},
+		{name: "not allowed", locator: stubLocator{country: "FR"}, allowed: []string{"DE"}, wantCountry: "FR", wantDenied: true},
+		{name: "denied", locator: stubLocator{country: "FR"}, denied: []string{"FR"}, wantCountry: "FR", wantDenied: true},
+		{name: "rules and lookup error", locator: stubLocator{err: lookupErr}, denied: []string{"FR"}, wantDenied: true},
+		{name: "rules and unknown country", locator: stubLocator{err: geoip.ErrUnknownCountry}, allowed: []string{"DE"}, wantDenied: true},
+		{name: "rules and no locator", allowed: []string{"DE"}, wantDenied: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			country, err := checkClientCountry(tt.locator, tt.allowed, tt.denied, clientIP)
+
+			if tt.wantDenied {
+				if !errors.Is(err, model.ErrSecretNotFound) || !errors.Is(err, model.ErrAccessDenied) {
+					t.Fatalf("expected a not found access denial, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if country != tt.wantCountry {
+				t.Fatalf("country = %q, want %q", country, tt.wantCountry)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+)
+
+const defaultAttachmentContentType = "application/octet-stream"
+
+type AttachmentDTO struct {
+	Name        string
+	ContentType string
+	Data        io.Reader
+}
+
+type AttachmentSource interface {
+	Next() (AttachmentDTO, error)
+}
+
+func storeAttachments(
+	ctx context.Context,
+	blobs blobstore.Store,
+	streamer cryptor.StreamEncryptor,
+	accessKey string,
+	key []byte,
+	source AttachmentSource,
+	dtos []AttachmentDTO,
+) ([]model.Attachment, error) {
+	const op = "storeAttachments"
+
+	attachments := make([]model.Attachment, 0, len(dtos))
+	for {
+		dto, err := nextAttachment(source, &dtos)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = deleteAttachments(ctx, blobs, attachments)
+			return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		attachment := model.Attachment{
+			ID:          randstr.SecureGen(16),
+			Name:        dto.Name,
+			ContentType: dto.ContentType,
+			Streamed:    true,
+		}
+		attachment.BlobKey = fmt.Sprintf("%s-%s", accessKey, attachment.ID)
+
+		if attachment.ContentType == "" {
+			attachment.ContentType = defaultAttachmentContentType
+		}
+
+		size, err := putEncryptedBlob(ctx, blobs, streamer, attachment.BlobKey, key, dto.Data)
+		if err != nil {
+			_ = deleteAttachments(ctx, blobs, attachments)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		attachment.Size = size
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+func nextAttachment(source AttachmentSource, dtos *[]AttachmentDTO) (AttachmentDTO, error) {
+	if source != nil {
+		dto, err := source.Next()
+		if !errors.Is(err, io.EOF) {
+			return dto, err
+		}
+	}
+
+	if len(*dtos) == 0 {
+		return AttachmentDTO{}, io.EOF
+	}
+
+	dto := (*dtos)[0]
+	*dtos = (*dtos)[1:]
+
+	return dto, nil
+}
+
+func putEncryptedBlob(
+	ctx context.Context,
+	blobs blobstore.Store,
+	streamer cryptor.StreamEncryptor,
+	blobKey string,
+	key []byte,
+	data io.Reader,
+) (int64, error) {
+	pr, pw := io.Pipe()
+
+	var (
+		size    int64
+		copyErr error
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		w, err := streamer.EncryptWriter(pw, key)
+		if err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+
+		size, copyErr = io.Copy(w, data)
+		if copyErr == nil {
+			err = w.Close()
+		}
+
+		_ = pw.CloseWithError(errors.Join(copyErr, err))
+	}()
+
+	err := blobs.Put(ctx, blobKey, pr, -1)
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	<-done
+
+	if copyErr != nil {
+		_ = blobs.Delete(ctx, blobKey)
+		return 0, copyErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func offerAttachments(
+	ctx context.Context,
+	downloadRepo *storage.DownloadRepository,
+	encoder cryptor.Encoder,
+	key []byte,
+	ttl time.Duration,
+	attachments []model.Attachment,
+) ([]model.Attachment, error) {
+	const op = "offerAttachments"
+	now := time.Now()
+
+	offered := make([]model.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		downloadKey := randstr.SecureGen(16)
+
+		encodedDownloadKey, err := encoder.Encode(bytes.Join(
+			[][]byte{[]byte(downloadKey), key},
+			[]byte("$"),
+		))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = downloadRepo.SaveDownload(ctx, model.AttachmentDownload{
+			CreatedAt:   now,
+			ExpiredAt:   now.Add(ttl),
+			DownloadKey: downloadKey,
+			BlobKey:     attachment.BlobKey,
+			Streamed:    attachment.Streamed,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		attachment.DownloadKey = string(encodedDownloadKey)
+		offered = append(offered, attachment)
+	}
+
+	return offered, nil
+}
+
+type DownloadAttachmentDTO struct {
+	DownloadKey string
+}
+
+type AttachmentContentDTO struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Data        io.ReadCloser
+}
+
+func DownloadAttachment(
+	downloadRepo *storage.DownloadRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	streamer cryptor.StreamEncryptor,
+	blobs blobstore.Store,
+) UseCaseFunc[DownloadAttachmentDTO, AttachmentContentDTO] {
+	return func(ctx context.Context, dto DownloadAttachmentDTO) (AttachmentContentDTO, error) {
+		const op = "usecase.DownloadAttachment"
+		var err error
+		now := time.Now()
+
+		decodedDownloadKey, err := encoder.Decode([]byte(dto.DownloadKey))
+		if err != nil {
+			return AttachmentContentDTO{}, fmt.Errorf("%s: %w: invalid download key: %w", op, model.ErrAttachmentNotFound, err)
+		}
+		defer cryptor.Wipe(decodedDownloadKey)
+
+		downloadKey, key, ok := bytes.Cut(decodedDownloadKey, []byte("$"))
+		if !ok || len(downloadKey) == 0 || len(key) == 0 {
+			return AttachmentContentDTO{}, fmt.Errorf("%s: %w: invalid download key", op, model.ErrAttachmentNotFound)
+		}
+
+		download, err := downloadRepo.GetDownload(ctx, string(downloadKey))
+		if err != nil {
+			return AttachmentContentDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if !now.Before(download.ExpiredAt) {
+			return AttachmentContentDTO{}, fmt.Errorf("%s: %w", op, model.ErrAttachmentNotFound)
+		}
+
+		data, err := openEncryptedBlob(ctx, blobs, encryptor, streamer, key, download)
+		if err != nil {
+			return AttachmentContentDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		removed, err := downloadRepo.RemoveDownload(ctx, download.DownloadKey)
+		if err != nil || !removed {
+			_ = data.Close()
+			return AttachmentContentDTO{}, fmt.Errorf("%s: %w", op, errors.Join(model.ErrAttachmentNotFound, err))
+		}
+
+		return AttachmentContentDTO{
+			Name:        download.Name,
+			ContentType: download.ContentType,
+			Size:        download.Size,
+			Data: &consumedBlob{
+				Reader: data,
+				close: func() error {
+					err := data.Close()
+					return errors.Join(err, blobs.Delete(context.WithoutCancel(ctx), download.BlobKey))
+				},
+			},
+		}, nil
+	}
+}
+
+func PurgeExpiredDownloads(downloadRepo *storage.DownloadRepository, blobs blobstore.Store) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredDownloads"
+
+		downloads, err := downloadRepo.TakeExpiredDownloads(ctx, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		errs := make([]error, 0)
+		for _, download := range downloads {
+			err = blobs.Delete(ctx, download.BlobKey)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(errs) > 0 {
+			return len(downloads), fmt.Errorf("%s: %w", op, errors.Join(errs...))
+		}
+
+		return len(downloads), nil
+	}
+}
+
+type consumedBlob struct {
+	io.Reader
+	close func() error
+}
+
+func (b *consumedBlob) Close() error {
+	return b.close()
+}
+
+func openEncryptedBlob(
+	ctx context.Context,
+	blobs blobstore.Store,
+	encryptor cryptor.Encryptor,
+	streamer cryptor.StreamEncryptor,
+	key []byte,
+	download model.AttachmentDownload,
+) (io.ReadCloser, error) {
+	blob, err := blobs.Get(ctx, download.BlobKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !download.Streamed {
+		defer func() { _ = blob.Close() }()
+
+		encryptedData, err := io.ReadAll(blob)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := encryptor.Decrypt(encryptedData, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", model.ErrAttachmentNotFound, err)
+		}
+
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	r, err := streamer.DecryptReader(blob, key)
+	if err != nil {
+		_ = blob.Close()
+		return nil, fmt.Errorf("%w: %w", model.ErrAttachmentNotFound, err)
+	}
+
+	data := bufio.NewReader(r)
+	_, err = data.Peek(1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		_ = blob.Close()
+
+		if errors.Is(err, cryptor.ErrInvalidData) {
+			return nil, fmt.Errorf("%w: %w", model.ErrAttachmentNotFound, err)
+		}
+
+		return nil, err
+	}
+
+	return &consumedBlob{Reader: data, close: blob.Close}, nil
+}
+
+func deleteAttachments(ctx context.Context, blobs blobstore.Store, attachments []model.Attachment) error {
+	const op = "deleteAttachments"
+
+	errs := make([]error, 0)
+	for _, attachment := range attachments {
+		err := blobs.Delete(ctx, attachment.BlobKey)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %w", op, errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/notify"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+)
+
+type CheckInSecretDTO struct {
+	CheckInToken string
+}
+
+func CheckInSecret(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	hasher passhash.Hasher,
+	encoder cryptor.Encoder,
+) UseCaseFunc[CheckInSecretDTO, time.Time] {
+	return func(ctx context.Context, dto CheckInSecretDTO) (time.Time, error) {
+		const op = "usecase.CheckInSecret"
+		var err error
+		now := time.Now()
+
+		decodedToken, err := encoder.Decode([]byte(dto.CheckInToken))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w: invalid check-in token: %w", op, model.ErrSecretNotFound, err)
+		}
+
+		tokenParts := bytes.Split(decodedToken, []byte("$"))
+		if len(tokenParts) != 2 {
+			return time.Time{}, fmt.Errorf("%s: %w: invalid check-in token", op, model.ErrSecretNotFound)
+		}
+
+		accessKey := tokenParts[0]
+		tokenKey := tokenParts[1]
+
+		secret, err := secretRepo.GetSecret(ctx, string(accessKey))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if secret.CheckInInterval == 0 {
+			return time.Time{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		if secret.ExpiredAt.Unix() < now.Unix() && secret.ExpiredAt.Unix() > secret.CreatedAt.Unix() {
+			return time.Time{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		err = hasher.Compare(tokenKey, secret.CheckInToken)
+		if err != nil {
+			if errors.Is(err, passhash.ErrWrongPassword) {
+				return time.Time{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+			}
+
+			return time.Time{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if secret.Released || !now.Before(secret.AvailableFrom) {
+			return time.Time{}, fmt.Errorf("%s: %w", op, model.ErrSecretReleased)
+		}
+
+		deadline := now.Add(secret.CheckInInterval)
+		if secret.ExpiredAt.Unix() > secret.CreatedAt.Unix() && !deadline.Before(secret.ExpiredAt) {
+			deadline = secret.ExpiredAt
+		}
+
+		err = secretRepo.CheckInSecret(ctx, secret.AccessKey, deadline)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+			CreatedAt: now,
+			AccessKey: secret.AccessKey,
+			Kind:      model.SecretCheckedIn,
+			Details:   fmt.Sprintf("next deadline %s", deadline.Format(time.RFC3339)),
+		})
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return deadline, nil
+	}
+}
+
+func ReleaseMissedCheckInSecrets(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	notifier notify.Notifier,
+) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.ReleaseMissedCheckInSecrets"
+		var err error
+		now := time.Now()
+
+		secrets, err := secretRepo.FindUnreleasedCheckInSecrets(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var (
+			released   = 0
+			notifyErrs = make([]error, 0)
+		)
+		for _, secret := range secrets {
+			if now.Before(secret.AvailableFrom) {
+				continue
+			}
+
+			if secret.ExpiredAt.Unix() <= now.Unix() && secret.ExpiredAt.Unix() > secret.CreatedAt.Unix() {
+				continue
+			}
+
+			ok, err := secretRepo.ReleaseSecret(ctx, secret.AccessKey)
+			if err != nil {
+				return released, fmt.Errorf("%s: %w", op, err)
+			}
+
+			if !ok {
+				continue
+			}
+
+			released++
+
+			_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+				CreatedAt: now,
+				AccessKey: secret.AccessKey,
+				Kind:      model.SecretReleased,
+				Details:   "missed check-in",
+			})
+			if err != nil {
+				return released, fmt.Errorf("%s: %w", op, err)
+			}
+
+			err = notifier.Notify(ctx, notify.Notification{
+				Event:     string(model.SecretReleased),
+				SecretID:  secret.ID,
+				CreatedAt: now,
+				Message:   "sender missed the check-in deadline, the secret is now readable",
+			})
+			if err != nil {
+				notifyErrs = append(notifyErrs, err)
+			}
+		}
+
+		if len(notifyErrs) > 0 {
+			return released, fmt.Errorf("%s: %w", op, errors.Join(notifyErrs...))
+		}
+
+		return released, nil
+	}
+}
+
+func generateCheckInToken(hasher passhash.Hasher, encoder cryptor.Encoder, accessKey []byte) ([]byte, []byte, error) {
+	const op = "generateCheckInToken"
+	var err error
+
+	tokenKey := randstr.SecureGen(16)
+
+	token, err := encoder.Encode(bytes.Join(
+		[][]byte{accessKey, []byte(tokenKey)},
+		[]byte("$"),
+	))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	hashedToken, err := hasher.Generate([]byte(tokenKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, []byte(hashedToken), nil
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/notify"
+)
+
+type stubNotifier struct {
+	notifications []notify.Notification
+}
+
+func (n *stubNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func saveCheckInSecret(t *testing.T, env *testEnv, accessKey string, createdAt, expiredAt, availableFrom time.Time) string {
+	t.Helper()
+
+	token, hashedToken, err := generateCheckInToken(env.hasher, env.encoder, []byte(accessKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.store.SecretRepo().SaveSecret(context.Background(), model.Secret{
+		CreatedAt:       createdAt,
+		ExpiredAt:       expiredAt,
+		AvailableFrom:   availableFrom,
+		AccessKey:       accessKey,
+		SigningKey:      "key",
+		PayloadType:     model.PayloadText,
+		Message:         []byte("message"),
+		CheckInInterval: 24 * time.Hour,
+		CheckInToken:    string(hashedToken),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(token)
+}
+
+func TestCheckInSecretExtendsDeadline(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	token := saveCheckInSecret(t, env, "access", now, now.Add(72*time.Hour), now.Add(time.Hour))
+
+	deadline, err := CheckInSecret(env.store.SecretRepo(), env.store.EventRepo(), env.hasher, env.encoder)(
+		context.Background(), CheckInSecretDTO{CheckInToken: token},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := now.Add(24 * time.Hour); deadline.Before(want) || deadline.After(want.Add(time.Minute)) {
+		t.Fatalf("deadline = %s, want about %s", deadline, want)
+	}
+}
+
+func TestCheckInSecretClampsDeadlineToExpiry(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now().Truncate(time.Second)
+	expiredAt := now.Add(6 * time.Hour)
+	token := saveCheckInSecret(t, env, "access", now.Add(-48*time.Hour), expiredAt, now.Add(time.Hour))
+
+	deadline, err := CheckInSecret(env.store.SecretRepo(), env.store.EventRepo(), env.hasher, env.encoder)(
+		context.Background(), CheckInSecretDTO{CheckInToken: token},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deadline.Equal(expiredAt) {
+		t.Fatalf("deadline = %s, want %s", deadline, expiredAt)
+	}
+
+	secret, err := env.store.SecretRepo().GetSecret(context.Background(), "access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.AvailableFrom.After(secret.ExpiredAt) {
+		t.Fatalf("available from %s is after expiry %s", secret.AvailableFrom, secret.ExpiredAt)
+	}
+}
+
+func TestCheckInSecretRejectsExpiredSecret(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	token := saveCheckInSecret(t, env, "access", now.Add(-48*time.Hour), now.Add(-time.Hour), now.Add(time.Hour))
+
+	_, err := CheckInSecret(env.store.SecretRepo(), env.store.EventRepo(), env.hasher, env.encoder)(
+		context.Background(), CheckInSecretDTO{CheckInToken: token},
+	)
+	if err == nil {
+		t.Fatal("CheckInSecret() accepted an expired secret")
+	}
+}
+
+func TestCheckInSecretRejectsMalformedToken(t *testing.T) {
+	env := newTestEnv(t)
+
+	noSeparator, err := env.encoder.Encode([]byte("access"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"not encoded":  "!!!",
+		"no separator": string(noSeparator),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := CheckInSecret(env.store.SecretRepo(), env.store.EventRepo(), env.hasher, env.encoder)(
+				context.Background(), CheckInSecretDTO{CheckInToken: token},
+			)
+			if !errors.Is(err, model.ErrSecretNotFound) {
+				t.Fatalf("expected ErrSecretNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReleaseMissedCheckInSecretsSkipsExpired(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+	saveCheckInSecret(t, env, "missed", now.Add(-48*time.Hour), now.Add(time.Hour), now.Add(-time.Minute))
+	saveCheckInSecret(t, env, "expired", now.Add(-48*time.Hour), now.Add(-time.Minute), now.Add(-time.Minute))
+
+	notifier := &stubNotifier{}
+	released, err := ReleaseMissedCheckInSecrets(env.store.SecretRepo(), env.store.EventRepo(), notifier)(
+		context.Background(), struct{}{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 1 || len(notifier.notifications) != 1 {
+		t.Fatalf("released %d secrets with %d notifications, want 1 and 1", released, len(notifier.notifications))
+	}
+
+	for accessKey, want := range map[string]bool{"missed": true, "expired": false} {
+		secret, err := env.store.SecretRepo().GetSecret(context.Background(), accessKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret.Released != want {
+			t.Fatalf("%s released = %t, want %t", accessKey, secret.Released, want)
+		}
+	}
+}
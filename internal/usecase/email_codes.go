@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/mailer"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+	"github.com/protomem/secrets-keeper/internal/storage"
+)
+
+const emailCodeDigits = 6
+
+type EmailCodeOptions struct {
+	TTL            time.Duration
+	MaxAttempts    int
+	ResendInterval time.Duration
+	Lockout        LockoutOptions
+}
+
+type SendEmailCodeDTO struct {
+	SecretKey string
+	ClientIP  netip.Addr
+}
+
+type SentEmailCodeDTO struct {
+	Email     string
+	ExpiredAt time.Time
+}
+
+type VerifyEmailCodeDTO struct {
+	SecretKey string
+	Code      string
+	ClientIP  netip.Addr
+}
+
+func SendEmailCode(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	codeRepo *storage.EmailCodeRepository,
+	hasher passhash.Hasher,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	blobs blobstore.Store,
+	sender mailer.Mailer,
+	locator geoip.Locator,
+	opts EmailCodeOptions,
+) UseCaseFunc[SendEmailCodeDTO, SentEmailCodeDTO] {
+	return func(ctx context.Context, dto SendEmailCodeDTO) (SentEmailCodeDTO, error) {
+		const op = "usecase.SendEmailCode"
+		var err error
+		now := time.Now()
+
+		secret, _, _, err := findGatedSecret(
+			ctx, secretRepo, eventRepo, encoder, encryptor, blobs, locator, opts.Lockout, dto.SecretKey, dto.ClientIP, hasRecipientEmail,
+		)
+		if err != nil {
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if sender == nil {
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w: email is not configured", op, model.ErrSecretNotFound)
+		}
+
+		existing, err := codeRepo.GetCode(ctx, secret.AccessKey)
+		if err != nil && !errors.Is(err, model.ErrEmailCodeNotFound) {
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err == nil && now.Before(existing.CreatedAt.Add(opts.ResendInterval)) {
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w", op, model.ErrEmailCodeRecentlySent)
+		}
+
+		code, err := generateEmailCode()
+		if err != nil {
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		codeHash, err := hasher.Generate([]byte(code))
+		if err != nil {
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		emailCode := model.EmailCode{
+			CreatedAt: now,
+			ExpiredAt: now.Add(opts.TTL),
+			AccessKey: secret.AccessKey,
+			CodeHash:  codeHash,
+		}
+
+		_, err = codeRepo.SaveCode(ctx, emailCode)
+		if err != nil {
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = sender.Send(ctx, mailer.Mail{
+			To:      secret.RecipientEmail,
+			Subject: "Your one-time code",
+			Body: fmt.Sprintf(
+				"Your one-time code is %s.\n\nIt expires at %s. If you did not request it, ignore this email.\n",
+				code, emailCode.ExpiredAt.UTC().Format("15:04 MST"),
+			),
+		})
+		if err != nil {
+			_ = codeRepo.RemoveCode(ctx, secret.AccessKey)
+			return SentEmailCodeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return SentEmailCodeDTO{
+			Email:     maskEmail(secret.RecipientEmail),
+			ExpiredAt: emailCode.ExpiredAt,
+		}, nil
+	}
+}
+
+func VerifyEmailCode(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	signerRepo *storage.SignerRepository,
+	downloadRepo *storage.DownloadRepository,
+	codeRepo *storage.EmailCodeRepository,
+	hasher passhash.Hasher,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	sealer *envelope.Envelope,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	opts EmailCodeOptions,
+	downloadTTL time.Duration,
+) UseCaseFunc[VerifyEmailCodeDTO, model.Secret] {
+	return func(ctx context.Context, dto VerifyEmailCodeDTO) (model.Secret, error) {
+		const op = "usecase.VerifyEmailCode"
+		var err error
+		now := time.Now()
+
+		secret, signingKey, country, err := findGatedSecret(
+			ctx, secretRepo, eventRepo, encoder, encryptor, blobs, locator, opts.Lockout, dto.SecretKey, dto.ClientIP, hasRecipientEmail,
+		)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		code, err := codeRepo.GetCode(ctx, secret.AccessKey)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if !now.Before(code.ExpiredAt) {
+			err = codeRepo.RemoveCode(ctx, secret.AccessKey)
+			if err != nil {
+				return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrEmailCodeNotFound)
+		}
+
+		attempts, err := codeRepo.ReserveAttempt(ctx, secret.AccessKey, opts.MaxAttempts)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = waitAttemptDelay(ctx, opts.Lockout, secret.FailedAttempts)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = hasher.Compare([]byte(strings.TrimSpace(dto.Code)), code.CodeHash)
+		if err != nil {
+			if !errors.Is(err, passhash.ErrWrongPassword) {
+				return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			if opts.MaxAttempts > 0 && attempts >= opts.MaxAttempts {
+				err = codeRepo.RemoveCode(ctx, secret.AccessKey)
+				if err != nil {
+					return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+				}
+			}
+
+			err = registerFailedAttempt(ctx, secretRepo, eventRepo, blobs, opts.Lockout, secret)
+			if err != nil {
+				return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		err = checkSecretAvailable(secret, now)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = codeRepo.RemoveCode(ctx, secret.AccessKey)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		key, err := lockSigningKey(signingKey, secret.SigningKey)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer key.Destroy()
+
+		secret, err = revealSecret(
+			ctx, secretRepo, eventRepo, signerRepo, downloadRepo, encoder, encryptor, sealer, downloadTTL,
+			secret, key.Bytes(), country,
+		)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return secret, nil
+	}
+}
+
+func PurgeExpiredEmailCodes(codeRepo *storage.EmailCodeRepository) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredEmailCodes"
+
+		purged, err := codeRepo.RemoveExpiredCodes(ctx, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return purged, nil
+	}
+}
+
+func normalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", err
+	}
+
+	local, domain, _ := strings.Cut(addr.Address, "@")
+	return local + "@" + strings.ToLower(domain), nil
+}
+
+func maskEmail(email string) string {
+	local, domain, _ := strings.Cut(email, "@")
+	if len(local) <= 1 {
+		return "*@" + domain
+	}
+
+	return local[:1] + strings.Repeat("*", len(local)-1) + "@" + domain
+}
+
+func generateEmailCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", emailCodeDigits, n.Int64()), nil
+}
+
+func hasRecipientEmail(secret model.Secret) bool {
+	return secret.RecipientEmail != ""
+}
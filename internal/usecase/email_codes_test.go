@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/mailer/smtp"
+	"github.com/protomem/secrets-keeper/internal/mailer/smtp/smtptest"
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+var emailCodePattern = regexp.MustCompile(`code is (\d{6})\.`)
+
+type emailCodeTest struct {
+	env       *testEnv
+	server    *smtptest.Server
+	secretKey string
+	send      UseCaseFunc[SendEmailCodeDTO, SentEmailCodeDTO]
+	verify    UseCaseFunc[VerifyEmailCodeDTO, model.Secret]
+}
+
+func newEmailCodeTest(t *testing.T, opts EmailCodeOptions) *emailCodeTest {
+	t.Helper()
+
+	env := newTestEnv(t)
+
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	sender, err := smtp.NewMailer(smtp.Options{
+		Addr:    server.Addr,
+		From:    "noreply@example.com",
+		TLS:     smtp.TLSNone,
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := env.createSecret(t, sender, CreateSecretDTO{
+		Message:        []byte("email protected"),
+		TTL:            1,
+		RecipientEmail: "alice@Example.com",
+	})
+
+	return &emailCodeTest{
+		env:       env,
+		server:    server,
+		secretKey: created.SecretKey,
+		send: SendEmailCode(
+			env.store.SecretRepo(),
+			env.store.EventRepo(),
+			env.store.EmailCodeRepo(),
+			env.hasher,
+			env.encoder,
+			env.encryptor,
+			env.blobs,
+			sender,
+			nil,
+			opts,
+		),
+		verify: VerifyEmailCode(
+			env.store.SecretRepo(),
+			env.store.EventRepo(),
+			env.store.SignerRepo(),
+			env.store.DownloadRepo(),
+			env.store.EmailCodeRepo(),
+			env.hasher,
+			env.encoder,
+			env.encryptor,
+			env.sealer,
+			env.blobs,
+			nil,
+			opts,
+			time.Minute,
+		),
+	}
+}
+
+func (e *emailCodeTest) sendCode(t *testing.T) string {
+	t.Helper()
+
+	sent, err := e.send(context.Background(), SendEmailCodeDTO{SecretKey: e.secretKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Email != "a****@example.com" {
+		t.Fatalf("masked email = %q, want %q", sent.Email, "a****@example.com")
+	}
+
+	messages := e.server.Messages()
+	if len(messages) == 0 {
+		t.Fatal("no email was sent")
+	}
+
+	msg := messages[len(messages)-1]
+	if len(msg.To) != 1 || msg.To[0] != "alice@example.com" {
+		t.Fatalf("to = %q, want [alice@example.com]", msg.To)
+	}
+
+	match := emailCodePattern.FindStringSubmatch(msg.Data)
+	if match == nil {
+		t.Fatalf("no code in email:\n%s", msg.Data)
+	}
+
+	return match[1]
+}
+
+func (e *emailCodeTest) verifyCode(code string) (model.Secret, error) {
+	return e.verify(context.Background(), VerifyEmailCodeDTO{SecretKey: e.secretKey, Code: code})
+}
+
+func wrongEmailCode(t *testing.T, code string) string {
+	t.Helper()
+
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fmt.Sprintf("%06d", (n+1)%1_000_000)
+}
+
+func TestEmailCodeRevealsSecret(t *testing.T) {
+	e := newEmailCodeTest(t, EmailCodeOptions{TTL: time.Minute, MaxAttempts: 3})
+
+	code := e.sendCode(t)
+
+	secret, err := e.verifyCode(" " + code + " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Message) != "email protected" {
+		t.Fatalf("message = %q, want %q", secret.Message, "email protected")
+	}
+
+	_, err = e.verifyCode(code)
+	if !errors.Is(err, model.ErrSecretNotFound) {
+		t.Fatalf("second VerifyEmailCode() error = %v, want %v", err, model.ErrSecretNotFound)
+	}
+}
+
+func TestEmailCodeExpires(t *testing.T) {
+	e := newEmailCodeTest(t, EmailCodeOptions{TTL: time.Millisecond, MaxAttempts: 3})
+
+	code := e.sendCode(t)
+	time.Sleep(10 * time.Millisecond)
+
+	_, err := e.verifyCode(code)
+	if !errors.Is(err, model.ErrEmailCodeNotFound) {
+		t.Fatalf("VerifyEmailCode() error = %v, want %v", err, model.ErrEmailCodeNotFound)
+	}
+
+	_, err = e.verifyCode(code)
+	if !errors.Is(err, model.ErrEmailCodeNotFound) {
+		t.Fatalf("VerifyEmailCode() after expiry error = %v, want %v", err, model.ErrEmailCodeNotFound)
+	}
+}
+
+func TestEmailCodeMaxAttempts(t *testing.T) {
+	const maxAttempts = 3
+
+	e := newEmailCodeTest(t, EmailCodeOptions{
+		TTL:         time.Minute,
+		MaxAttempts: maxAttempts,
+		Lockout:     LockoutOptions{MaxAttempts: 10},
+	})
+
+	code := e.sendCode(t)
+	for range maxAttempts {
+		_, err := e.verifyCode(wrongEmailCode(t, code))
+		if !errors.Is(err, model.ErrSecretNotFound) {
+			t.Fatalf("VerifyEmailCode() error = %v, want %v", err, model.ErrSecretNotFound)
+		}
+	}
+
+	_, err := e.verifyCode(code)
+	if !errors.Is(err, model.ErrEmailCodeNotFound) {
+		t.Fatalf("VerifyEmailCode() after max attempts error = %v, want %v", err, model.ErrEmailCodeNotFound)
+	}
+
+	accessKey, _ := decodeTestSecretKey(t, e.env, e.secretKey)
+	secret, err := e.env.store.SecretRepo().GetSecret(context.Background(), accessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.FailedAttempts != maxAttempts {
+		t.Fatalf("failed attempts = %d, want %d", secret.FailedAttempts, maxAttempts)
+	}
+}
+
+func TestEmailCodeResendInterval(t *testing.T) {
+	e := newEmailCodeTest(t, EmailCodeOptions{TTL: time.Minute, MaxAttempts: 3, ResendInterval: time.Hour})
+
+	code := e.sendCode(t)
+
+	_, err := e.send(context.Background(), SendEmailCodeDTO{SecretKey: e.secretKey})
+	if !errors.Is(err, model.ErrEmailCodeRecentlySent) {
+		t.Fatalf("SendEmailCode() error = %v, want %v", err, model.ErrEmailCodeRecentlySent)
+	}
+	if got := len(e.server.Messages()); got != 1 {
+		t.Fatalf("got %d emails, want 1", got)
+	}
+
+	_, err = e.verifyCode(code)
+	if err != nil {
+		t.Fatalf("VerifyEmailCode() with first code error = %v", err)
+	}
+}
+
+func TestEmailCodeResendReplacesCode(t *testing.T) {
+	e := newEmailCodeTest(t, EmailCodeOptions{TTL: time.Minute, MaxAttempts: 3})
+
+	first := e.sendCode(t)
+	second := e.sendCode(t)
+	if got := len(e.server.Messages()); got != 2 {
+		t.Fatalf("got %d emails, want 2", got)
+	}
+
+	if first != second {
+		_, err := e.verifyCode(first)
+		if !errors.Is(err, model.ErrSecretNotFound) {
+			t.Fatalf("VerifyEmailCode() with replaced code error = %v, want %v", err, model.ErrSecretNotFound)
+		}
+	}
+
+	_, err := e.verifyCode(second)
+	if err != nil {
+		t.Fatalf("VerifyEmailCode() with latest code error = %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore/fs"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/cryptor/aes"
+	"github.com/protomem/secrets-keeper/internal/cryptor/base64"
+	"github.com/protomem/secrets-keeper/internal/cryptor/pkcs7"
+	"github.com/protomem/secrets-keeper/internal/cryptor/stream"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/mailer"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+	"github.com/protomem/secrets-keeper/internal/passhash/pbkdf2"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/logging/stdlog"
+)
+
+type testEnv struct {
+	database  string
+	store     *storage.Storage
+	hasher    passhash.Hasher
+	encoder   cryptor.Encoder
+	encryptor cryptor.Encryptor
+	streamer  cryptor.StreamEncryptor
+	sealer    *envelope.Envelope
+	blobs     *fs.Store
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	logger, err := stdlog.New("error")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	database := filepath.Join(t.TempDir(), "data.db")
+	store, err := storage.New(ctx, logger, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close(context.Background()) })
+
+	err = store.Migrate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streamer, err := stream.NewEncryptor(64 << 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealer, err := envelope.New(envelope.Options{MaxSize: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blobs, err := fs.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rawEncoder := base64.NewEncoder(false)
+
+	return &testEnv{
+		database:  database,
+		store:     store,
+		hasher:    pbkdf2.NewHasher(rawEncoder, pbkdf2.Options{Iterations: 1000, SaltLength: 16, KeyLength: 32}),
+		encoder:   base64.NewEncoder(true),
+		encryptor: aes.NewEncryptor(rawEncoder, pkcs7.NewPaddinger()),
+		streamer:  streamer,
+		sealer:    sealer,
+		blobs:     blobs,
+	}
+}
+
+func (e *testEnv) createSecret(t *testing.T, sender mailer.Mailer, dto CreateSecretDTO) CreatedSecretDTO {
+	t.Helper()
+
+	created, err := e.createSecretFunc(sender, cryptor.ModeStandard)(context.Background(), dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return created
+}
+
+func (e *testEnv) createSecretFunc(sender mailer.Mailer, mode cryptor.Mode) UseCaseFunc[CreateSecretDTO, CreatedSecretDTO] {
+	return CreateSecret(
+		e.store.SecretRepo(),
+		e.store.RequestRepo(),
+		e.store.StatusRepo(),
+		e.store.EventRepo(),
+		e.store.UploadRepo(),
+		e.store.SignerRepo(),
+		e.hasher,
+		e.encoder,
+		e.encryptor,
+		e.streamer,
+		e.sealer,
+		cryptor.NewKEMRegistry(),
+		e.blobs,
+		nil,
+		sender,
+		mode,
+	)
+}
+
+func (e *testEnv) getSecretFunc(lockout LockoutOptions) UseCaseFunc[GetSecretDTO, model.Secret] {
+	return GetSecret(
+		e.store.SecretRepo(),
+		e.store.EventRepo(),
+		e.store.SignerRepo(),
+		e.store.DownloadRepo(),
+		e.hasher,
+		e.encoder,
+		e.encryptor,
+		e.sealer,
+		e.blobs,
+		nil,
+		lockout,
+		time.Minute,
+	)
+}
+
+func decodeTestSecretKey(t *testing.T, env *testEnv, secretKey string) (string, string) {
+	t.Helper()
+
+	decoded, err := env.encoder.Decode([]byte(secretKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessKey, signingKey, ok := strings.Cut(string(decoded), "$")
+	if !ok {
+		t.Fatalf("malformed secret key %q", decoded)
+	}
+
+	return accessKey, signingKey
+}
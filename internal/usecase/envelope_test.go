@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func saveRawSecret(t *testing.T, env *testEnv, accessKey string, sealed bool, payload []byte) string {
+	t.Helper()
+
+	const signingKey = "0123456789abcdef"
+
+	encrypted, err := env.encryptor.Encrypt(payload, []byte(signingKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	_, err = env.store.SecretRepo().SaveSecret(context.Background(), model.Secret{
+		CreatedAt:   now,
+		ExpiredAt:   now.Add(time.Hour),
+		AccessKey:   accessKey,
+		SigningKey:  signingKey[6:],
+		Sealed:      sealed,
+		PayloadType: model.PayloadText,
+		Message:     encrypted,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secretKey, err := env.encoder.Encode([]byte(accessKey + "$" + signingKey[:6]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(secretKey)
+}
+
+func TestGetSecretOpensUnsealedLegacyRows(t *testing.T) {
+	env := newTestEnv(t)
+	secretKey := saveRawSecret(t, env, "legacy", false, []byte("stored before envelopes"))
+
+	secret, err := env.getSecretFunc(LockoutOptions{MaxAttempts: 5})(context.Background(), GetSecretDTO{SecretKey: secretKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(secret.Message) != "stored before envelopes" {
+		t.Fatalf("message = %q", secret.Message)
+	}
+}
+
+func TestGetSecretRejectsSealedRowsWithoutEnvelope(t *testing.T) {
+	env := newTestEnv(t)
+	secretKey := saveRawSecret(t, env, "sealed", true, []byte("no envelope"))
+
+	_, err := env.getSecretFunc(LockoutOptions{MaxAttempts: 5})(context.Background(), GetSecretDTO{SecretKey: secretKey})
+	if err == nil {
+		t.Fatal("GetSecret() accepted a sealed row without an envelope")
+	}
+
+	_, err = env.store.SecretRepo().GetSecret(context.Background(), "sealed")
+	if err != nil {
+		t.Fatalf("secret was consumed: %v", err)
+	}
+}
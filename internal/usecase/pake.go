@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/pake"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+)
+
+type PakeOptions struct {
+	SessionTTL time.Duration
+	Lockout    LockoutOptions
+}
+
+type PhraseVerifierDTO struct {
+	Salt   []byte
+	Params pake.Params
+	W0     []byte
+	L      []byte
+}
+
+type GetPakeParamsDTO struct {
+	SecretKey string
+	ClientIP  netip.Addr
+}
+
+type PakeParamsDTO struct {
+	Suite  string
+	Salt   []byte
+	Params pake.Params
+}
+
+type BeginPakeDTO struct {
+	SecretKey string
+	ShareP    []byte
+	ClientIP  netip.Addr
+}
+
+type BeganPakeDTO struct {
+	SessionKey string
+	ShareV     []byte
+	ConfirmV   []byte
+	ExpiredAt  time.Time
+}
+
+type FinishPakeDTO struct {
+	SecretKey  string
+	SessionKey string
+	ConfirmP   []byte
+	ClientIP   netip.Addr
+}
+
+type FinishedPakeDTO struct {
+	Secret    model.Secret
+	SharedKey []byte
+}
+
+func GetPakeParams(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	opts PakeOptions,
+) UseCaseFunc[GetPakeParamsDTO, PakeParamsDTO] {
+	return func(ctx context.Context, dto GetPakeParamsDTO) (PakeParamsDTO, error) {
+		const op = "usecase.GetPakeParams"
+
+		secret, _, _, err := findGatedSecret(
+			ctx, secretRepo, eventRepo, encoder, encryptor, blobs, locator, opts.Lockout, dto.SecretKey, dto.ClientIP, hasPhraseVerifier,
+		)
+		if err != nil {
+			return PakeParamsDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return PakeParamsDTO{
+			Suite:  pake.Suite,
+			Salt:   secret.PhraseVerifier.Salt,
+			Params: verifierParams(secret.PhraseVerifier),
+		}, nil
+	}
+}
+
+func BeginPake(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	pakeRepo *storage.PakeRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	opts PakeOptions,
+) UseCaseFunc[BeginPakeDTO, BeganPakeDTO] {
+	return func(ctx context.Context, dto BeginPakeDTO) (BeganPakeDTO, error) {
+		const op = "usecase.BeginPake"
+		var err error
+		now := time.Now()
+
+		secret, _, _, err := findGatedSecret(
+			ctx, secretRepo, eventRepo, encoder, encryptor, blobs, locator, opts.Lockout, dto.SecretKey, dto.ClientIP, hasPhraseVerifier,
+		)
+		if err != nil {
+			return BeganPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = waitAttemptDelay(ctx, opts.Lockout, secret.FailedAttempts)
+		if err != nil {
+			return BeganPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		exchange, err := pake.Respond(toPakeVerifier(secret.PhraseVerifier), dto.ShareP)
+		if err != nil {
+			if errors.Is(err, pake.ErrInvalidShare) {
+				return BeganPakeDTO{}, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+			}
+
+			return BeganPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		lockout := opts.Lockout
+		if lockout.MaxAttempts > 0 {
+			lockout.MaxAttempts++
+		}
+
+		err = registerFailedAttempt(ctx, secretRepo, eventRepo, blobs, lockout, secret)
+		if err != nil {
+			return BeganPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		session := model.PakeSession{
+			CreatedAt:  now,
+			ExpiredAt:  now.Add(opts.SessionTTL),
+			SessionKey: randstr.SecureGen(32),
+			AccessKey:  secret.AccessKey,
+			ConfirmKey: exchange.ConfirmP,
+			SharedKey:  exchange.SharedKey,
+		}
+
+		_, err = pakeRepo.SaveSession(ctx, session)
+		if err != nil {
+			return BeganPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return BeganPakeDTO{
+			SessionKey: session.SessionKey,
+			ShareV:     exchange.ShareV,
+			ConfirmV:   exchange.ConfirmV,
+			ExpiredAt:  session.ExpiredAt,
+		}, nil
+	}
+}
+
+func FinishPake(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	signerRepo *storage.SignerRepository,
+	downloadRepo *storage.DownloadRepository,
+	pakeRepo *storage.PakeRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	sealer *envelope.Envelope,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	opts PakeOptions,
+	downloadTTL time.Duration,
+) UseCaseFunc[FinishPakeDTO, FinishedPakeDTO] {
+	return func(ctx context.Context, dto FinishPakeDTO) (FinishedPakeDTO, error) {
+		const op = "usecase.FinishPake"
+		var err error
+		now := time.Now()
+
+		secret, signingKey, country, err := findGatedSecret(
+			ctx, secretRepo, eventRepo, encoder, encryptor, blobs, locator, opts.Lockout, dto.SecretKey, dto.ClientIP, hasPhraseVerifier,
+		)
+		if err != nil {
+			return FinishedPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		session, err := pakeRepo.TakeSession(ctx, dto.SessionKey)
+		if err != nil {
+			return FinishedPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if session.AccessKey != secret.AccessKey || !now.Before(session.ExpiredAt) {
+			return FinishedPakeDTO{}, fmt.Errorf("%s: %w", op, model.ErrPakeSessionNotFound)
+		}
+
+		err = (pake.Exchange{ConfirmP: session.ConfirmKey}).Verify(dto.ConfirmP)
+		if err != nil {
+			return FinishedPakeDTO{}, fmt.Errorf("%s: %w: %w", op, model.ErrSecretNotFound, err)
+		}
+
+		err = checkSecretAvailable(secret, now)
+		if err != nil {
+			return FinishedPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		key, err := lockSigningKey(signingKey, secret.SigningKey)
+		if err != nil {
+			return FinishedPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer key.Destroy()
+
+		secret, err = revealSecret(
+			ctx, secretRepo, eventRepo, signerRepo, downloadRepo, encoder, encryptor, sealer, downloadTTL,
+			secret, key.Bytes(), country,
+		)
+		if err != nil {
+			return FinishedPakeDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return FinishedPakeDTO{
+			Secret:    secret,
+			SharedKey: session.SharedKey,
+		}, nil
+	}
+}
+
+func PurgeExpiredPakeSessions(pakeRepo *storage.PakeRepository) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredPakeSessions"
+
+		purged, err := pakeRepo.RemoveExpiredSessions(ctx, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return purged, nil
+	}
+}
+
+func newPhraseVerifier(phrase []byte, verifier *PhraseVerifierDTO) (*model.PhraseVerifier, error) {
+	const op = "newPhraseVerifier"
+
+	if verifier != nil {
+		v := pake.Verifier{
+			Salt:   verifier.Salt,
+			Params: verifier.Params,
+			W0:     verifier.W0,
+			L:      verifier.L,
+		}
+
+		err := v.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		return fromPakeVerifier(v), nil
+	}
+
+	if len(phrase) == 0 {
+		return nil, nil
+	}
+
+	v, err := pake.NewVerifier(phrase, pake.DefaultParams)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fromPakeVerifier(v), nil
+}
+
+func hasPhraseVerifier(secret model.Secret) bool {
+	return secret.PhraseVerifier != nil
+}
+
+func verifierParams(v *model.PhraseVerifier) pake.Params {
+	return pake.Params{Time: v.Time, Memory: v.Memory, Threads: v.Threads}
+}
+
+func toPakeVerifier(v *model.PhraseVerifier) pake.Verifier {
+	return pake.Verifier{
+		Salt:   v.Salt,
+		Params: verifierParams(v),
+		W0:     v.W0,
+		L:      v.L,
+	}
+}
+
+func fromPakeVerifier(v pake.Verifier) *model.PhraseVerifier {
+	return &model.PhraseVerifier{
+		Salt:    v.Salt,
+		Time:    v.Params.Time,
+		Memory:  v.Params.Memory,
+		Threads: v.Params.Threads,
+		W0:      v.W0,
+		L:       v.L,
+	}
+}
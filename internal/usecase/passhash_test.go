@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor/base64"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+	"github.com/protomem/secrets-keeper/internal/passhash/argon2"
+	"github.com/protomem/secrets-keeper/internal/passhash/pbkdf2"
+)
+
+func TestGetSecretVerifiesHashesOfPreviousHasher(t *testing.T) {
+	env := newTestEnv(t)
+
+	rawEncoder := base64.NewEncoder(false)
+	argon2Hasher := argon2.NewHasher(rawEncoder, argon2.Options{Memory: 1024, Iterations: 1, Parallel: 1, SaltLength: 16, KeyLength: 32})
+	pbkdf2Hasher := pbkdf2.NewHasher(rawEncoder, pbkdf2.Options{Iterations: 1000, SaltLength: 16, KeyLength: 32})
+
+	env.hasher = argon2Hasher
+	created := env.createSecret(t, nil, CreateSecretDTO{
+		Message:      []byte("hashed before the switch"),
+		TTL:          1,
+		SecretPhrase: []byte("correct horse"),
+	})
+
+	env.hasher = passhash.NewDispatcher(pbkdf2Hasher, argon2Hasher, pbkdf2Hasher)
+
+	secret, err := GetSecret(
+		env.store.SecretRepo(),
+		env.store.EventRepo(),
+		env.store.SignerRepo(),
+		env.store.DownloadRepo(),
+		env.hasher,
+		env.encoder,
+		env.encryptor,
+		env.sealer,
+		env.blobs,
+		nil,
+		LockoutOptions{MaxAttempts: 5},
+		time.Minute,
+	)(context.Background(), GetSecretDTO{
+		SecretKey:    created.SecretKey,
+		SecretPhrase: []byte("correct horse"),
+	})
+	if err != nil {
+		t.Fatalf("get secret: %v", err)
+	}
+
+	if string(secret.Message) != "hashed before the switch" {
+		t.Fatalf("message = %q", secret.Message)
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/recipient"
+	"github.com/protomem/secrets-keeper/pkg/e2e"
+)
+
+const defaultFieldContentType = "text/plain"
+
+func encodePayload(message []byte, fields []model.SecretField) (model.PayloadType, []byte, error) {
+	const op = "encodePayload"
+
+	if len(fields) == 0 {
+		return model.PayloadText, bytes.Clone(message), nil
+	}
+
+	if len(message) > 0 {
+		return "", nil, fmt.Errorf("%s: %w: both message and fields are set", op, model.ErrInvalidSecret)
+	}
+
+	normalized := make([]model.SecretField, 0, len(fields))
+	for _, field := range fields {
+		field.Name = strings.TrimSpace(field.Name)
+		if field.Name == "" {
+			return "", nil, fmt.Errorf("%s: %w: field without name", op, model.ErrInvalidSecret)
+		}
+
+		if field.ContentType == "" {
+			field.ContentType = defaultFieldContentType
+		}
+
+		normalized = append(normalized, field)
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return model.PayloadFields, data, nil
+}
+
+func encodeE2EPayload(dto CreateSecretDTO) (model.PayloadType, []byte, error) {
+	const op = "encodeE2EPayload"
+
+	if len(dto.Message) > 0 || len(dto.Fields) > 0 || dto.Attachments != nil || len(dto.Uploads) > 0 {
+		return "", nil, fmt.Errorf("%s: %w: ciphertext cannot be combined with plaintext content", op, model.ErrInvalidSecret)
+	}
+
+	err := e2e.Validate(dto.Ciphertext)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+	}
+
+	return model.PayloadE2E, []byte(dto.Ciphertext), nil
+}
+
+func encodeRecipientsPayload(kems *cryptor.KEMRegistry, dto CreateSecretDTO) (model.PayloadType, []byte, error) {
+	const op = "encodeRecipientsPayload"
+
+	if len(dto.Fields) > 0 || dto.Attachments != nil || len(dto.Uploads) > 0 || dto.Ciphertext != "" {
+		return "", nil, fmt.Errorf("%s: %w: recipients support only a plain message", op, model.ErrInvalidSecret)
+	}
+
+	recipients, err := recipient.Parse(dto.Recipients, kems)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+	}
+
+	data, err := recipient.Encrypt(dto.Message, recipients)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return model.PayloadAge, data, nil
+}
+
+func decodePayload(payloadType model.PayloadType, data []byte) (model.SecretText, []model.SecretField, error) {
+	const op = "decodePayload"
+
+	switch payloadType {
+	case model.PayloadText, model.PayloadE2E, model.PayloadAge, "":
+		return bytes.Clone(data), nil, nil
+	case model.PayloadFields:
+		var fields []model.SecretField
+		err := json.Unmarshal(data, &fields)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return renderFields(fields), fields, nil
+	default:
+		return nil, nil, fmt.Errorf("%s: unknown payload type %q", op, payloadType)
+	}
+}
+
+func renderFields(fields []model.SecretField) model.SecretText {
+	var text []byte
+	for i, field := range fields {
+		if i > 0 {
+			text = append(text, '\n')
+		}
+
+		text = append(text, field.Name...)
+		text = append(text, ": "...)
+		text = append(text, field.Value...)
+	}
+
+	return text
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/storage"
+)
+
+func PurgeExpiredSecrets(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	blobs blobstore.Store,
+) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredSecrets"
+		var err error
+		now := time.Now()
+
+		secrets, err := secretRepo.FindExpiredSecrets(ctx, now)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var (
+			purged = 0
+			errs   = make([]error, 0)
+		)
+		for _, secret := range secrets {
+			err = secretRepo.RemoveSecret(ctx, secret.AccessKey)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			purged++
+
+			err = deleteAttachments(ctx, blobs, secret.Attachments)
+			if err != nil {
+				errs = append(errs, err)
+			}
+
+			_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+				CreatedAt: now,
+				AccessKey: secret.AccessKey,
+				Kind:      model.SecretDestroyed,
+				Details:   "expired",
+			})
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(errs) > 0 {
+			return purged, fmt.Errorf("%s: %w", op, errors.Join(errs...))
+		}
+
+		return purged, nil
+	}
+}
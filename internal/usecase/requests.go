@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+)
+
+type CreateSecretRequestDTO struct {
+	Note         string
+	TTL          int64 // in hours
+	SecretPhrase []byte
+}
+
+type CreatedSecretRequestDTO struct {
+	RequestKey string
+	SecretKey  string
+}
+
+func CreateSecretRequest(
+	requestRepo *storage.RequestRepository,
+	hasher passhash.Hasher,
+	encoder cryptor.Encoder,
+) UseCaseFunc[CreateSecretRequestDTO, CreatedSecretRequestDTO] {
+	return func(ctx context.Context, dto CreateSecretRequestDTO) (CreatedSecretRequestDTO, error) {
+		const op = "usecase.CreateSecretRequest"
+		var err error
+		now := time.Now()
+
+		requestKey := []byte(randstr.SecureGen(16))
+		accessKey := []byte(randstr.SecureGen(8))
+		signingKey := []byte(randstr.SecureGen(16))
+
+		encodedRequestKey, err := encoder.Encode(requestKey)
+		if err != nil {
+			return CreatedSecretRequestDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		secretKey, err := encoder.Encode(bytes.Join(
+			[][]byte{accessKey, signingKey[:6]},
+			[]byte("$"),
+		))
+		if err != nil {
+			return CreatedSecretRequestDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var hashedPhrase string
+		if len(dto.SecretPhrase) > 0 {
+			hashedPhrase, err = hasher.Generate(dto.SecretPhrase)
+			if err != nil {
+				return CreatedSecretRequestDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		_, err = requestRepo.SaveRequest(ctx, model.SecretRequest{
+			CreatedAt:    now,
+			ExpiredAt:    now.Add(time.Duration(dto.TTL) * time.Hour),
+			RequestKey:   string(requestKey),
+			AccessKey:    string(accessKey),
+			SigningKey:   string(signingKey),
+			SecretPhrase: hashedPhrase,
+			Note:         dto.Note,
+		})
+		if err != nil {
+			return CreatedSecretRequestDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return CreatedSecretRequestDTO{
+			RequestKey: string(encodedRequestKey),
+			SecretKey:  string(secretKey),
+		}, nil
+	}
+}
+
+type GetSecretRequestDTO struct {
+	RequestKey string
+}
+
+func GetSecretRequest(
+	requestRepo *storage.RequestRepository,
+	encoder cryptor.Encoder,
+) UseCaseFunc[GetSecretRequestDTO, model.SecretRequest] {
+	return func(ctx context.Context, dto GetSecretRequestDTO) (model.SecretRequest, error) {
+		const op = "usecase.GetSecretRequest"
+		var err error
+
+		request, err := findSecretRequest(ctx, requestRepo, encoder, dto.RequestKey)
+		if err != nil {
+			return model.SecretRequest{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return request, nil
+	}
+}
+
+type FulfilSecretRequestDTO struct {
+	RequestKey string
+	Message    []byte
+	Fields     []model.SecretField
+}
+
+func FulfilSecretRequest(
+	requestRepo *storage.RequestRepository,
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	sealer *envelope.Envelope,
+) UseCaseFunc[FulfilSecretRequestDTO, struct{}] {
+	return func(ctx context.Context, dto FulfilSecretRequestDTO) (struct{}, error) {
+		const op = "usecase.FulfilSecretRequest"
+		var err error
+		now := time.Now()
+
+		request, err := findSecretRequest(ctx, requestRepo, encoder, dto.RequestKey)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		signingKey := []byte(request.SigningKey)
+
+		payloadType, payload, err := encodePayload(dto.Message, dto.Fields)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		payload, err = sealer.Seal(payload)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		encryptedMessage, err := encryptor.Encrypt(payload, signingKey)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = secretRepo.SaveSecret(ctx, model.Secret{
+			CreatedAt:    now,
+			ExpiredAt:    now.Add(request.ExpiredAt.Sub(request.CreatedAt)),
+			AccessKey:    request.AccessKey,
+			SigningKey:   string(signingKey[6:]),
+			KeyCheck:     signingKeyCheck(signingKey),
+			SecretPhrase: request.SecretPhrase,
+			Sealed:       true,
+			PayloadType:  payloadType,
+			Message:      encryptedMessage,
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = requestRepo.RemoveRequest(ctx, request.RequestKey)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+			CreatedAt: now,
+			AccessKey: request.AccessKey,
+			Kind:      model.SecretCreated,
+			Details:   "fulfilled secret request",
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return struct{}{}, nil
+	}
+}
+
+func findSecretRequest(
+	ctx context.Context,
+	requestRepo *storage.RequestRepository,
+	encoder cryptor.Encoder,
+	requestKey string,
+) (model.SecretRequest, error) {
+	const op = "findSecretRequest"
+	now := time.Now()
+
+	decodedRequestKey, err := encoder.Decode([]byte(requestKey))
+	if err != nil {
+		return model.SecretRequest{}, fmt.Errorf("%s: %w: invalid request key: %w", op, model.ErrRequestNotFound, err)
+	}
+
+	request, err := requestRepo.GetRequest(ctx, string(decodedRequestKey))
+	if err != nil {
+		return model.SecretRequest{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if request.ExpiredAt.Unix() < now.Unix() && request.ExpiredAt.Unix() > request.CreatedAt.Unix() {
+		return model.SecretRequest{}, fmt.Errorf("%s: %w", op, model.ErrRequestNotFound)
+	}
+
+	return request, nil
+}
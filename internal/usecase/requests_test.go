@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func TestGetSecretRequestRejectsMalformedKey(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, requestKey := range []string{"!!!", ""} {
+		_, err := GetSecretRequest(env.store.RequestRepo(), env.encoder)(context.Background(), GetSecretRequestDTO{
+			RequestKey: requestKey,
+		})
+		if !errors.Is(err, model.ErrRequestNotFound) {
+			t.Fatalf("GetSecretRequest(%q): expected ErrRequestNotFound, got %v", requestKey, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/cryptor/shamir"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/storage"
+)
+
+type SharesDTO struct {
+	Total     int
+	Threshold int
+	Window    time.Duration
+}
+
+type SubmitSecretShareDTO struct {
+	ShareKey string
+	ClientIP netip.Addr
+}
+
+type SubmittedSecretShareDTO struct {
+	Revealed  bool
+	Secret    model.Secret
+	Submitted int
+	Threshold int
+	ExpiredAt time.Time
+}
+
+func SubmitSecretShare(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	shareRepo *storage.ShareRepository,
+	signerRepo *storage.SignerRepository,
+	downloadRepo *storage.DownloadRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	sealer *envelope.Envelope,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	downloadTTL time.Duration,
+) UseCaseFunc[SubmitSecretShareDTO, SubmittedSecretShareDTO] {
+	return func(ctx context.Context, dto SubmitSecretShareDTO) (SubmittedSecretShareDTO, error) {
+		const op = "usecase.SubmitSecretShare"
+		var err error
+		now := time.Now()
+
+		accessKey, share, err := decodeShareKey(encoder, dto.ShareKey)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer cryptor.Wipe(share)
+
+		secret, err := secretRepo.GetSecret(ctx, accessKey)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if secret.ExpiredAt.Unix() < now.Unix() && secret.ExpiredAt.Unix() > secret.CreatedAt.Unix() {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		if secret.ShareThreshold == 0 || !validShare(secret, share) {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		err = checkClientIP(secret.AllowedCIDRs, dto.ClientIP)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		country, err := checkClientCountry(locator, secret.AllowedCountries, secret.DeniedCountries, dto.ClientIP)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if now.Before(secret.AvailableFrom) {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, &model.SecretNotYetAvailableError{
+				AvailableFrom: secret.AvailableFrom,
+			})
+		}
+
+		shares, err := shareRepo.FindShares(ctx, accessKey)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer func() { wipeShares(shares) }()
+
+		expiredAt := now.Add(secret.ShareWindow)
+		if len(shares) > 0 {
+			expiredAt = shares[0].ExpiredAt
+		}
+
+		if !now.Before(expiredAt) {
+			err = shareRepo.RemoveShares(ctx, accessKey)
+			if err != nil {
+				return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			wipeShares(shares)
+			shares, expiredAt = nil, now.Add(secret.ShareWindow)
+		}
+
+		submitted := model.SecretShare{
+			CreatedAt: now,
+			ExpiredAt: expiredAt,
+			AccessKey: accessKey,
+			Index:     shamir.Index(share),
+			Share:     share,
+		}
+
+		_, err = shareRepo.SaveShare(ctx, submitted)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		shares = append(shares, submitted)
+		if len(shares) < secret.ShareThreshold {
+			return SubmittedSecretShareDTO{
+				Submitted: len(shares),
+				Threshold: secret.ShareThreshold,
+				ExpiredAt: expiredAt,
+			}, nil
+		}
+
+		rawShares := make([][]byte, 0, len(shares))
+		for _, s := range shares {
+			rawShares = append(rawShares, s.Share)
+		}
+
+		signingKey, err := shamir.Combine(rawShares)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		key, err := lockSigningKey(signingKey, "")
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer key.Destroy()
+
+		err = shareRepo.RemoveShares(ctx, accessKey)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		secret, err = revealSecret(
+			ctx, secretRepo, eventRepo, signerRepo, downloadRepo, encoder, encryptor, sealer, downloadTTL,
+			secret, key.Bytes(), country,
+		)
+		if err != nil {
+			return SubmittedSecretShareDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return SubmittedSecretShareDTO{
+			Revealed:  true,
+			Secret:    secret,
+			Submitted: len(shares),
+			Threshold: secret.ShareThreshold,
+		}, nil
+	}
+}
+
+func PurgeExpiredShares(shareRepo *storage.ShareRepository) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredShares"
+
+		purged, err := shareRepo.RemoveExpiredShares(ctx, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return purged, nil
+	}
+}
+
+func splitSigningKey(
+	encoder cryptor.Encoder,
+	accessKey []byte,
+	signingKey []byte,
+	dto SharesDTO,
+) ([]string, []string, error) {
+	const op = "splitSigningKey"
+
+	if dto.Window <= 0 {
+		return nil, nil, fmt.Errorf("%s: %w: invalid share window", op, model.ErrInvalidSecret)
+	}
+
+	shares, err := shamir.Split(signingKey, dto.Total, dto.Threshold)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+	}
+
+	keys := make([]string, 0, len(shares))
+	digests := make([]string, 0, len(shares))
+	for _, share := range shares {
+		key, err := encoder.Encode(bytes.Join(
+			[][]byte{accessKey, []byte(hex.EncodeToString(share))},
+			[]byte("$"),
+		))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		digest := sha256.Sum256(share)
+		keys = append(keys, string(key))
+		digests = append(digests, hex.EncodeToString(digest[:]))
+	}
+
+	return keys, digests, nil
+}
+
+func decodeShareKey(encoder cryptor.Encoder, shareKey string) (string, []byte, error) {
+	decoded, err := encoder.Decode([]byte(shareKey))
+	if err != nil {
+		return "", nil, err
+	}
+
+	accessKey, encodedShare, ok := bytes.Cut(decoded, []byte("$"))
+	if !ok {
+		return "", nil, fmt.Errorf("%w: invalid share key", model.ErrSecretNotFound)
+	}
+
+	share, err := hex.DecodeString(string(encodedShare))
+	if err != nil || len(share) < 2 {
+		return "", nil, fmt.Errorf("%w: invalid share key", model.ErrSecretNotFound)
+	}
+
+	return string(accessKey), share, nil
+}
+
+func wipeShares(shares []model.SecretShare) {
+	for _, share := range shares {
+		cryptor.Wipe(share.Share)
+	}
+}
+
+func validShare(secret model.Secret, share []byte) bool {
+	idx := shamir.Index(share)
+	if idx < 1 || idx > len(secret.ShareDigests) {
+		return false
+	}
+
+	digest := sha256.Sum256(share)
+	return subtle.ConstantTimeCompare(
+		[]byte(hex.EncodeToString(digest[:])),
+		[]byte(secret.ShareDigests[idx-1]),
+	) == 1
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func (e *testEnv) submitShareFunc() UseCaseFunc[SubmitSecretShareDTO, SubmittedSecretShareDTO] {
+	return SubmitSecretShare(
+		e.store.SecretRepo(),
+		e.store.EventRepo(),
+		e.store.ShareRepo(),
+		e.store.SignerRepo(),
+		e.store.DownloadRepo(),
+		e.encoder,
+		e.encryptor,
+		e.sealer,
+		e.blobs,
+		nil,
+		time.Minute,
+	)
+}
+
+func (e *testEnv) createSharedSecret(t *testing.T, message string, shares SharesDTO) []string {
+	t.Helper()
+
+	created := e.createSecret(t, nil, CreateSecretDTO{Message: []byte(message), TTL: 1, Shares: &shares})
+	if len(created.ShareKeys) != shares.Total {
+		t.Fatalf("share keys = %d, want %d", len(created.ShareKeys), shares.Total)
+	}
+
+	return created.ShareKeys
+}
+
+func submitShare(t *testing.T, submit UseCaseFunc[SubmitSecretShareDTO, SubmittedSecretShareDTO], shareKey string) SubmittedSecretShareDTO {
+	t.Helper()
+
+	submitted, err := submit(context.Background(), SubmitSecretShareDTO{ShareKey: shareKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return submitted
+}
+
+func TestSubmitSecretShareRevealsAtThreshold(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	submit := env.submitShareFunc()
+
+	shareKeys := env.createSharedSecret(t, "break glass", SharesDTO{Total: 5, Threshold: 3, Window: time.Hour})
+
+	for i, shareKey := range shareKeys[:2] {
+		submitted := submitShare(t, submit, shareKey)
+		if submitted.Revealed {
+			t.Fatalf("secret revealed after %d shares", i+1)
+		}
+		if submitted.Submitted != i+1 || submitted.Threshold != 3 {
+			t.Fatalf("submitted = %d/%d, want %d/3", submitted.Submitted, submitted.Threshold, i+1)
+		}
+	}
+
+	submitted := submitShare(t, submit, shareKeys[4])
+	if !submitted.Revealed {
+		t.Fatal("secret not revealed at the threshold")
+	}
+	if string(submitted.Secret.Message) != "break glass" {
+		t.Fatalf("message = %q", submitted.Secret.Message)
+	}
+
+	accessKey, _, err := decodeShareKey(env.encoder, shareKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := env.store.ShareRepo().FindShares(ctx, accessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("pending shares after reveal = %d, want 0", len(pending))
+	}
+
+	_, err = submit(ctx, SubmitSecretShareDTO{ShareKey: shareKeys[3]})
+	if !errors.Is(err, model.ErrSecretNotFound) {
+		t.Fatalf("SubmitSecretShare() after reveal error = %v, want %v", err, model.ErrSecretNotFound)
+	}
+}
+
+func TestSubmitSecretShareRejectsDuplicateShares(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	submit := env.submitShareFunc()
+
+	shareKeys := env.createSharedSecret(t, "break glass", SharesDTO{Total: 3, Threshold: 2, Window: time.Hour})
+
+	submitShare(t, submit, shareKeys[0])
+
+	_, err := submit(ctx, SubmitSecretShareDTO{ShareKey: shareKeys[0]})
+	if !errors.Is(err, model.ErrShareAlreadySubmitted) {
+		t.Fatalf("SubmitSecretShare() of a duplicate error = %v, want %v", err, model.ErrShareAlreadySubmitted)
+	}
+
+	submitted := submitShare(t, submit, shareKeys[1])
+	if !submitted.Revealed {
+		t.Fatal("duplicate share counted towards the threshold")
+	}
+}
+
+func TestSubmitSecretShareRejectsForgedShares(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	submit := env.submitShareFunc()
+
+	shareKeys := env.createSharedSecret(t, "break glass", SharesDTO{Total: 3, Threshold: 2, Window: time.Hour})
+
+	accessKey, share, err := decodeShareKey(env.encoder, shareKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	share[len(share)-1] ^= 0x01
+
+	forged, err := env.encoder.Encode([]byte(accessKey + "$" + hex.EncodeToString(share)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = submit(ctx, SubmitSecretShareDTO{ShareKey: string(forged)})
+	if !errors.Is(err, model.ErrSecretNotFound) {
+		t.Fatalf("SubmitSecretShare() of a forged share error = %v, want %v", err, model.ErrSecretNotFound)
+	}
+
+	submitted := submitShare(t, submit, shareKeys[1])
+	if submitted.Revealed || submitted.Submitted != 1 {
+		t.Fatalf("submitted = %d, revealed = %v, want 1 pending share", submitted.Submitted, submitted.Revealed)
+	}
+}
+
+func TestSubmitSecretShareResetsExpiredWindow(t *testing.T) {
+	env := newTestEnv(t)
+	submit := env.submitShareFunc()
+
+	shareKeys := env.createSharedSecret(t, "break glass", SharesDTO{Total: 3, Threshold: 3, Window: time.Second})
+
+	submitShare(t, submit, shareKeys[0])
+	submitShare(t, submit, shareKeys[1])
+
+	time.Sleep(1100 * time.Millisecond)
+
+	submitted := submitShare(t, submit, shareKeys[2])
+	if submitted.Revealed {
+		t.Fatal("shares from an expired window were combined")
+	}
+	if submitted.Submitted != 1 {
+		t.Fatalf("submitted after the window reset = %d, want 1", submitted.Submitted)
+	}
+
+	submitted = submitShare(t, submit, shareKeys[0])
+	if submitted.Revealed || submitted.Submitted != 2 {
+		t.Fatalf("submitted = %d, revealed = %v, want 2 pending shares", submitted.Submitted, submitted.Revealed)
+	}
+
+	submitted = submitShare(t, submit, shareKeys[1])
+	if !submitted.Revealed {
+		t.Fatal("secret not revealed after the window reset")
+	}
+	if string(submitted.Secret.Message) != "break glass" {
+		t.Fatalf("message = %q", submitted.Secret.Message)
+	}
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+	"github.com/protomem/secrets-keeper/pkg/signature"
+)
+
+const (
+	maxSignerNameLength  = 128
+	signatureNonceLength = 32
+)
+
+type RegisterSignerDTO struct {
+	Name      string
+	PublicKey string
+	Proof     string
+}
+
+type SignatureDTO struct {
+	Fingerprint string
+	Nonce       string
+	Value       string
+}
+
+func RegisterSigner(signerRepo *storage.SignerRepository) UseCaseFunc[RegisterSignerDTO, model.Signer] {
+	return func(ctx context.Context, dto RegisterSignerDTO) (model.Signer, error) {
+		const op = "usecase.RegisterSigner"
+		var err error
+
+		name := strings.TrimSpace(dto.Name)
+		if name == "" || len(name) > maxSignerNameLength {
+			return model.Signer{}, fmt.Errorf("%s: %w: invalid name", op, model.ErrInvalidSigner)
+		}
+
+		pub, err := signature.ParsePublicKey(dto.PublicKey)
+		if err != nil {
+			return model.Signer{}, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSigner, err)
+		}
+
+		err = signature.Verify(pub, signature.RegistrationBytes(name, pub), dto.Proof)
+		if err != nil {
+			return model.Signer{}, fmt.Errorf("%s: %w: proof: %w", op, model.ErrInvalidSigner, err)
+		}
+
+		signer := model.Signer{
+			CreatedAt:   time.Now(),
+			Name:        name,
+			PublicKey:   signature.FormatPublicKey(pub),
+			Fingerprint: signature.Fingerprint(pub),
+		}
+
+		signer.ID, err = signerRepo.SaveSigner(ctx, signer)
+		if err != nil {
+			return model.Signer{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return signer, nil
+	}
+}
+
+func GetSigner(signerRepo *storage.SignerRepository) UseCaseFunc[string, model.Signer] {
+	return func(ctx context.Context, fingerprint string) (model.Signer, error) {
+		const op = "usecase.GetSigner"
+
+		signer, err := signerRepo.GetSigner(ctx, fingerprint)
+		if err != nil {
+			return model.Signer{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return signer, nil
+	}
+}
+
+func IssueSignatureNonce(
+	signerRepo *storage.SignerRepository,
+	ttl time.Duration,
+) UseCaseFunc[string, model.SignatureNonce] {
+	return func(ctx context.Context, fingerprint string) (model.SignatureNonce, error) {
+		const op = "usecase.IssueSignatureNonce"
+		var err error
+		now := time.Now()
+
+		signer, err := signerRepo.GetSigner(ctx, fingerprint)
+		if err != nil {
+			return model.SignatureNonce{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		nonce := model.SignatureNonce{
+			CreatedAt:   now,
+			ExpiredAt:   now.Add(ttl),
+			Fingerprint: signer.Fingerprint,
+			Nonce:       randstr.SecureGen(signatureNonceLength),
+		}
+
+		nonce.ID, err = signerRepo.SaveNonce(ctx, nonce)
+		if err != nil {
+			return model.SignatureNonce{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nonce, nil
+	}
+}
+
+func PurgeExpiredSignatureNonces(signerRepo *storage.SignerRepository) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredSignatureNonces"
+
+		purged, err := signerRepo.RemoveExpiredNonces(ctx, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return purged, nil
+	}
+}
+
+func signSecret(
+	ctx context.Context,
+	signerRepo *storage.SignerRepository,
+	dto *SignatureDTO,
+	stmt signature.Statement,
+) (*model.SecretSignature, error) {
+	const op = "signSecret"
+
+	if dto == nil {
+		return nil, nil
+	}
+
+	signer, err := signerRepo.GetSigner(ctx, dto.Fingerprint)
+	if err != nil {
+		if errors.Is(err, model.ErrSignerNotFound) {
+			return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if dto.Nonce == "" {
+		return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, model.ErrSignatureNonceNotFound)
+	}
+
+	stmt.Nonce = dto.Nonce
+	err = verifyStatement(signer, stmt.Bytes(), dto.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+	}
+
+	nonce, err := signerRepo.TakeNonce(ctx, dto.Nonce)
+	if err != nil {
+		if errors.Is(err, model.ErrSignatureNonceNotFound) {
+			return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if nonce.Fingerprint != signer.Fingerprint || !time.Now().Before(nonce.ExpiredAt) {
+		return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, model.ErrSignatureNonceNotFound)
+	}
+
+	return &model.SecretSignature{
+		Fingerprint:   signer.Fingerprint,
+		Value:         dto.Value,
+		TTL:           stmt.TTL,
+		AvailableFrom: stmt.AvailableFrom,
+		Nonce:         stmt.Nonce,
+	}, nil
+}
+
+func verifySecretSigner(
+	ctx context.Context,
+	signerRepo *storage.SignerRepository,
+	secret model.Secret,
+) (*model.SecretSigner, error) {
+	const op = "verifySecretSigner"
+
+	if secret.Signature == nil {
+		return nil, nil
+	}
+
+	signer, err := signerRepo.GetSigner(ctx, secret.Signature.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := &model.SecretSigner{
+		Name:        signer.Name,
+		Fingerprint: signer.Fingerprint,
+		Signature:   secret.Signature.Value,
+		TTL:         secret.Signature.TTL,
+		Nonce:       secret.Signature.Nonce,
+		Verified:    true,
+	}
+
+	if !secret.Signature.AvailableFrom.IsZero() {
+		res.AvailableFrom = secret.Signature.AvailableFrom.UTC().Format(time.RFC3339)
+	}
+
+	if secret.PayloadType != model.PayloadAge {
+		stmt := signature.Statement{
+			PayloadType:   string(secret.PayloadType),
+			Content:       statementContent(secret.PayloadType, secret.Message, secret.Fields),
+			TTL:           secret.Signature.TTL,
+			AvailableFrom: secret.Signature.AvailableFrom,
+			Nonce:         secret.Signature.Nonce,
+		}
+
+		message := stmt.Bytes()
+		if secret.Signature.Nonce == "" {
+			stmt.Content = secret.Message
+			message = stmt.LegacyBytes()
+		}
+
+		err = verifyStatement(signer, message, secret.Signature.Value)
+		res.Verified = err == nil
+	}
+
+	return res, nil
+}
+
+func statementContent(payloadType model.PayloadType, message []byte, fields []model.SecretField) []byte {
+	if payloadType != model.PayloadFields {
+		return message
+	}
+
+	signed := make([]signature.Field, 0, len(fields))
+	for _, field := range fields {
+		signed = append(signed, signature.Field{
+			Name:  field.Name,
+			Value: field.Value,
+		})
+	}
+
+	return signature.FieldsContent(signed)
+}
+
+func verifyStatement(signer model.Signer, message []byte, sig string) error {
+	pub, err := signature.ParsePublicKey(signer.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	return signature.Verify(pub, message, sig)
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/signature"
+)
+
+type signerTest struct {
+	env *testEnv
+}
+
+func newSignerTest(t *testing.T) *signerTest {
+	t.Helper()
+
+	return &signerTest{env: newTestEnv(t)}
+}
+
+func (s *signerTest) register(t *testing.T, name string) (ed25519.PrivateKey, model.Signer) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := RegisterSigner(s.env.store.SignerRepo())(context.Background(), RegisterSignerDTO{
+		Name:      name,
+		PublicKey: signature.FormatPublicKey(pub),
+		Proof:     signature.Sign(priv, signature.RegistrationBytes(name, pub)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return priv, signer
+}
+
+func (s *signerTest) nonce(t *testing.T, fingerprint string, ttl time.Duration) string {
+	t.Helper()
+
+	nonce, err := IssueSignatureNonce(s.env.store.SignerRepo(), ttl)(context.Background(), fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return nonce.Nonce
+}
+
+func signedDTO(priv ed25519.PrivateKey, fingerprint, nonce string, fields []model.SecretField) CreateSecretDTO {
+	signed := make([]signature.Field, 0, len(fields))
+	for _, field := range fields {
+		signed = append(signed, signature.Field{Name: field.Name, Value: field.Value})
+	}
+
+	stmt := signature.Statement{
+		PayloadType: string(model.PayloadFields),
+		Content:     signature.FieldsContent(signed),
+		TTL:         1,
+		Nonce:       nonce,
+	}
+
+	return CreateSecretDTO{
+		Fields: fields,
+		TTL:    1,
+		Signature: &SignatureDTO{
+			Fingerprint: fingerprint,
+			Nonce:       nonce,
+			Value:       signature.Sign(priv, stmt.Bytes()),
+		},
+	}
+}
+
+func TestSignedSecretVerifiesOnRead(t *testing.T) {
+	s := newSignerTest(t)
+	priv, signer := s.register(t, "alice")
+
+	fields := []model.SecretField{{Name: "user", Value: "alice\npassword: x"}}
+	created := s.env.createSecret(t, nil, signedDTO(priv, signer.Fingerprint, s.nonce(t, signer.Fingerprint, time.Minute), fields))
+
+	secret, err := GetSecret(
+		s.env.store.SecretRepo(),
+		s.env.store.EventRepo(),
+		s.env.store.SignerRepo(),
+		s.env.store.DownloadRepo(),
+		s.env.hasher,
+		s.env.encoder,
+		s.env.encryptor,
+		s.env.sealer,
+		s.env.blobs,
+		nil,
+		LockoutOptions{MaxAttempts: 5},
+		time.Minute,
+	)(context.Background(), GetSecretDTO{SecretKey: created.SecretKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Signer == nil || !secret.Signer.Verified || secret.Signer.Nonce == "" {
+		t.Fatalf("signer = %+v, want a verified signer with a nonce", secret.Signer)
+	}
+}
+
+func TestSignedSecretRejectsReplay(t *testing.T) {
+	s := newSignerTest(t)
+	priv, signer := s.register(t, "alice")
+	_, other := s.register(t, "bob")
+
+	fields := []model.SecretField{{Name: "user", Value: "alice"}}
+	dto := signedDTO(priv, signer.Fingerprint, s.nonce(t, signer.Fingerprint, time.Minute), fields)
+	s.env.createSecret(t, nil, dto)
+
+	tests := []struct {
+		name string
+		dto  CreateSecretDTO
+	}{
+		{name: "reused nonce", dto: dto},
+		{name: "no nonce", dto: signedDTO(priv, signer.Fingerprint, "", fields)},
+		{name: "unknown nonce", dto: signedDTO(priv, signer.Fingerprint, "unknown", fields)},
+		{name: "other signer's nonce", dto: signedDTO(priv, signer.Fingerprint, s.nonce(t, other.Fingerprint, time.Minute), fields)},
+		{name: "expired nonce", dto: signedDTO(priv, signer.Fingerprint, s.nonce(t, signer.Fingerprint, -time.Second), fields)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.env.createSecretFunc(nil, cryptor.ModeStandard)(context.Background(), tt.dto)
+			if !errors.Is(err, model.ErrInvalidSecret) {
+				t.Fatalf("expected ErrInvalidSecret, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifySecretSignerAcceptsLegacyStatements(t *testing.T) {
+	s := newSignerTest(t)
+	priv, signer := s.register(t, "alice")
+
+	stmt := signature.Statement{
+		PayloadType: string(model.PayloadText),
+		Content:     []byte("hello"),
+		TTL:         1,
+	}
+
+	res, err := verifySecretSigner(context.Background(), s.env.store.SignerRepo(), model.Secret{
+		PayloadType: model.PayloadText,
+		Message:     []byte("hello"),
+		Signature: &model.SecretSignature{
+			Fingerprint: signer.Fingerprint,
+			Value:       signature.Sign(priv, stmt.LegacyBytes()),
+			TTL:         1,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.Verified {
+		t.Fatal("legacy statement did not verify")
+	}
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+)
+
+func wrongSigningHalves(t *testing.T, env *testEnv, secretKey string, n int) []string {
+	t.Helper()
+
+	accessKey, signingKey := decodeTestSecretKey(t, env, secretKey)
+
+	keys := make([]string, 0, n)
+	for i := 1; len(keys) < n; i++ {
+		wrong := []byte(signingKey)
+		wrong[len(wrong)-2] ^= byte(i >> 8)
+		wrong[len(wrong)-1] ^= byte(i)
+		if strings.Contains(string(wrong), "$") {
+			continue
+		}
+
+		key, err := env.encoder.Encode([]byte(accessKey + "$" + string(wrong)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys = append(keys, string(key))
+	}
+
+	return keys
+}
+
+func TestGetSecretRejectsWrongSigningHalves(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	getSecret := env.getSecretFunc(LockoutOptions{})
+
+	created := env.createSecret(t, nil, CreateSecretDTO{Message: []byte("key check"), TTL: 1})
+	accessKey, _ := decodeTestSecretKey(t, env, created.SecretKey)
+
+	wrongKeys := wrongSigningHalves(t, env, created.SecretKey, 512)
+	for _, key := range wrongKeys {
+		_, err := getSecret(ctx, GetSecretDTO{SecretKey: key})
+		if !errors.Is(err, model.ErrSecretNotFound) {
+			t.Fatalf("GetSecret() with a wrong signing half error = %v, want %v", err, model.ErrSecretNotFound)
+		}
+	}
+
+	stored, err := env.store.SecretRepo().GetSecret(ctx, accessKey)
+	if err != nil {
+		t.Fatalf("secret was consumed by a wrong signing half: %v", err)
+	}
+	if stored.FailedAttempts != len(wrongKeys) {
+		t.Fatalf("failed attempts = %d, want %d", stored.FailedAttempts, len(wrongKeys))
+	}
+
+	secret, err := getSecret(ctx, GetSecretDTO{SecretKey: created.SecretKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Message) != "key check" {
+		t.Fatalf("message = %q", secret.Message)
+	}
+}
+
+func TestGetSecretLocksOutWrongSigningHalves(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	getSecret := env.getSecretFunc(LockoutOptions{MaxAttempts: 3})
+
+	created := env.createSecret(t, nil, CreateSecretDTO{Message: []byte("key check"), TTL: 1})
+
+	for _, key := range wrongSigningHalves(t, env, created.SecretKey, 3) {
+		_, err := getSecret(ctx, GetSecretDTO{SecretKey: key})
+		if !errors.Is(err, model.ErrSecretNotFound) {
+			t.Fatalf("GetSecret() with a wrong signing half error = %v, want %v", err, model.ErrSecretNotFound)
+		}
+	}
+
+	_, err := getSecret(ctx, GetSecretDTO{SecretKey: created.SecretKey})
+	if !errors.Is(err, model.ErrSecretNotFound) {
+		t.Fatalf("GetSecret() after lockout error = %v, want %v", err, model.ErrSecretNotFound)
+	}
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/passhash"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+)
+
+type GetSecretStatusDTO struct {
+	StatusKey string
+}
+
+type SecretStatusDTO struct {
+	Events         []model.SecretEvent
+	Replied        bool
+	ReplySecretKey string
+}
+
+func GetSecretStatus(
+	statusRepo *storage.StatusRepository,
+	eventRepo *storage.EventRepository,
+	hasher passhash.Hasher,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+) UseCaseFunc[GetSecretStatusDTO, SecretStatusDTO] {
+	return func(ctx context.Context, dto GetSecretStatusDTO) (SecretStatusDTO, error) {
+		const op = "usecase.GetSecretStatus"
+		var err error
+		now := time.Now()
+
+		decodedStatusKey, err := encoder.Decode([]byte(dto.StatusKey))
+		if err != nil {
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w: invalid status key: %w", op, model.ErrStatusNotFound, err)
+		}
+
+		statusKeyParts := bytes.Split(decodedStatusKey, []byte("$"))
+		if len(statusKeyParts) != 2 {
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w: invalid status key", op, model.ErrStatusNotFound)
+		}
+
+		statusKey := statusKeyParts[0]
+		statusSecret := statusKeyParts[1]
+
+		status, err := statusRepo.GetStatus(ctx, string(statusKey))
+		if err != nil {
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if status.ExpiredAt.Unix() < now.Unix() && status.ExpiredAt.Unix() > status.CreatedAt.Unix() {
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w", op, model.ErrStatusNotFound)
+		}
+
+		err = hasher.Compare(statusSecret, status.StatusSecret)
+		if err != nil {
+			if errors.Is(err, passhash.ErrWrongPassword) {
+				return SecretStatusDTO{}, fmt.Errorf("%s: %w", op, model.ErrStatusNotFound)
+			}
+
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		events, err := eventRepo.FindEvents(ctx, status.AccessKey)
+		if err != nil {
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		replyEvents, err := eventRepo.FindEvents(ctx, status.ReplyAccessKey)
+		if err != nil {
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		replied := slices.ContainsFunc(replyEvents, func(event model.SecretEvent) bool {
+			return event.Kind == model.SecretCreated
+		})
+		if !replied {
+			return SecretStatusDTO{
+				Events: events,
+			}, nil
+		}
+
+		replySecretKey, err := encryptor.Decrypt([]byte(status.ReplySecretKey), statusSecret)
+		if err != nil {
+			return SecretStatusDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return SecretStatusDTO{
+			Events:         events,
+			Replied:        true,
+			ReplySecretKey: string(replySecretKey),
+		}, nil
+	}
+}
+
+func createReplyChannel(
+	ctx context.Context,
+	requestRepo *storage.RequestRepository,
+	statusRepo *storage.StatusRepository,
+	hasher passhash.Hasher,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	secret model.Secret,
+) (string, string, error) {
+	const op = "createReplyChannel"
+	var err error
+
+	requestKey := randstr.SecureGen(16)
+	replyAccessKey := []byte(randstr.SecureGen(8))
+	replySigningKey := []byte(randstr.SecureGen(16))
+
+	replySecretKey, err := encoder.Encode(bytes.Join(
+		[][]byte{replyAccessKey, replySigningKey[:6]},
+		[]byte("$"),
+	))
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = requestRepo.SaveRequest(ctx, model.SecretRequest{
+		CreatedAt:  secret.CreatedAt,
+		ExpiredAt:  secret.ExpiredAt,
+		RequestKey: requestKey,
+		AccessKey:  string(replyAccessKey),
+		SigningKey: string(replySigningKey),
+		Note:       "reply to sender",
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	statusKey := []byte(randstr.SecureGen(8))
+	statusSecret := []byte(randstr.SecureGen(16))
+
+	encodedStatusKey, err := encoder.Encode(bytes.Join(
+		[][]byte{statusKey, statusSecret},
+		[]byte("$"),
+	))
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	hashedStatusSecret, err := hasher.Generate(statusSecret)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	encryptedReplySecretKey, err := encryptor.Encrypt(replySecretKey, statusSecret)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = statusRepo.SaveStatus(ctx, model.SecretStatus{
+		CreatedAt:      secret.CreatedAt,
+		ExpiredAt:      secret.ExpiredAt,
+		StatusKey:      string(statusKey),
+		StatusSecret:   hashedStatusSecret,
+		AccessKey:      secret.AccessKey,
+		ReplyAccessKey: string(replyAccessKey),
+		ReplySecretKey: string(encryptedReplySecretKey),
+	})
+	if err != nil {
+		_ = requestRepo.RemoveRequest(ctx, requestKey)
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return requestKey, string(encodedStatusKey), nil
+}
+
+func removeReplyChannel(
+	ctx context.Context,
+	requestRepo *storage.RequestRepository,
+	statusRepo *storage.StatusRepository,
+	secret model.Secret,
+) error {
+	const op = "removeReplyChannel"
+
+	if secret.ReplyKey == "" {
+		return nil
+	}
+
+	err := errors.Join(
+		statusRepo.RemoveSecretStatuses(ctx, secret.AccessKey),
+		requestRepo.RemoveRequest(ctx, secret.ReplyKey),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+)
+
+func TestCreateSecretRemovesReplyChannelOnFailure(t *testing.T) {
+	env := newTestEnv(t)
+
+	db, err := sql.Open("sqlite3", env.database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec(`
+        CREATE TRIGGER fail_secret_insert BEFORE INSERT ON secrets
+        BEGIN
+            SELECT RAISE(ABORT, 'insert failed');
+        END
+    `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.createSecretFunc(nil, cryptor.ModeStandard)(context.Background(), CreateSecretDTO{
+		Message:    []byte("with reply"),
+		TTL:        1,
+		AllowReply: true,
+	})
+	if err == nil {
+		t.Fatal("expected the secret insert to fail")
+	}
+
+	for _, table := range []string{"secret_requests", "secret_statuses"} {
+		var count int
+		err = db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count != 0 {
+			t.Fatalf("%s has %d orphan rows", table, count)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+)
+
+type UploadOptions struct {
+	MaxSize int64
+	TTL     time.Duration
+}
+
+type CreateUploadDTO struct {
+	Length      int64
+	Name        string
+	ContentType string
+}
+
+type CreatedUploadDTO struct {
+	UploadKey string
+	ExpiredAt time.Time
+}
+
+func CreateUpload(
+	uploadRepo *storage.UploadRepository,
+	encoder cryptor.Encoder,
+	opts UploadOptions,
+) UseCaseFunc[CreateUploadDTO, CreatedUploadDTO] {
+	return func(ctx context.Context, dto CreateUploadDTO) (CreatedUploadDTO, error) {
+		const op = "usecase.CreateUpload"
+		var err error
+		now := time.Now()
+
+		if dto.Length < 0 {
+			return CreatedUploadDTO{}, fmt.Errorf("%s: %w", op, model.ErrInvalidSecret)
+		}
+
+		if dto.Length > opts.MaxSize {
+			return CreatedUploadDTO{}, fmt.Errorf("%s: %w", op, model.ErrUploadTooLarge)
+		}
+
+		accessKey := []byte(randstr.SecureGen(8))
+		signingKey := []byte(randstr.SecureGen(16))
+
+		uploadKey, err := encoder.Encode(bytes.Join(
+			[][]byte{accessKey, signingKey[:6]},
+			[]byte("$"),
+		))
+		if err != nil {
+			return CreatedUploadDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		upload := model.Upload{
+			CreatedAt:   now,
+			ExpiredAt:   now.Add(opts.TTL),
+			AccessKey:   string(accessKey),
+			SigningKey:  string(signingKey[6:]),
+			Name:        dto.Name,
+			ContentType: dto.ContentType,
+			Length:      dto.Length,
+		}
+
+		_, err = uploadRepo.SaveUpload(ctx, upload)
+		if err != nil {
+			return CreatedUploadDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return CreatedUploadDTO{
+			UploadKey: string(uploadKey),
+			ExpiredAt: upload.ExpiredAt,
+		}, nil
+	}
+}
+
+type GetUploadDTO struct {
+	UploadKey string
+}
+
+func GetUpload(
+	uploadRepo *storage.UploadRepository,
+	encoder cryptor.Encoder,
+) UseCaseFunc[GetUploadDTO, model.Upload] {
+	return func(ctx context.Context, dto GetUploadDTO) (model.Upload, error) {
+		const op = "usecase.GetUpload"
+
+		upload, _, err := findUpload(ctx, uploadRepo, encoder, dto.UploadKey)
+		if err != nil {
+			return model.Upload{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return upload, nil
+	}
+}
+
+type WriteUploadDTO struct {
+	UploadKey string
+	Offset    int64
+	Data      io.Reader
+}
+
+func WriteUpload(
+	uploadRepo *storage.UploadRepository,
+	encoder cryptor.Encoder,
+	streamer cryptor.StreamEncryptor,
+	blobs blobstore.Store,
+) UseCaseFunc[WriteUploadDTO, int64] {
+	return func(ctx context.Context, dto WriteUploadDTO) (int64, error) {
+		const op = "usecase.WriteUpload"
+		var err error
+
+		upload, key, err := findUpload(ctx, uploadRepo, encoder, dto.UploadKey)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if dto.Offset != upload.Offset {
+			return upload.Offset, fmt.Errorf("%s: %w", op, model.ErrUploadOffsetMismatch)
+		}
+
+		ctx = context.WithoutCancel(ctx)
+		remaining := upload.Length - upload.Offset
+		blobKey := fmt.Sprintf("upload-%s-%s", upload.AccessKey, randstr.SecureGen(16))
+
+		data := &partialReader{r: io.LimitReader(dto.Data, remaining+1)}
+		size, err := putEncryptedBlob(ctx, blobs, streamer, blobKey, key, data)
+		if err != nil {
+			return upload.Offset, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if size > remaining {
+			_ = blobs.Delete(ctx, blobKey)
+			return upload.Offset, fmt.Errorf("%s: %w", op, model.ErrUploadTooLarge)
+		}
+
+		if size == 0 {
+			_ = blobs.Delete(ctx, blobKey)
+			return upload.Offset, nil
+		}
+
+		err = uploadRepo.AppendUploadChunk(ctx, upload, upload.Offset+size, blobKey)
+		if err != nil {
+			_ = blobs.Delete(ctx, blobKey)
+			return upload.Offset, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if data.err != nil {
+			return upload.Offset + size, fmt.Errorf("%s: %w", op, data.err)
+		}
+
+		return upload.Offset + size, nil
+	}
+}
+
+type DeleteUploadDTO struct {
+	UploadKey string
+}
+
+func DeleteUpload(
+	uploadRepo *storage.UploadRepository,
+	encoder cryptor.Encoder,
+	blobs blobstore.Store,
+) UseCaseFunc[DeleteUploadDTO, struct{}] {
+	return func(ctx context.Context, dto DeleteUploadDTO) (struct{}, error) {
+		const op = "usecase.DeleteUpload"
+
+		upload, _, err := findUpload(ctx, uploadRepo, encoder, dto.UploadKey)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = removeUpload(ctx, uploadRepo, blobs, upload)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return struct{}{}, nil
+	}
+}
+
+func PurgeExpiredUploads(
+	uploadRepo *storage.UploadRepository,
+	blobs blobstore.Store,
+) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredUploads"
+		var err error
+
+		uploads, err := uploadRepo.FindExpiredUploads(ctx, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var (
+			purged = 0
+			errs   = make([]error, 0)
+		)
+		for _, upload := range uploads {
+			err = removeUpload(ctx, uploadRepo, blobs, upload)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			purged++
+		}
+
+		if len(errs) > 0 {
+			return purged, fmt.Errorf("%s: %w", op, errors.Join(errs...))
+		}
+
+		return purged, nil
+	}
+}
+
+func findUpload(
+	ctx context.Context,
+	uploadRepo *storage.UploadRepository,
+	encoder cryptor.Encoder,
+	uploadKey string,
+) (model.Upload, []byte, error) {
+	decodedUploadKey, err := encoder.Decode([]byte(uploadKey))
+	if err != nil {
+		return model.Upload{}, nil, model.ErrUploadNotFound
+	}
+
+	uploadKeyParts := bytes.Split(decodedUploadKey, []byte("$"))
+	if len(uploadKeyParts) != 2 {
+		return model.Upload{}, nil, model.ErrUploadNotFound
+	}
+
+	upload, err := uploadRepo.GetUpload(ctx, string(uploadKeyParts[0]))
+	if err != nil {
+		return model.Upload{}, nil, err
+	}
+
+	if upload.ExpiredAt.Before(time.Now()) {
+		return model.Upload{}, nil, model.ErrUploadNotFound
+	}
+
+	key := append(uploadKeyParts[1], []byte(upload.SigningKey)...)
+
+	return upload, key, nil
+}
+
+func resolveUploads(
+	ctx context.Context,
+	uploadRepo *storage.UploadRepository,
+	encoder cryptor.Encoder,
+	streamer cryptor.StreamEncryptor,
+	blobs blobstore.Store,
+	uploadKeys []string,
+) ([]model.Upload, []AttachmentDTO, error) {
+	uploads := make([]model.Upload, 0, len(uploadKeys))
+	attachments := make([]AttachmentDTO, 0, len(uploadKeys))
+	for _, uploadKey := range uploadKeys {
+		upload, key, err := findUpload(ctx, uploadRepo, encoder, uploadKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if upload.Offset != upload.Length {
+			return nil, nil, model.ErrUploadIncomplete
+		}
+
+		uploads = append(uploads, upload)
+		attachments = append(attachments, AttachmentDTO{
+			Name:        upload.Name,
+			ContentType: upload.ContentType,
+			Data: &uploadReader{
+				ctx:      ctx,
+				blobs:    blobs,
+				streamer: streamer,
+				key:      key,
+				chunks:   upload.Chunks,
+			},
+		})
+	}
+
+	return uploads, attachments, nil
+}
+
+func removeUpload(
+	ctx context.Context,
+	uploadRepo *storage.UploadRepository,
+	blobs blobstore.Store,
+	upload model.Upload,
+) error {
+	err := uploadRepo.RemoveUpload(ctx, upload.AccessKey)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+	for _, chunk := range upload.Chunks {
+		err = blobs.Delete(ctx, chunk)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type partialReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *partialReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+type uploadReader struct {
+	ctx      context.Context
+	blobs    blobstore.Store
+	streamer cryptor.StreamEncryptor
+	key      []byte
+	chunks   []string
+
+	blob    io.ReadCloser
+	current io.Reader
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+
+			blob, err := r.blobs.Get(r.ctx, r.chunks[0])
+			if err != nil {
+				return 0, err
+			}
+			r.chunks = r.chunks[1:]
+
+			r.current, err = r.streamer.DecryptReader(blob, r.key)
+			if err != nil {
+				_ = blob.Close()
+				return 0, err
+			}
+			r.blob = blob
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			_ = r.blob.Close()
+			r.blob, r.current = nil, nil
+
+			if n > 0 {
+				return n, nil
+			}
+
+			continue
+		}
+
+		return n, err
+	}
+}
@@ -3,48 +3,79 @@ package usecase
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/netip"
+	"sync"
 	"time"
 
+	"github.com/protomem/secrets-keeper/internal/blobstore"
 	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/mailer"
 	"github.com/protomem/secrets-keeper/internal/model"
 	"github.com/protomem/secrets-keeper/internal/passhash"
 	"github.com/protomem/secrets-keeper/internal/storage"
 	"github.com/protomem/secrets-keeper/pkg/randstr"
+	"github.com/protomem/secrets-keeper/pkg/realip"
+	"github.com/protomem/secrets-keeper/pkg/signature"
 )
 
 type UseCaseFunc[I any, O any] func(context.Context, I) (O, error)
 
+var (
+	keyCheckLabel = []byte("secrets-keeper signing key check")
+
+	errWrongSigningKey = fmt.Errorf("%w: wrong signing key", model.ErrSecretNotFound)
+)
+
+type LockoutOptions struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
 type GetSecretDTO struct {
 	SecretKey    string
-	SecretPhrase string
+	SecretPhrase []byte
+	ClientIP     netip.Addr
+	E2E          bool
 }
 
 func GetSecret(
 	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	signerRepo *storage.SignerRepository,
+	downloadRepo *storage.DownloadRepository,
 	hasher passhash.Hasher,
 	encoder cryptor.Encoder,
 	encryptor cryptor.Encryptor,
+	sealer *envelope.Envelope,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	lockout LockoutOptions,
+	downloadTTL time.Duration,
 ) UseCaseFunc[GetSecretDTO, model.Secret] {
-	return func(ctx context.Context, dto GetSecretDTO) (model.Secret, error) {
+	return func(ctx context.Context, dto GetSecretDTO) (_ model.Secret, err error) {
 		const op = "usecase.GetSecret"
-		var err error
 		now := time.Now()
 
-		decodedSecretKey, err := encoder.Decode([]byte(dto.SecretKey))
+		phraseCompared := false
+		defer func() {
+			if err != nil && !phraseCompared {
+				equalizePhraseCompare(hasher, dto.SecretPhrase)
+			}
+		}()
+
+		accessKey, signingKey, err := decodeSecretKey(encoder, dto.SecretKey)
 		if err != nil {
 			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
 		}
 
-		secretKeyParts := bytes.Split(decodedSecretKey, []byte("$"))
-		if len(secretKeyParts) != 2 {
-			return model.Secret{}, fmt.Errorf("%s: %w", op, errors.New("invalid secret key"))
-		}
-
-		accessKey := secretKeyParts[0]
-		signingKey := secretKeyParts[1]
-
 		secret, err := secretRepo.GetSecret(ctx, string(accessKey))
 		if err != nil {
 			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
@@ -54,30 +85,80 @@ func GetSecret(
 			return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
 		}
 
+		if (secret.PayloadType == model.PayloadE2E) != dto.E2E || secret.ShareThreshold > 0 {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		if (secret.SecretPhrase == "" && secret.PhraseVerifier != nil) || hasPasskeys(secret) || hasRecipientEmail(secret) {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+		}
+
+		err = checkClientIP(secret.AllowedCIDRs, dto.ClientIP)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		country, err := checkClientCountry(locator, secret.AllowedCountries, secret.DeniedCountries, dto.ClientIP)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = checkSigningKey(ctx, secretRepo, eventRepo, blobs, lockout, encryptor, secret, signingKey)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
 		if secret.SecretPhrase != "" {
-			if dto.SecretPhrase == "" {
+			if len(dto.SecretPhrase) == 0 {
 				return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
 			}
 
-			err = hasher.Compare(dto.SecretPhrase, secret.SecretPhrase)
+			var failedAttempts int
+			failedAttempts, err = secretRepo.ReserveAttempt(ctx, secret.AccessKey, lockout.MaxAttempts)
+			if err != nil {
+				return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			err = waitAttemptDelay(ctx, lockout, failedAttempts-1)
+			if err == nil {
+				phraseCompared = true
+				err = hasher.Compare(dto.SecretPhrase, secret.SecretPhrase)
+			}
 			if err != nil {
 				if errors.Is(err, passhash.ErrWrongPassword) {
+					err = rejectAttempt(ctx, secretRepo, eventRepo, blobs, lockout, secret, failedAttempts)
+					if err != nil {
+						return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+					}
+
 					return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
 				}
 
+				_ = secretRepo.ReleaseAttempt(ctx, secret.AccessKey)
+				return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			err = secretRepo.ReleaseAttempt(ctx, secret.AccessKey)
+			if err != nil {
 				return model.Secret{}, fmt.Errorf("%s: %w", op, err)
 			}
 		}
 
-		signingKey = append(signingKey, []byte(secret.SigningKey)...)
-		decryptedMessage, err := encryptor.Decrypt([]byte(secret.Message), []byte(signingKey))
+		err = checkSecretAvailable(secret, now)
 		if err != nil {
 			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
 		}
 
-		secret.Message = string(decryptedMessage)
+		key, err := lockSigningKey(signingKey, secret.SigningKey)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer key.Destroy()
 
-		err = secretRepo.RemoveSecret(ctx, string(accessKey))
+		secret, err = revealSecret(
+			ctx, secretRepo, eventRepo, signerRepo, downloadRepo, encoder, encryptor, sealer, downloadTTL,
+			secret, key.Bytes(), country,
+		)
 		if err != nil {
 			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
 		}
@@ -86,58 +167,665 @@ func GetSecret(
 	}
 }
 
+func revealSecret(
+	ctx context.Context,
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	signerRepo *storage.SignerRepository,
+	downloadRepo *storage.DownloadRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	sealer *envelope.Envelope,
+	downloadTTL time.Duration,
+	secret model.Secret,
+	signingKey []byte,
+	country string,
+) (model.Secret, error) {
+	const op = "revealSecret"
+	var err error
+	now := time.Now()
+
+	decryptedMessage, err := encryptor.Decrypt(secret.Message, signingKey)
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer cryptor.Wipe(decryptedMessage)
+
+	openedMessage, err := openMessage(sealer, secret, decryptedMessage)
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer cryptor.Wipe(openedMessage)
+
+	secret.Message, secret.Fields, err = decodePayload(secret.PayloadType, openedMessage)
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret.Signer, err = verifySecretSigner(ctx, signerRepo, secret)
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if secret.ReplyKey != "" {
+		replyKey, err := encoder.Encode([]byte(secret.ReplyKey))
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		secret.ReplyKey = string(replyKey)
+	}
+
+	secret.Attachments, err = offerAttachments(ctx, downloadRepo, encoder, signingKey, downloadTTL, secret.Attachments)
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = secretRepo.RemoveSecret(ctx, secret.AccessKey)
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+		CreatedAt: now,
+		AccessKey: secret.AccessKey,
+		Kind:      model.SecretRead,
+		Details:   readDetails(country),
+	})
+	if err != nil {
+		return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return secret, nil
+}
+
+func findGatedSecret(
+	ctx context.Context,
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	lockout LockoutOptions,
+	secretKey string,
+	clientIP netip.Addr,
+	gated func(model.Secret) bool,
+) (model.Secret, []byte, string, error) {
+	const op = "findGatedSecret"
+	now := time.Now()
+
+	accessKey, signingKey, err := decodeSecretKey(encoder, secretKey)
+	if err != nil {
+		return model.Secret{}, nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret, err := secretRepo.GetSecret(ctx, string(accessKey))
+	if err != nil {
+		return model.Secret{}, nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if secret.ExpiredAt.Unix() < now.Unix() && secret.ExpiredAt.Unix() > secret.CreatedAt.Unix() {
+		return model.Secret{}, nil, "", fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+	}
+
+	if !gated(secret) || secret.PayloadType == model.PayloadE2E || secret.ShareThreshold > 0 {
+		return model.Secret{}, nil, "", fmt.Errorf("%s: %w", op, model.ErrSecretNotFound)
+	}
+
+	err = checkClientIP(secret.AllowedCIDRs, clientIP)
+	if err != nil {
+		return model.Secret{}, nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	country, err := checkClientCountry(locator, secret.AllowedCountries, secret.DeniedCountries, clientIP)
+	if err != nil {
+		return model.Secret{}, nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = checkSigningKey(ctx, secretRepo, eventRepo, blobs, lockout, encryptor, secret, signingKey)
+	if err != nil {
+		return model.Secret{}, nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return secret, signingKey, country, nil
+}
+
+func checkSecretAvailable(secret model.Secret, now time.Time) error {
+	if now.Before(secret.AvailableFrom) {
+		return &model.SecretNotYetAvailableError{
+			AvailableFrom: secret.AvailableFrom,
+		}
+	}
+
+	return nil
+}
+
+func openMessage(sealer *envelope.Envelope, secret model.Secret, data []byte) ([]byte, error) {
+	if !secret.Sealed {
+		return sealer.OpenLegacy(data)
+	}
+
+	return sealer.Open(data)
+}
+
+func decodeSecretKey(encoder cryptor.Encoder, secretKey string) ([]byte, []byte, error) {
+	decoded, err := encoder.Decode([]byte(secretKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid secret key: %w", model.ErrSecretNotFound, err)
+	}
+
+	parts := bytes.Split(decoded, []byte("$"))
+	if len(parts) != 2 {
+		return nil, nil, fmt.Errorf("%w: invalid secret key", model.ErrSecretNotFound)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+func checkSigningKey(
+	ctx context.Context,
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	blobs blobstore.Store,
+	lockout LockoutOptions,
+	encryptor cryptor.Encryptor,
+	secret model.Secret,
+	signingKey []byte,
+) error {
+	const op = "checkSigningKey"
+
+	err := verifySigningKey(encryptor, secret, signingKey)
+	if errors.Is(err, errWrongSigningKey) {
+		rejectErr := registerFailedAttempt(ctx, secretRepo, eventRepo, blobs, lockout, secret)
+		if rejectErr != nil {
+			return fmt.Errorf("%s: %w", op, rejectErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func verifySigningKey(encryptor cryptor.Encryptor, secret model.Secret, signingKey []byte) error {
+	key, err := cryptor.NewBufferFrom(signingKey)
+	if err != nil {
+		return err
+	}
+	defer key.Destroy()
+
+	_, err = key.WriteString(secret.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	if secret.KeyCheck != "" {
+		if !hmac.Equal([]byte(signingKeyCheck(key.Bytes())), []byte(secret.KeyCheck)) {
+			return errWrongSigningKey
+		}
+
+		return nil
+	}
+
+	decrypted, err := encryptor.Decrypt(secret.Message, key.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errWrongSigningKey, err)
+	}
+	cryptor.Wipe(decrypted)
+
+	return nil
+}
+
+func signingKeyCheck(signingKey []byte) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write(keyCheckLabel)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func lockSigningKey(signingKey []byte, storedSigningKey string) (*cryptor.Buffer, error) {
+	key, err := cryptor.NewBufferFrom(signingKey)
+	cryptor.Wipe(signingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = key.WriteString(storedSigningKey)
+	if err != nil {
+		key.Destroy()
+		return nil, err
+	}
+
+	return key, nil
+}
+
+var dummyPhraseHashes sync.Map
+
+func equalizePhraseCompare(hasher passhash.Hasher, phrase []byte) {
+	hash, ok := dummyPhraseHashes.Load(hasher)
+	if !ok {
+		generated, err := hasher.Generate([]byte(randstr.SecureGen(16)))
+		if err != nil {
+			return
+		}
+
+		hash, _ = dummyPhraseHashes.LoadOrStore(hasher, generated)
+	}
+
+	_ = hasher.Compare(phrase, hash.(string))
+}
+
 type CreateSecretDTO struct {
-	Message      string
-	TTL          int64 // in hours
-	SecretPhrase string
+	Message          []byte
+	Fields           []model.SecretField
+	TTL              int64 // in hours
+	SecretPhrase     []byte
+	AvailableFrom    time.Time
+	CheckInInterval  int64 // in hours
+	AllowedCIDRs     []string
+	AllowedCountries []string
+	DeniedCountries  []string
+	AllowReply       bool
+	Attachments      AttachmentSource
+	Uploads          []string
+	Ciphertext       string
+	Recipients       []string
+	Signature        *SignatureDTO
+	Shares           *SharesDTO
+	PhraseVerifier   *PhraseVerifierDTO
+	Passkeys         []PasskeyDTO
+	RecipientEmail   string
+}
+
+type CreatedSecretDTO struct {
+	SecretKey    string
+	ShareKeys    []string
+	CheckInToken string
+	StatusKey    string
 }
 
 func CreateSecret(
 	secretRepo *storage.SecretRepository,
+	requestRepo *storage.RequestRepository,
+	statusRepo *storage.StatusRepository,
+	eventRepo *storage.EventRepository,
+	uploadRepo *storage.UploadRepository,
+	signerRepo *storage.SignerRepository,
 	hasher passhash.Hasher,
 	encoder cryptor.Encoder,
 	encryptor cryptor.Encryptor,
-) UseCaseFunc[CreateSecretDTO, string] {
-	return func(ctx context.Context, dto CreateSecretDTO) (string, error) {
+	streamer cryptor.StreamEncryptor,
+	sealer *envelope.Envelope,
+	kems *cryptor.KEMRegistry,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	sender mailer.Mailer,
+	mode cryptor.Mode,
+) UseCaseFunc[CreateSecretDTO, CreatedSecretDTO] {
+	return func(ctx context.Context, dto CreateSecretDTO) (CreatedSecretDTO, error) {
 		const op = "usecase.CreateSecret"
 		var err error
 		now := time.Now()
+		requestedAvailableFrom := dto.AvailableFrom
+
+		allowedCIDRs := make([]string, 0, len(dto.AllowedCIDRs))
+		for _, cidr := range dto.AllowedCIDRs {
+			prefix, err := realip.ParsePrefix(cidr)
+			if err != nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w: cidr %q: %w", op, model.ErrInvalidSecret, cidr, err)
+			}
+
+			allowedCIDRs = append(allowedCIDRs, prefix.String())
+		}
+
+		if (len(dto.AllowedCountries) > 0 || len(dto.DeniedCountries) > 0) && locator == nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: country restrictions are not configured", op, model.ErrInvalidSecret)
+		}
+
+		allowedCountries, err := normalizeCountries(dto.AllowedCountries)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		deniedCountries, err := normalizeCountries(dto.DeniedCountries)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		checkInInterval := time.Duration(dto.CheckInInterval) * time.Hour
+		if checkInInterval > 0 && dto.AvailableFrom.Before(now.Add(checkInInterval)) {
+			dto.AvailableFrom = now.Add(checkInInterval)
+		}
+
+		expiredAt := now.Add(time.Duration(dto.TTL) * time.Hour)
+		if dto.TTL > 0 && !dto.AvailableFrom.Before(expiredAt) {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: available after expiration", op, model.ErrInvalidSecret)
+		}
+
+		if len(dto.SecretPhrase) > 0 && dto.PhraseVerifier != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: both phrase and phrase verifier are set", op, model.ErrInvalidSecret)
+		}
+
+		if mode == cryptor.ModeFIPS && (dto.PhraseVerifier != nil || len(dto.Recipients) > 0) {
+			return CreatedSecretDTO{}, fmt.Errorf(
+				"%s: %w: %w: phrase verifiers and recipients", op, model.ErrInvalidSecret, cryptor.ErrNotApproved,
+			)
+		}
+
+		if dto.Shares != nil && (len(dto.SecretPhrase) > 0 || dto.PhraseVerifier != nil || dto.Ciphertext != "") {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: shares cannot be combined with a phrase or ciphertext", op, model.ErrInvalidSecret)
+		}
+
+		if len(dto.Passkeys) > 0 && (len(dto.SecretPhrase) > 0 || dto.PhraseVerifier != nil || dto.Shares != nil || dto.Ciphertext != "") {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: passkeys cannot be combined with a phrase, shares or ciphertext", op, model.ErrInvalidSecret)
+		}
+
+		passkeys, err := newPasskeys(dto.Passkeys)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var recipientEmail string
+		if dto.RecipientEmail != "" {
+			if len(dto.SecretPhrase) > 0 || dto.PhraseVerifier != nil || len(dto.Passkeys) > 0 || dto.Shares != nil || dto.Ciphertext != "" {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w: recipient email cannot be combined with other verification", op, model.ErrInvalidSecret)
+			}
+
+			if sender == nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w: email is not configured", op, model.ErrInvalidSecret)
+			}
+
+			recipientEmail, err = normalizeEmail(dto.RecipientEmail)
+			if err != nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w: recipient email: %w", op, model.ErrInvalidSecret, err)
+			}
+		}
+
+		uploads, uploadAttachments, err := resolveUploads(ctx, uploadRepo, encoder, streamer, blobs, dto.Uploads)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
 
 		accessKey := []byte(randstr.Gen(8))
-		signingKey := []byte(randstr.Gen(16))
 
-		secretKey, err := encoder.Encode(bytes.Join(
+		key, err := cryptor.NewBuffer(16)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer key.Destroy()
+
+		signingKey := key.Bytes()
+		randstr.SecureFill(signingKey)
+
+		rawSecretKey := bytes.Join(
 			[][]byte{[]byte(accessKey), signingKey[:6]},
 			[]byte("$"),
-		))
+		)
+		secretKey, err := encoder.Encode(rawSecretKey)
+		cryptor.Wipe(rawSecretKey)
 		if err != nil {
-			return "", fmt.Errorf("%s: %w", op, err)
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
 		}
 
-		encryptedMessage, err := encryptor.Encrypt([]byte(dto.Message), signingKey)
+		storedSigningKey := signingKey[6:]
+
+		var shareKeys, shareDigests []string
+		if dto.Shares != nil {
+			shareKeys, shareDigests, err = splitSigningKey(encoder, accessKey, signingKey, *dto.Shares)
+			if err != nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			secretKey, storedSigningKey = nil, nil
+		}
+
+		payloadType, payload, err := encodePayload(dto.Message, dto.Fields)
 		if err != nil {
-			return "", fmt.Errorf("%s: %w", op, err)
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
 		}
 
-		if dto.SecretPhrase != "" {
-			dto.SecretPhrase, err = hasher.Generate(dto.SecretPhrase)
+		if dto.Ciphertext != "" {
+			payloadType, payload, err = encodeE2EPayload(dto)
 			if err != nil {
-				return "", fmt.Errorf("%s: %w", op, err)
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
 			}
 		}
 
-		_, err = secretRepo.SaveSecret(ctx, model.Secret{
-			CreatedAt:    now,
-			ExpiredAt:    now.Add(time.Duration(dto.TTL) * time.Hour),
-			AccessKey:    string(accessKey),
-			SigningKey:   string(signingKey[6:]),
-			SecretPhrase: dto.SecretPhrase,
-			Message:      string(encryptedMessage),
+		if len(dto.Recipients) > 0 {
+			payloadType, payload, err = encodeRecipientsPayload(kems, dto)
+			if err != nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		signedMessage, signedFields, err := decodePayload(payloadType, payload)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer cryptor.Wipe(signedMessage)
+
+		if payloadType == model.PayloadAge {
+			signedMessage = dto.Message
+		}
+
+		secretSignature, err := signSecret(ctx, signerRepo, dto.Signature, signature.Statement{
+			PayloadType:   string(payloadType),
+			Content:       statementContent(payloadType, signedMessage, signedFields),
+			TTL:           dto.TTL,
+			AvailableFrom: requestedAvailableFrom,
 		})
 		if err != nil {
-			return "", fmt.Errorf("%s: %w", op, err)
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
 		}
 
-		return string(secretKey), nil
+		sealedPayload, err := sealer.Seal(payload)
+		cryptor.Wipe(payload)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		encryptedMessage, err := encryptor.Encrypt(sealedPayload, signingKey)
+		cryptor.Wipe(sealedPayload)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var phraseVerifier *model.PhraseVerifier
+		if mode != cryptor.ModeFIPS {
+			phraseVerifier, err = newPhraseVerifier(dto.SecretPhrase, dto.PhraseVerifier)
+			if err != nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		var hashedPhrase string
+		if len(dto.SecretPhrase) > 0 {
+			hashedPhrase, err = hasher.Generate(dto.SecretPhrase)
+			if err != nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		var checkInToken, hashedCheckInToken []byte
+		if checkInInterval > 0 {
+			checkInToken, hashedCheckInToken, err = generateCheckInToken(hasher, encoder, accessKey)
+			if err != nil {
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		secret := model.Secret{
+			CreatedAt:        now,
+			ExpiredAt:        expiredAt,
+			AvailableFrom:    dto.AvailableFrom,
+			AccessKey:        string(accessKey),
+			SigningKey:       string(storedSigningKey),
+			KeyCheck:         signingKeyCheck(signingKey),
+			SecretPhrase:     hashedPhrase,
+			PhraseVerifier:   phraseVerifier,
+			Passkeys:         passkeys,
+			RecipientEmail:   recipientEmail,
+			CheckInInterval:  checkInInterval,
+			CheckInToken:     string(hashedCheckInToken),
+			AllowedCIDRs:     allowedCIDRs,
+			AllowedCountries: allowedCountries,
+			DeniedCountries:  deniedCountries,
+			PayloadType:      payloadType,
+			Message:          encryptedMessage,
+			Signature:        secretSignature,
+			Sealed:           true,
+		}
+
+		if dto.Shares != nil {
+			secret.ShareThreshold = dto.Shares.Threshold
+			secret.ShareTotal = dto.Shares.Total
+			secret.ShareWindow = dto.Shares.Window
+			secret.ShareDigests = shareDigests
+		}
+
+		secret.Attachments, err = storeAttachments(
+			ctx, blobs, streamer, secret.AccessKey, signingKey,
+			dto.Attachments, uploadAttachments,
+		)
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var statusKey string
+		if dto.AllowReply {
+			secret.ReplyKey, statusKey, err = createReplyChannel(
+				ctx, requestRepo, statusRepo, hasher, encoder, encryptor, secret,
+			)
+			if err != nil {
+				_ = deleteAttachments(ctx, blobs, secret.Attachments)
+				return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		_, err = secretRepo.SaveSecret(ctx, secret)
+		if err != nil {
+			_ = deleteAttachments(ctx, blobs, secret.Attachments)
+			_ = removeReplyChannel(ctx, requestRepo, statusRepo, secret)
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, upload := range uploads {
+			_ = removeUpload(ctx, uploadRepo, blobs, upload)
+		}
+
+		_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+			CreatedAt: now,
+			AccessKey: string(accessKey),
+			Kind:      model.SecretCreated,
+		})
+		if err != nil {
+			return CreatedSecretDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return CreatedSecretDTO{
+			SecretKey:    string(secretKey),
+			ShareKeys:    shareKeys,
+			CheckInToken: string(checkInToken),
+			StatusKey:    statusKey,
+		}, nil
 	}
 }
+
+func waitAttemptDelay(ctx context.Context, lockout LockoutOptions, failedAttempts int) error {
+	if failedAttempts == 0 || lockout.BaseDelay <= 0 {
+		return nil
+	}
+
+	delay := lockout.BaseDelay
+	for i := 1; i < failedAttempts && delay < lockout.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, lockout.MaxDelay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func registerFailedAttempt(
+	ctx context.Context,
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	blobs blobstore.Store,
+	lockout LockoutOptions,
+	secret model.Secret,
+) error {
+	const op = "registerFailedAttempt"
+	var err error
+
+	failedAttempts, err := secretRepo.IncrementFailedAttempts(ctx, secret.AccessKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = rejectAttempt(ctx, secretRepo, eventRepo, blobs, lockout, secret, failedAttempts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func rejectAttempt(
+	ctx context.Context,
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	blobs blobstore.Store,
+	lockout LockoutOptions,
+	secret model.Secret,
+	failedAttempts int,
+) error {
+	const op = "rejectAttempt"
+	var err error
+	now := time.Now()
+	accessKey := secret.AccessKey
+
+	_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+		CreatedAt: now,
+		AccessKey: accessKey,
+		Kind:      model.SecretFailedAttempt,
+		Details:   fmt.Sprintf("attempt %d of %d", failedAttempts, lockout.MaxAttempts),
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if lockout.MaxAttempts <= 0 || failedAttempts < lockout.MaxAttempts {
+		return nil
+	}
+
+	err = secretRepo.RemoveSecret(ctx, accessKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = deleteAttachments(ctx, blobs, secret.Attachments)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = eventRepo.SaveEvent(ctx, model.SecretEvent{
+		CreatedAt: now,
+		AccessKey: accessKey,
+		Kind:      model.SecretDestroyed,
+		Details:   "too many failed attempts",
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/blobstore"
+	"github.com/protomem/secrets-keeper/internal/cryptor"
+	"github.com/protomem/secrets-keeper/internal/envelope"
+	"github.com/protomem/secrets-keeper/internal/geoip"
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/internal/storage"
+	"github.com/protomem/secrets-keeper/pkg/randstr"
+	"github.com/protomem/secrets-keeper/pkg/webauthn"
+)
+
+const maxPasskeys = 32
+
+type WebAuthnOptions struct {
+	Config     webauthn.Config
+	SessionTTL time.Duration
+	Lockout    LockoutOptions
+}
+
+type PasskeyDTO struct {
+	ID        []byte
+	PublicKey []byte
+}
+
+type BeginWebAuthnDTO struct {
+	SecretKey string
+	ClientIP  netip.Addr
+}
+
+type BeganWebAuthnDTO struct {
+	SessionKey       string
+	Challenge        []byte
+	RPID             string
+	Credentials      [][]byte
+	UserVerification bool
+	ExpiredAt        time.Time
+}
+
+type FinishWebAuthnDTO struct {
+	SecretKey         string
+	SessionKey        string
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	ClientIP          netip.Addr
+}
+
+func BeginWebAuthn(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	webauthnRepo *storage.WebAuthnRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	opts WebAuthnOptions,
+) UseCaseFunc[BeginWebAuthnDTO, BeganWebAuthnDTO] {
+	return func(ctx context.Context, dto BeginWebAuthnDTO) (BeganWebAuthnDTO, error) {
+		const op = "usecase.BeginWebAuthn"
+		var err error
+		now := time.Now()
+
+		secret, _, _, err := findGatedSecret(
+			ctx, secretRepo, eventRepo, encoder, encryptor, blobs, locator, opts.Lockout, dto.SecretKey, dto.ClientIP, hasPasskeys,
+		)
+		if err != nil {
+			return BeganWebAuthnDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		challenge, err := webauthn.NewChallenge()
+		if err != nil {
+			return BeganWebAuthnDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		session := model.WebAuthnSession{
+			CreatedAt:  now,
+			ExpiredAt:  now.Add(opts.SessionTTL),
+			SessionKey: randstr.SecureGen(32),
+			AccessKey:  secret.AccessKey,
+			Challenge:  challenge,
+		}
+
+		_, err = webauthnRepo.SaveSession(ctx, session)
+		if err != nil {
+			return BeganWebAuthnDTO{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		credentials := make([][]byte, 0, len(secret.Passkeys))
+		for _, passkey := range secret.Passkeys {
+			credentials = append(credentials, passkey.ID)
+		}
+
+		return BeganWebAuthnDTO{
+			SessionKey:       session.SessionKey,
+			Challenge:        challenge,
+			RPID:             opts.Config.RPID,
+			Credentials:      credentials,
+			UserVerification: opts.Config.UserVerification,
+			ExpiredAt:        session.ExpiredAt,
+		}, nil
+	}
+}
+
+func FinishWebAuthn(
+	secretRepo *storage.SecretRepository,
+	eventRepo *storage.EventRepository,
+	signerRepo *storage.SignerRepository,
+	downloadRepo *storage.DownloadRepository,
+	webauthnRepo *storage.WebAuthnRepository,
+	encoder cryptor.Encoder,
+	encryptor cryptor.Encryptor,
+	sealer *envelope.Envelope,
+	blobs blobstore.Store,
+	locator geoip.Locator,
+	opts WebAuthnOptions,
+	downloadTTL time.Duration,
+) UseCaseFunc[FinishWebAuthnDTO, model.Secret] {
+	return func(ctx context.Context, dto FinishWebAuthnDTO) (model.Secret, error) {
+		const op = "usecase.FinishWebAuthn"
+		var err error
+		now := time.Now()
+
+		secret, signingKey, country, err := findGatedSecret(
+			ctx, secretRepo, eventRepo, encoder, encryptor, blobs, locator, opts.Lockout, dto.SecretKey, dto.ClientIP, hasPasskeys,
+		)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		session, err := webauthnRepo.TakeSession(ctx, dto.SessionKey)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if session.AccessKey != secret.AccessKey || !now.Before(session.ExpiredAt) {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, model.ErrWebAuthnSessionNotFound)
+		}
+
+		err = waitAttemptDelay(ctx, opts.Lockout, secret.FailedAttempts)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = verifyPasskey(opts.Config, session.Challenge, secret.Passkeys, webauthn.Assertion{
+			CredentialID:      dto.CredentialID,
+			ClientDataJSON:    dto.ClientDataJSON,
+			AuthenticatorData: dto.AuthenticatorData,
+			Signature:         dto.Signature,
+		})
+		if err != nil {
+			regErr := registerFailedAttempt(ctx, secretRepo, eventRepo, blobs, opts.Lockout, secret)
+			if regErr != nil {
+				return model.Secret{}, fmt.Errorf("%s: %w", op, regErr)
+			}
+
+			return model.Secret{}, fmt.Errorf("%s: %w: %w", op, model.ErrSecretNotFound, err)
+		}
+
+		err = checkSecretAvailable(secret, now)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		key, err := lockSigningKey(signingKey, secret.SigningKey)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+		defer key.Destroy()
+
+		secret, err = revealSecret(
+			ctx, secretRepo, eventRepo, signerRepo, downloadRepo, encoder, encryptor, sealer, downloadTTL,
+			secret, key.Bytes(), country,
+		)
+		if err != nil {
+			return model.Secret{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return secret, nil
+	}
+}
+
+func PurgeExpiredWebAuthnSessions(webauthnRepo *storage.WebAuthnRepository) UseCaseFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) (int, error) {
+		const op = "usecase.PurgeExpiredWebAuthnSessions"
+
+		purged, err := webauthnRepo.RemoveExpiredSessions(ctx, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return purged, nil
+	}
+}
+
+func newPasskeys(dtos []PasskeyDTO) ([]model.Passkey, error) {
+	const op = "newPasskeys"
+
+	if len(dtos) > maxPasskeys {
+		return nil, fmt.Errorf("%s: %w: too many passkeys", op, model.ErrInvalidSecret)
+	}
+
+	passkeys := make([]model.Passkey, 0, len(dtos))
+	for _, dto := range dtos {
+		credential := webauthn.Credential{ID: dto.ID, PublicKey: dto.PublicKey}
+
+		err := credential.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %w", op, model.ErrInvalidSecret, err)
+		}
+
+		passkeys = append(passkeys, model.Passkey(credential))
+	}
+
+	return passkeys, nil
+}
+
+func verifyPasskey(conf webauthn.Config, challenge []byte, passkeys []model.Passkey, assertion webauthn.Assertion) error {
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		credentials = append(credentials, webauthn.Credential(passkey))
+	}
+
+	credential, err := webauthn.FindCredential(credentials, assertion.CredentialID)
+	if err != nil {
+		return err
+	}
+
+	return webauthn.Verify(conf, challenge, credential, assertion)
+}
+
+func hasPasskeys(secret model.Secret) bool {
+	return len(secret.Passkeys) > 0
+}
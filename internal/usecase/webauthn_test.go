@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/protomem/secrets-keeper/internal/model"
+	"github.com/protomem/secrets-keeper/pkg/webauthn"
+)
+
+const (
+	testRPID   = "secrets.example.com"
+	testOrigin = "https://secrets.example.com"
+)
+
+type webauthnTest struct {
+	env           *testEnv
+	authenticator *webauthn.VirtualAuthenticator
+	secretKey     string
+	begin         UseCaseFunc[BeginWebAuthnDTO, BeganWebAuthnDTO]
+	finish        UseCaseFunc[FinishWebAuthnDTO, model.Secret]
+}
+
+func newWebAuthnTest(t *testing.T) *webauthnTest {
+	t.Helper()
+
+	env := newTestEnv(t)
+
+	authenticator, err := webauthn.NewVirtualAuthenticator(testRPID, testOrigin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err := authenticator.Credential()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := env.createSecret(t, nil, CreateSecretDTO{
+		Message:  []byte("passkey protected"),
+		TTL:      1,
+		Passkeys: []PasskeyDTO{{ID: credential.ID, PublicKey: credential.PublicKey}},
+	})
+
+	opts := WebAuthnOptions{
+		Config:     webauthn.Config{RPID: testRPID, UserVerification: true},
+		SessionTTL: time.Minute,
+		Lockout:    LockoutOptions{MaxAttempts: 5},
+	}
+
+	return &webauthnTest{
+		env:           env,
+		authenticator: authenticator,
+		secretKey:     created.SecretKey,
+		begin: BeginWebAuthn(
+			env.store.SecretRepo(), env.store.EventRepo(), env.store.WebAuthnRepo(),
+			env.encoder, env.encryptor, env.blobs, nil, opts,
+		),
+		finish: FinishWebAuthn(
+			env.store.SecretRepo(),
+			env.store.EventRepo(),
+			env.store.SignerRepo(),
+			env.store.DownloadRepo(),
+			env.store.WebAuthnRepo(),
+			env.encoder,
+			env.encryptor,
+			env.sealer,
+			env.blobs,
+			nil,
+			opts,
+			time.Minute,
+		),
+	}
+}
+
+func (w *webauthnTest) assert(t *testing.T) (string, webauthn.Assertion) {
+	t.Helper()
+
+	began, err := w.begin(context.Background(), BeginWebAuthnDTO{SecretKey: w.secretKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertion, err := w.authenticator.Assert(began.Challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return began.SessionKey, assertion
+}
+
+func (w *webauthnTest) finishWith(sessionKey string, assertion webauthn.Assertion) (model.Secret, error) {
+	return w.finish(context.Background(), FinishWebAuthnDTO{
+		SecretKey:         w.secretKey,
+		SessionKey:        sessionKey,
+		CredentialID:      assertion.CredentialID,
+		ClientDataJSON:    assertion.ClientDataJSON,
+		AuthenticatorData: assertion.AuthenticatorData,
+		Signature:         assertion.Signature,
+	})
+}
+
+func (w *webauthnTest) failedAttempts(t *testing.T) int {
+	t.Helper()
+
+	accessKey, _ := decodeTestSecretKey(t, w.env, w.secretKey)
+	secret, err := w.env.store.SecretRepo().GetSecret(context.Background(), accessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return secret.FailedAttempts
+}
+
+func TestFinishWebAuthnRevealsSecret(t *testing.T) {
+	w := newWebAuthnTest(t)
+
+	sessionKey, assertion := w.assert(t)
+	secret, err := w.finishWith(sessionKey, assertion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Message) != "passkey protected" {
+		t.Fatalf("message = %q, want %q", secret.Message, "passkey protected")
+	}
+
+	_, err = w.begin(context.Background(), BeginWebAuthnDTO{SecretKey: w.secretKey})
+	if !errors.Is(err, model.ErrSecretNotFound) {
+		t.Fatalf("BeginWebAuthn() after read error = %v, want %v", err, model.ErrSecretNotFound)
+	}
+}
+
+func TestFinishWebAuthnRejectsBadAssertions(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(a *webauthn.VirtualAuthenticator)
+		tamper  func(assertion *webauthn.Assertion)
+	}{
+		{
+			name:    "wrong origin",
+			prepare: func(a *webauthn.VirtualAuthenticator) { a.Origin = "https://evil.example.net" },
+		},
+		{
+			name:    "wrong rp id",
+			prepare: func(a *webauthn.VirtualAuthenticator) { a.RPID = "example.com" },
+		},
+		{
+			name:    "missing user verification",
+			prepare: func(a *webauthn.VirtualAuthenticator) { a.UserVerified = false },
+		},
+		{
+			name:   "unknown credential",
+			tamper: func(assertion *webauthn.Assertion) { assertion.CredentialID = []byte("unknown") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWebAuthnTest(t)
+			if tt.prepare != nil {
+				tt.prepare(w.authenticator)
+			}
+
+			sessionKey, assertion := w.assert(t)
+			if tt.tamper != nil {
+				tt.tamper(&assertion)
+			}
+
+			_, err := w.finishWith(sessionKey, assertion)
+			if !errors.Is(err, model.ErrSecretNotFound) {
+				t.Fatalf("FinishWebAuthn() error = %v, want %v", err, model.ErrSecretNotFound)
+			}
+
+			if got := w.failedAttempts(t); got != 1 {
+				t.Fatalf("failed attempts = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestFinishWebAuthnRejectsReplayedChallenge(t *testing.T) {
+	w := newWebAuthnTest(t)
+
+	sessionKey, assertion := w.assert(t)
+	signature := assertion.Signature
+	assertion.Signature = []byte("bad signature")
+
+	_, err := w.finishWith(sessionKey, assertion)
+	if !errors.Is(err, model.ErrSecretNotFound) {
+		t.Fatalf("FinishWebAuthn() error = %v, want %v", err, model.ErrSecretNotFound)
+	}
+
+	assertion.Signature = signature
+	_, err = w.finishWith(sessionKey, assertion)
+	if !errors.Is(err, model.ErrWebAuthnSessionNotFound) {
+		t.Fatalf("replayed FinishWebAuthn() error = %v, want %v", err, model.ErrWebAuthnSessionNotFound)
+	}
+
+	nextSessionKey, _ := w.assert(t)
+	_, err = w.finishWith(nextSessionKey, assertion)
+	if !errors.Is(err, model.ErrSecretNotFound) {
+		t.Fatalf("FinishWebAuthn() with old challenge error = %v, want %v", err, model.ErrSecretNotFound)
+	}
+}
@@ -0,0 +1,41 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCloseRunsInRegistrationOrder(t *testing.T) {
+	c := New()
+
+	order := make([]string, 0)
+	for _, name := range []string{"server", "scheduler", "store"} {
+		c.Add(func(context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	err := c.Close(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(order, ","); got != "server,scheduler,store" {
+		t.Fatalf("close order = %s, want server,scheduler,store", got)
+	}
+}
+
+func TestCloseCollectsErrors(t *testing.T) {
+	c := New()
+	c.Add(func(context.Context) error { return errors.New("first") })
+	c.Add(func(context.Context) error { return nil })
+	c.Add(func(context.Context) error { return errors.New("second") })
+
+	err := c.Close(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "first") || !strings.Contains(err.Error(), "second") {
+		t.Fatalf("Close() error = %v, want both errors", err)
+	}
+}
@@ -0,0 +1,150 @@
+package e2e
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"strings"
+)
+
+const (
+	Version = 1
+
+	KeySize = 32
+
+	ivSize        = 12
+	tagSize       = 16
+	lengthSize    = 4
+	minPaddedSize = 256
+)
+
+var (
+	ErrInvalidKey        = errors.New("invalid e2e key")
+	ErrInvalidCiphertext = errors.New("invalid e2e ciphertext")
+)
+
+var encoding = base64.RawURLEncoding
+
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(key), nil
+}
+
+func Seal(plaintext []byte, key string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	block := make([]byte, paddedSize(lengthSize+len(plaintext)))
+	binary.BigEndian.PutUint32(block, uint32(len(plaintext)))
+	copy(block[lengthSize:], plaintext)
+
+	envelope := make([]byte, 1+ivSize, 1+ivSize+len(block)+tagSize)
+	envelope[0] = Version
+
+	_, err = rand.Read(envelope[1 : 1+ivSize])
+	if err != nil {
+		return "", err
+	}
+
+	envelope = aead.Seal(envelope, envelope[1:1+ivSize], block, envelope[:1])
+
+	return encoding.EncodeToString(envelope), nil
+}
+
+func Open(ciphertext string, key string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := decode(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aead.Open(nil, envelope[1:1+ivSize], envelope[1+ivSize:], envelope[:1])
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	size := binary.BigEndian.Uint32(block)
+	if uint64(size) > uint64(len(block)-lengthSize) {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return block[lengthSize : lengthSize+size], nil
+}
+
+func Validate(ciphertext string) error {
+	_, err := decode(ciphertext)
+	return err
+}
+
+func SplitLink(link string) (string, string, error) {
+	base, key, ok := strings.Cut(link, "#")
+	if !ok {
+		return "", "", ErrInvalidKey
+	}
+
+	_, err := decodeKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base, key, nil
+}
+
+func decode(ciphertext string) ([]byte, error) {
+	envelope, err := encoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	if len(envelope) < 1+ivSize+lengthSize+tagSize || envelope[0] != Version {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return envelope, nil
+}
+
+func decodeKey(key string) ([]byte, error) {
+	raw, err := encoding.DecodeString(key)
+	if err != nil || len(raw) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return raw, nil
+}
+
+func newAEAD(key string) (cipher.AEAD, error) {
+	raw, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func paddedSize(n int) int {
+	if n <= minPaddedSize {
+		return minPaddedSize
+	}
+
+	return 1 << bits.Len(uint(n-1))
+}
@@ -0,0 +1,130 @@
+package e2e
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestSealOpen(t *testing.T) {
+	key := newTestKey(t)
+
+	for _, plaintext := range [][]byte{{}, []byte("hello"), bytes.Repeat([]byte("x"), 1000)} {
+		ciphertext, err := Seal(plaintext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = Validate(ciphertext)
+		if err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+
+		got, err := Open(ciphertext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("Open() = %q, want %q", got, plaintext)
+		}
+	}
+}
+
+func TestSealPadsLength(t *testing.T) {
+	key := newTestKey(t)
+
+	short, err := Seal([]byte("a"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	long, err := Seal(bytes.Repeat([]byte("a"), 200), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(short) != len(long) {
+		t.Fatalf("ciphertext lengths = %d/%d, want equal", len(short), len(long))
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	key := newTestKey(t)
+
+	ciphertext, err := Seal([]byte("hello"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := encoding.DecodeString(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := bytes.Clone(raw)
+	flipped[len(flipped)-1] ^= 0x01
+
+	badVersion := bytes.Clone(raw)
+	badVersion[0] = Version + 1
+
+	tests := []struct {
+		name       string
+		ciphertext string
+		key        string
+	}{
+		{name: "wrong key", ciphertext: ciphertext, key: newTestKey(t)},
+		{name: "flipped bit", ciphertext: encoding.EncodeToString(flipped), key: key},
+		{name: "bad version", ciphertext: encoding.EncodeToString(badVersion), key: key},
+		{name: "truncated", ciphertext: encoding.EncodeToString(raw[:1+ivSize+tagSize]), key: key},
+		{name: "not base64", ciphertext: "!!!", key: key},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Open(tt.ciphertext, tt.key)
+			if !errors.Is(err, ErrInvalidCiphertext) {
+				t.Fatalf("Open() error = %v, want %v", err, ErrInvalidCiphertext)
+			}
+		})
+	}
+}
+
+func TestInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "short", strings.Repeat("A", 44), "!!"} {
+		_, err := Seal([]byte("hello"), key)
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("Seal() with key %q error = %v, want %v", key, err, ErrInvalidKey)
+		}
+	}
+}
+
+func TestSplitLink(t *testing.T) {
+	key := newTestKey(t)
+
+	base, got, err := SplitLink("https://secrets.example.com/e2e/abc#" + key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base != "https://secrets.example.com/e2e/abc" || got != key {
+		t.Fatalf("SplitLink() = %q, %q", base, got)
+	}
+
+	for _, link := range []string{"https://secrets.example.com/e2e/abc", "https://secrets.example.com/e2e/abc#bad"} {
+		_, _, err = SplitLink(link)
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("SplitLink(%q) error = %v, want %v", link, err, ErrInvalidKey)
+		}
+	}
+}
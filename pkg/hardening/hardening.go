@@ -0,0 +1,5 @@
+package hardening
+
+import "errors"
+
+var ErrUnsupported = errors.New("process hardening is not supported on this platform")
@@ -0,0 +1,23 @@
+//go:build linux
+
+package hardening
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+func Apply() error {
+	err := unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{})
+	if err != nil {
+		return fmt.Errorf("disable core dumps: %w", err)
+	}
+
+	err = unix.Prctl(unix.PR_SET_DUMPABLE, 0, 0, 0, 0)
+	if err != nil {
+		return fmt.Errorf("disable ptrace: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package hardening
+
+func Apply() error {
+	return ErrUnsupported
+}
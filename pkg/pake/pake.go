@@ -0,0 +1,352 @@
+package pake
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"filippo.io/edwards25519"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	Suite = "SPAKE2+-edwards25519-SHA256-HKDF-HMAC-argon2id"
+
+	SaltSize = 16
+	KeySize  = 32
+
+	maxMemory = 1024 * 1024
+
+	context = "secrets-keeper SPAKE2+ v1"
+)
+
+var (
+	ErrInvalidShare       = errors.New("invalid pake share")
+	ErrInvalidVerifier    = errors.New("invalid pake verifier")
+	ErrConfirmationFailed = errors.New("pake confirmation failed")
+	ErrInvalidKDFParams   = errors.New("invalid pake kdf params")
+)
+
+var DefaultParams = Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+var (
+	pointM = mustPoint("d048032c6ea0b6d697ddc2e86bda85a33adac920f1bf18e1b0c6d166a5cecdaf")
+	pointN = mustPoint("d3bfb518f44f3430f29d0c92af503865a1ed3281dc69b35dd868ba85f886c4ab")
+)
+
+type Params struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+type Verifier struct {
+	Salt   []byte
+	Params Params
+	W0     []byte
+	L      []byte
+}
+
+type Exchange struct {
+	ShareV    []byte
+	ConfirmV  []byte
+	ConfirmP  []byte
+	SharedKey []byte
+}
+
+type Prover struct {
+	x      *edwards25519.Scalar
+	w0, w1 *edwards25519.Scalar
+	shareP []byte
+}
+
+func NewVerifier(phrase []byte, params Params) (Verifier, error) {
+	salt := make([]byte, SaltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return Verifier{}, err
+	}
+
+	w0, w1, err := deriveScalars(phrase, salt, params)
+	if err != nil {
+		return Verifier{}, err
+	}
+
+	return Verifier{
+		Salt:   salt,
+		Params: params,
+		W0:     w0.Bytes(),
+		L:      new(edwards25519.Point).ScalarBaseMult(w1).Bytes(),
+	}, nil
+}
+
+func (v Verifier) Validate() error {
+	if len(v.Salt) < 8 || v.Params.validate() != nil {
+		return ErrInvalidVerifier
+	}
+
+	_, err := new(edwards25519.Scalar).SetCanonicalBytes(v.W0)
+	if err != nil {
+		return ErrInvalidVerifier
+	}
+
+	_, err = decodePoint(v.L)
+	if err != nil {
+		return ErrInvalidVerifier
+	}
+
+	return nil
+}
+
+func Respond(v Verifier, shareP []byte) (Exchange, error) {
+	w0, err := new(edwards25519.Scalar).SetCanonicalBytes(v.W0)
+	if err != nil {
+		return Exchange{}, ErrInvalidVerifier
+	}
+
+	l, err := decodePoint(v.L)
+	if err != nil {
+		return Exchange{}, ErrInvalidVerifier
+	}
+
+	x, err := decodePoint(shareP)
+	if err != nil {
+		return Exchange{}, err
+	}
+
+	y, err := randomScalar()
+	if err != nil {
+		return Exchange{}, err
+	}
+
+	shareV := new(edwards25519.Point).ScalarBaseMult(y)
+	shareV.Add(shareV, new(edwards25519.Point).ScalarMult(w0, pointN))
+
+	t := new(edwards25519.Point).Subtract(x, new(edwards25519.Point).ScalarMult(w0, pointM))
+	t.MultByCofactor(t)
+	if t.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return Exchange{}, ErrInvalidShare
+	}
+
+	z := new(edwards25519.Point).ScalarMult(y, t)
+	vv := new(edwards25519.Point).ScalarMult(y, new(edwards25519.Point).MultByCofactor(l))
+
+	confirmP, confirmV, sharedKey, err := deriveKeys(shareP, shareV.Bytes(), z.Bytes(), vv.Bytes(), v.W0)
+	if err != nil {
+		return Exchange{}, err
+	}
+
+	return Exchange{
+		ShareV:    shareV.Bytes(),
+		ConfirmV:  mac(confirmV, shareP),
+		ConfirmP:  mac(confirmP, shareV.Bytes()),
+		SharedKey: sharedKey,
+	}, nil
+}
+
+func (e Exchange) Verify(confirmP []byte) error {
+	if !hmac.Equal(e.ConfirmP, confirmP) {
+		return ErrConfirmationFailed
+	}
+
+	return nil
+}
+
+func NewProver(phrase []byte, salt []byte, params Params) (*Prover, error) {
+	w0, w1, err := deriveScalars(phrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	shareP := new(edwards25519.Point).ScalarBaseMult(x)
+	shareP.Add(shareP, new(edwards25519.Point).ScalarMult(w0, pointM))
+
+	return &Prover{x: x, w0: w0, w1: w1, shareP: shareP.Bytes()}, nil
+}
+
+func (p *Prover) Share() []byte {
+	return p.shareP
+}
+
+func (p *Prover) Finish(shareV, confirmV []byte) ([]byte, []byte, error) {
+	y, err := decodePoint(shareV)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := new(edwards25519.Point).Subtract(y, new(edwards25519.Point).ScalarMult(p.w0, pointN))
+	t.MultByCofactor(t)
+	if t.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, nil, ErrInvalidShare
+	}
+
+	z := new(edwards25519.Point).ScalarMult(p.x, t)
+	v := new(edwards25519.Point).ScalarMult(p.w1, t)
+
+	keyP, keyV, sharedKey, err := deriveKeys(p.shareP, shareV, z.Bytes(), v.Bytes(), p.w0.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !hmac.Equal(mac(keyV, p.shareP), confirmV) {
+		return nil, nil, ErrConfirmationFailed
+	}
+
+	return mac(keyP, shareV), sharedKey, nil
+}
+
+func (p Params) validate() error {
+	if p.Time == 0 || p.Time > 16 || p.Memory < 8*1024 || p.Memory > maxMemory || p.Threads == 0 {
+		return ErrInvalidKDFParams
+	}
+
+	return nil
+}
+
+func deriveScalars(phrase []byte, salt []byte, params Params) (*edwards25519.Scalar, *edwards25519.Scalar, error) {
+	err := params.validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := argon2.IDKey(phrase, salt, params.Time, params.Memory, params.Threads, 128)
+	defer clear(out)
+
+	w0, err := new(edwards25519.Scalar).SetUniformBytes(out[:64])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w1, err := new(edwards25519.Scalar).SetUniformBytes(out[64:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return w0, w1, nil
+}
+
+func deriveKeys(shareP, shareV, z, v, w0 []byte) ([]byte, []byte, []byte, error) {
+	var tt bytes.Buffer
+	for _, item := range [][]byte{
+		[]byte(context), nil, nil,
+		pointM.Bytes(), pointN.Bytes(),
+		shareP, shareV, z, v, w0,
+	} {
+		_ = binary.Write(&tt, binary.LittleEndian, uint64(len(item)))
+		tt.Write(item)
+	}
+
+	mainKey := sha256.Sum256(tt.Bytes())
+
+	confirmation := make([]byte, 2*KeySize)
+	_, err := io.ReadFull(hkdf.New(sha256.New, mainKey[:], nil, []byte("ConfirmationKeys")), confirmation)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sharedKey := make([]byte, KeySize)
+	_, err = io.ReadFull(hkdf.New(sha256.New, mainKey[:], nil, []byte("SharedKey")), sharedKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return confirmation[:KeySize], confirmation[KeySize:], sharedKey, nil
+}
+
+func mac(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func randomScalar() (*edwards25519.Scalar, error) {
+	buf := make([]byte, 64)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(edwards25519.Scalar).SetUniformBytes(buf)
+}
+
+func decodePoint(b []byte) (*edwards25519.Point, error) {
+	p, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil {
+		return nil, ErrInvalidShare
+	}
+
+	if new(edwards25519.Point).MultByCofactor(p).Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, ErrInvalidShare
+	}
+
+	return p, nil
+}
+
+func mustPoint(s string) *edwards25519.Point {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	p, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func Seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, []byte(context)), nil
+}
+
+func Open(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrConfirmationFailed
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(context))
+	if err != nil {
+		return nil, ErrConfirmationFailed
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
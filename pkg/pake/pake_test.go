@@ -0,0 +1,200 @@
+package pake
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+var testParams = Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+
+func TestExchange(t *testing.T) {
+	verifier, err := NewVerifier([]byte("correct horse"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = verifier.Validate()
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	prover, err := NewProver([]byte("correct horse"), verifier.Salt, verifier.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exchange, err := Respond(verifier, prover.Share())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirmP, sharedKey, err := prover.Finish(exchange.ShareV, exchange.ConfirmV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = exchange.Verify(confirmP)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if !bytes.Equal(sharedKey, exchange.SharedKey) {
+		t.Fatal("shared keys differ")
+	}
+
+	sealed, err := Seal(exchange.SharedKey, []byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := Open(sharedKey, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(opened) != "message" {
+		t.Fatalf("Open() = %q, want %q", opened, "message")
+	}
+}
+
+func TestExchangeWrongPhrase(t *testing.T) {
+	verifier, err := NewVerifier([]byte("correct horse"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prover, err := NewProver([]byte("battery staple"), verifier.Salt, verifier.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exchange, err := Respond(verifier, prover.Share())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = prover.Finish(exchange.ShareV, exchange.ConfirmV)
+	if !errors.Is(err, ErrConfirmationFailed) {
+		t.Fatalf("Finish() error = %v, want %v", err, ErrConfirmationFailed)
+	}
+
+	err = exchange.Verify(make([]byte, KeySize))
+	if !errors.Is(err, ErrConfirmationFailed) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrConfirmationFailed)
+	}
+}
+
+func TestRespondRejectsInvalidShares(t *testing.T) {
+	verifier, err := NewVerifier([]byte("correct horse"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w0, err := new(edwards25519.Scalar).SetCanonicalBytes(verifier.W0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity := edwards25519.NewIdentityPoint().Bytes()
+	maskOnly := new(edwards25519.Point).ScalarMult(w0, pointM).Bytes()
+	invalid := offCurvePoint(t)
+
+	for name, share := range map[string][]byte{
+		"identity":    identity,
+		"mask only":   maskOnly,
+		"not a point": invalid,
+		"short":       identity[:31],
+		"empty":       nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Respond(verifier, share)
+			if !errors.Is(err, ErrInvalidShare) {
+				t.Fatalf("Respond() error = %v, want %v", err, ErrInvalidShare)
+			}
+		})
+	}
+}
+
+func offCurvePoint(t *testing.T) []byte {
+	t.Helper()
+
+	b := make([]byte, 32)
+	for y := range 256 {
+		b[0] = byte(y)
+		_, err := new(edwards25519.Point).SetBytes(b)
+		if err != nil {
+			return b
+		}
+	}
+
+	t.Fatal("no off-curve encoding found")
+	return nil
+}
+
+func TestVerifierValidate(t *testing.T) {
+	verifier, err := NewVerifier([]byte("correct horse"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(v *Verifier)
+	}{
+		{name: "short salt", mutate: func(v *Verifier) { v.Salt = v.Salt[:4] }},
+		{name: "weak params", mutate: func(v *Verifier) { v.Params.Memory = 1024 }},
+		{name: "non canonical w0", mutate: func(v *Verifier) { v.W0 = bytes.Repeat([]byte{0xff}, 32) }},
+		{name: "identity l", mutate: func(v *Verifier) { v.L = edwards25519.NewIdentityPoint().Bytes() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := verifier
+			tt.mutate(&v)
+
+			err := v.Validate()
+			if !errors.Is(err, ErrInvalidVerifier) {
+				t.Fatalf("Validate() error = %v, want %v", err, ErrInvalidVerifier)
+			}
+		})
+	}
+}
+
+func TestParamsValidate(t *testing.T) {
+	for _, params := range []Params{
+		{Time: 0, Memory: 8 * 1024, Threads: 1},
+		{Time: 17, Memory: 8 * 1024, Threads: 1},
+		{Time: 1, Memory: 8*1024 - 1, Threads: 1},
+		{Time: 1, Memory: maxMemory + 1, Threads: 1},
+		{Time: 1, Memory: 8 * 1024, Threads: 0},
+	} {
+		_, err := NewVerifier([]byte("phrase"), params)
+		if !errors.Is(err, ErrInvalidKDFParams) {
+			t.Fatalf("NewVerifier(%+v) error = %v, want %v", params, err, ErrInvalidKDFParams)
+		}
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, KeySize)
+
+	sealed, err := Seal(key, []byte("message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-1] ^= 0x01
+
+	for name, data := range map[string][]byte{
+		"flipped": flipped,
+		"short":   sealed[:10],
+	} {
+		_, err = Open(key, data)
+		if !errors.Is(err, ErrConfirmationFailed) {
+			t.Fatalf("%s: Open() error = %v, want %v", name, err, ErrConfirmationFailed)
+		}
+	}
+}
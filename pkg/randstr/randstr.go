@@ -17,7 +17,13 @@ var src = rand.NewSource(time.Now().UnixNano())
 
 func Gen(n int) string {
 	b := make([]byte, n)
-	for i, cache, remain := n-1, src.Int63(), _letterIdxMax; i >= 0; {
+	Fill(b)
+
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+func Fill(b []byte) {
+	for i, cache, remain := len(b)-1, src.Int63(), _letterIdxMax; i >= 0; {
 		if remain == 0 {
 			cache, remain = src.Int63(), _letterIdxMax
 		}
@@ -28,6 +34,4 @@ func Gen(n int) string {
 		cache >>= _letterIdxBits
 		remain--
 	}
-
-	return *(*string)(unsafe.Pointer(&b))
 }
@@ -0,0 +1,32 @@
+package randstr
+
+import (
+	"crypto/rand"
+	"unsafe"
+)
+
+func SecureGen(n int) string {
+	b := make([]byte, n)
+	SecureFill(b)
+
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+func SecureFill(b []byte) {
+	buf := make([]byte, len(b)+len(b)/4+1)
+	defer clear(buf)
+
+	for i := 0; i < len(b); {
+		_, _ = rand.Read(buf)
+
+		for _, c := range buf {
+			if i == len(b) {
+				break
+			}
+			if idx := int(c & _letterIdxMask); idx < len(_letterBytes) {
+				b[i] = _letterBytes[idx]
+				i++
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package randstr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecureGen(t *testing.T) {
+	for _, n := range []int{0, 1, 16, 32, 1024} {
+		s := SecureGen(n)
+		if len(s) != n {
+			t.Fatalf("len(SecureGen(%d)) = %d", n, len(s))
+		}
+
+		for _, c := range s {
+			if !strings.ContainsRune(_letterBytes, c) {
+				t.Fatalf("SecureGen(%d) produced %q outside the alphabet", n, c)
+			}
+		}
+	}
+}
+
+func TestSecureGenIsUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		s := SecureGen(16)
+		if seen[s] {
+			t.Fatalf("SecureGen(16) repeated %q", s)
+		}
+		seen[s] = true
+	}
+}
+
+func TestSecureFillUsesWholeAlphabet(t *testing.T) {
+	b := make([]byte, 1<<14)
+	SecureFill(b)
+
+	counts := make(map[byte]int)
+	for _, c := range b {
+		counts[c]++
+	}
+
+	if len(counts) != len(_letterBytes) {
+		t.Fatalf("got %d distinct letters, want %d", len(counts), len(_letterBytes))
+	}
+}
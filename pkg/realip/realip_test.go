@@ -0,0 +1,94 @@
+package realip
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		cidr string
+		want string
+	}{
+		{cidr: "10.0.0.1", want: "10.0.0.1/32"},
+		{cidr: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{cidr: "2001:db8::1", want: "2001:db8::1/128"},
+		{cidr: "2001:db8::1/32", want: "2001:db8::/32"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePrefix(tt.cidr)
+		if err != nil {
+			t.Fatalf("ParsePrefix(%q) error = %v", tt.cidr, err)
+		}
+		if got.String() != tt.want {
+			t.Fatalf("ParsePrefix(%q) = %s, want %s", tt.cidr, got, tt.want)
+		}
+	}
+
+	for _, cidr := range []string{"", "10.0.0.256", "10.0.0.0/33", "example.com"} {
+		_, err := ParsePrefix(cidr)
+		if err == nil {
+			t.Fatalf("ParsePrefix(%q) accepted an invalid prefix", cidr)
+		}
+	}
+
+	_, err := ParsePrefixes([]string{"10.0.0.0/8", "bad"})
+	if err == nil {
+		t.Fatal("ParsePrefixes() accepted an invalid prefix")
+	}
+}
+
+func TestContainsUnmapsAddresses(t *testing.T) {
+	prefixes, err := ParsePrefixes([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Contains(prefixes, netip.MustParseAddr("::ffff:10.1.2.3")) {
+		t.Fatal("IPv4-mapped address is not matched")
+	}
+	if Contains(prefixes, netip.MustParseAddr("11.0.0.1")) {
+		t.Fatal("address outside the prefix is matched")
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	trusted, err := ParsePrefixes([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:1234", want: "203.0.113.7"},
+		{name: "untrusted peer ignores header", remoteAddr: "203.0.113.7:1234", forwarded: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.1:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed left hop", remoteAddr: "10.0.0.1:1234", forwarded: []string{"1.1.1.1, 198.51.100.1, 192.0.2.1"}, want: "198.51.100.1"},
+		{name: "repeated headers", remoteAddr: "10.0.0.1:1234", forwarded: []string{"1.1.1.1", "198.51.100.1"}, want: "198.51.100.1"},
+		{name: "all hops trusted", remoteAddr: "10.0.0.1:1234", forwarded: []string{"10.0.0.2"}, want: "10.0.0.2"},
+		{name: "invalid hop", remoteAddr: "10.0.0.1:1234", forwarded: []string{"198.51.100.1, junk"}, want: "10.0.0.1"},
+		{name: "mapped peer", remoteAddr: "[::ffff:10.0.0.1]:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "ipv6 client", remoteAddr: "[2001:db8::1]:1234", want: "2001:db8::1"},
+		{name: "garbage remote addr", remoteAddr: "garbage", want: "invalid IP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add(Header, value)
+			}
+
+			if got := FromRequest(r, trusted); got.String() != tt.want {
+				t.Fatalf("FromRequest() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	statementHeader       = "secrets-keeper-signature-v2"
+	legacyStatementHeader = "secrets-keeper-signature-v1"
+	registrationHeader    = "secrets-keeper-signer-v1"
+
+	fingerprintPrefix = "SHA256:"
+)
+
+var (
+	ErrInvalidPublicKey = errors.New("invalid ed25519 public key")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+type Statement struct {
+	PayloadType   string
+	Content       []byte
+	TTL           int64
+	AvailableFrom time.Time
+	Nonce         string
+}
+
+type Field struct {
+	Name  string
+	Value string
+}
+
+func (s Statement) Bytes() []byte {
+	digest := sha256.Sum256(s.Content)
+
+	return encodeItems(
+		[]byte(statementHeader),
+		[]byte(s.PayloadType),
+		[]byte(strconv.FormatInt(s.TTL, 10)),
+		[]byte(formatAvailableFrom(s.AvailableFrom)),
+		[]byte(s.Nonce),
+		digest[:],
+	)
+}
+
+func (s Statement) LegacyBytes() []byte {
+	digest := sha256.Sum256(s.Content)
+
+	return []byte(strings.Join([]string{
+		legacyStatementHeader,
+		"type:" + s.PayloadType,
+		"ttl:" + strconv.FormatInt(s.TTL, 10),
+		"available-from:" + formatAvailableFrom(s.AvailableFrom),
+		"content-sha256:" + hex.EncodeToString(digest[:]),
+	}, "\n"))
+}
+
+func FieldsContent(fields []Field) []byte {
+	items := make([][]byte, 0, 2*len(fields))
+	for _, field := range fields {
+		items = append(items, []byte(field.Name), []byte(field.Value))
+	}
+
+	return encodeItems(items...)
+}
+
+func RegistrationBytes(name string, pub ed25519.PublicKey) []byte {
+	return []byte(strings.Join([]string{
+		registrationHeader,
+		"name:" + name,
+		"key:" + FormatPublicKey(pub),
+	}, "\n"))
+}
+
+func Sign(priv ed25519.PrivateKey, message []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, message))
+}
+
+func Verify(pub ed25519.PublicKey, message []byte, sig string) error {
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+
+	if !ed25519.Verify(pub, message, raw) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return ed25519.PublicKey(raw), nil
+}
+
+func FormatPublicKey(pub ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(pub)
+}
+
+func Fingerprint(pub ed25519.PublicKey) string {
+	digest := sha256.Sum256(pub)
+	return fingerprintPrefix + base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func formatAvailableFrom(availableFrom time.Time) string {
+	if availableFrom.IsZero() {
+		return ""
+	}
+
+	return availableFrom.UTC().Format(time.RFC3339)
+}
+
+func encodeItems(items ...[]byte) []byte {
+	var buf bytes.Buffer
+	for _, item := range items {
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(len(item)))
+		buf.Write(item)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,211 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyRFC8032(t *testing.T) {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	publicKey, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	sig, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+
+	priv := ed25519.NewKeyFromSeed(seed)
+	if !bytes.Equal(priv.Public().(ed25519.PublicKey), publicKey) {
+		t.Fatal("public key does not match RFC 8032 vector")
+	}
+
+	got := Sign(priv, nil)
+	if got != base64.StdEncoding.EncodeToString(sig) {
+		t.Fatalf("Sign() = %s, want %x", got, sig)
+	}
+
+	err := Verify(publicKey, nil, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignVerifyStatement(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement := Statement{
+		PayloadType:   "text",
+		Content:       []byte("hello"),
+		TTL:           24,
+		AvailableFrom: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Nonce:         "nonce",
+	}
+	sig := Sign(priv, statement.Bytes())
+
+	err = Verify(pub, statement.Bytes(), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *Statement)
+	}{
+		{name: "payload type", mutate: func(s *Statement) { s.PayloadType = "fields" }},
+		{name: "content", mutate: func(s *Statement) { s.Content = []byte("hellO") }},
+		{name: "ttl", mutate: func(s *Statement) { s.TTL = 48 }},
+		{name: "available from", mutate: func(s *Statement) { s.AvailableFrom = time.Time{} }},
+		{name: "nonce", mutate: func(s *Statement) { s.Nonce = "other" }},
+		{name: "no nonce", mutate: func(s *Statement) { s.Nonce = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := statement
+			tt.mutate(&changed)
+
+			err := Verify(pub, changed.Bytes(), sig)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestStatementBytesSeparatesItems(t *testing.T) {
+	a := Statement{PayloadType: "text", Nonce: "ab", Content: []byte("c")}
+	b := Statement{PayloadType: "text", Nonce: "a", Content: []byte("c")}
+	if bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Fatal("statements with different nonces encode the same")
+	}
+
+	if bytes.Equal(a.Bytes(), a.LegacyBytes()) {
+		t.Fatal("statement and legacy statement encode the same")
+	}
+}
+
+func TestLegacyBytes(t *testing.T) {
+	statement := Statement{
+		PayloadType:   "text",
+		Content:       []byte("hello"),
+		TTL:           24,
+		AvailableFrom: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Nonce:         "ignored",
+	}
+
+	const want = "secrets-keeper-signature-v1\n" +
+		"type:text\n" +
+		"ttl:24\n" +
+		"available-from:2030-01-02T03:04:05Z\n" +
+		"content-sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	if got := string(statement.LegacyBytes()); got != want {
+		t.Fatalf("LegacyBytes() = %q, want %q", got, want)
+	}
+}
+
+func TestFieldsContent(t *testing.T) {
+	want := []byte{
+		1, 0, 0, 0, 0, 0, 0, 0, 'a',
+		2, 0, 0, 0, 0, 0, 0, 0, 'b', 'c',
+	}
+	if got := FieldsContent([]Field{{Name: "a", Value: "bc"}}); !bytes.Equal(got, want) {
+		t.Fatalf("FieldsContent() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		a, b []Field
+	}{
+		{
+			name: "newline in value",
+			a:    []Field{{Name: "user", Value: "alice\npassword: secret"}},
+			b:    []Field{{Name: "user", Value: "alice"}, {Name: "password", Value: "secret"}},
+		},
+		{
+			name: "separator in name",
+			a:    []Field{{Name: "a: b", Value: "c"}},
+			b:    []Field{{Name: "a", Value: "b: c"}},
+		},
+		{
+			name: "empty field",
+			a:    []Field{{Name: "a", Value: ""}},
+			b:    []Field{{Name: "a", Value: ""}, {Name: "", Value: ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if bytes.Equal(FieldsContent(tt.a), FieldsContent(tt.b)) {
+				t.Fatal("different fields encode the same")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsMalformedSignatures(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("message")
+	raw, _ := base64.StdEncoding.DecodeString(Sign(priv, message))
+
+	for name, sig := range map[string]string{
+		"not base64": "!!!",
+		"short":      base64.StdEncoding.EncodeToString(raw[:ed25519.SignatureSize-1]),
+		"other key":  Sign(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)), message),
+	} {
+		err = Verify(pub, message, sig)
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("%s: Verify() error = %v, want %v", name, err, ErrInvalidSignature)
+		}
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParsePublicKey(" " + FormatPublicKey(pub) + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(pub) {
+		t.Fatal("parsed key does not match")
+	}
+
+	for _, key := range []string{"", "!!!", base64.StdEncoding.EncodeToString(pub[:16])} {
+		_, err = ParsePublicKey(key)
+		if !errors.Is(err, ErrInvalidPublicKey) {
+			t.Fatalf("ParsePublicKey(%q) error = %v, want %v", key, err, ErrInvalidPublicKey)
+		}
+	}
+}
+
+func TestRegistrationBytesBindsName(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(RegistrationBytes("alice", pub), RegistrationBytes("bob", pub)) {
+		t.Fatal("registration bytes do not depend on the name")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	pub := make(ed25519.PublicKey, ed25519.PublicKeySize)
+
+	const want = "SHA256:Zmh6rfhivXdsj8GLjp-OIAiXFIVu4jOzkCpZHQ1fKSU"
+	if got := Fingerprint(pub); got != want {
+		t.Fatalf("Fingerprint() = %s, want %s", got, want)
+	}
+}
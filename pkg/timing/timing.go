@@ -0,0 +1,71 @@
+package timing
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+func Middleware(quantum time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if quantum <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			dw := &delayedWriter{
+				ResponseWriter: w,
+				request:        r,
+				start:          time.Now(),
+				quantum:        quantum,
+			}
+			defer dw.wait()
+
+			next.ServeHTTP(dw, r)
+		})
+	}
+}
+
+type delayedWriter struct {
+	http.ResponseWriter
+
+	request *http.Request
+	start   time.Time
+	quantum time.Duration
+	once    sync.Once
+}
+
+func (w *delayedWriter) WriteHeader(code int) {
+	w.wait()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *delayedWriter) Write(b []byte) (int, error) {
+	w.wait()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *delayedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *delayedWriter) wait() {
+	w.once.Do(func() {
+		timer := time.NewTimer(remaining(time.Since(w.start), w.quantum))
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-w.request.Context().Done():
+		}
+	})
+}
+
+func remaining(elapsed, quantum time.Duration) time.Duration {
+	if quantum <= 0 {
+		return 0
+	}
+
+	slots := elapsed/quantum + 1
+	return slots*quantum - elapsed
+}
@@ -0,0 +1,123 @@
+package timing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRemaining(t *testing.T) {
+	const quantum = 100 * time.Millisecond
+
+	tests := []struct {
+		elapsed time.Duration
+		want    time.Duration
+	}{
+		{elapsed: 0, want: 100 * time.Millisecond},
+		{elapsed: 30 * time.Millisecond, want: 70 * time.Millisecond},
+		{elapsed: 100 * time.Millisecond, want: 100 * time.Millisecond},
+		{elapsed: 250 * time.Millisecond, want: 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := remaining(tt.elapsed, quantum); got != tt.want {
+			t.Fatalf("remaining(%s) = %s, want %s", tt.elapsed, got, tt.want)
+		}
+	}
+
+	if got := remaining(time.Second, 0); got != 0 {
+		t.Fatalf("remaining() with zero quantum = %s, want 0", got)
+	}
+}
+
+func TestMiddlewareRoundsUpResponseTime(t *testing.T) {
+	const quantum = 50 * time.Millisecond
+
+	for _, work := range []time.Duration{0, 10 * time.Millisecond, 60 * time.Millisecond} {
+		handler := Middleware(quantum)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(work)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+
+		start := time.Now()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		elapsed := time.Since(start)
+
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+
+		want := (work/quantum + 1) * quantum
+		if elapsed < want {
+			t.Fatalf("work %s: elapsed %s, want at least %s", work, elapsed, want)
+		}
+	}
+}
+
+func TestMiddlewareWaitsWithoutWrite(t *testing.T) {
+	const quantum = 30 * time.Millisecond
+
+	handler := Middleware(quantum)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if elapsed := time.Since(start); elapsed < quantum {
+		t.Fatalf("elapsed %s, want at least %s", elapsed, quantum)
+	}
+}
+
+func TestMiddlewareDelaysOnlyFirstWrite(t *testing.T) {
+	const quantum = 30 * time.Millisecond
+
+	var between time.Duration
+	handler := Middleware(quantum)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("a"))
+		start := time.Now()
+		_, _ = w.Write([]byte("b"))
+		between = time.Since(start)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Body.String() != "ab" {
+		t.Fatalf("body = %q, want %q", rec.Body.String(), "ab")
+	}
+	if between >= quantum {
+		t.Fatalf("second write waited %s", between)
+	}
+}
+
+func TestMiddlewareStopsOnCanceledRequest(t *testing.T) {
+	handler := Middleware(time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler did not return after the request was canceled")
+	}
+}
+
+func TestMiddlewareDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	start := time.Now()
+	Middleware(0)(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("disabled middleware delayed the response by %s", elapsed)
+	}
+}
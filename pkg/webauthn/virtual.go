@@ -0,0 +1,89 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+)
+
+type VirtualAuthenticator struct {
+	RPID         string
+	Origin       string
+	UserVerified bool
+
+	id      []byte
+	key     *ecdsa.PrivateKey
+	counter uint32
+}
+
+func NewVirtualAuthenticator(rpID, origin string) (*VirtualAuthenticator, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 32)
+	_, err = rand.Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VirtualAuthenticator{
+		RPID:         rpID,
+		Origin:       origin,
+		UserVerified: true,
+		id:           id,
+		key:          key,
+	}, nil
+}
+
+func (a *VirtualAuthenticator) Credential() (Credential, error) {
+	publicKey, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{ID: a.id, PublicKey: publicKey}, nil
+}
+
+func (a *VirtualAuthenticator) Assert(challenge []byte) (Assertion, error) {
+	clientDataJSON, err := json.Marshal(clientData{
+		Type:      "webauthn.get",
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.Origin,
+	})
+	if err != nil {
+		return Assertion{}, err
+	}
+
+	a.counter++
+
+	rpIDHash := sha256.Sum256([]byte(a.RPID))
+	authData := make([]byte, authDataMinSize)
+	copy(authData, rpIDHash[:])
+	authData[32] = flagUserPresent
+	if a.UserVerified {
+		authData[32] |= flagUserVerified
+	}
+	binary.BigEndian.PutUint32(authData[33:], a.counter)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		return Assertion{}, err
+	}
+
+	return Assertion{
+		CredentialID:      a.id,
+		ClientDataJSON:    clientDataJSON,
+		AuthenticatorData: authData,
+		Signature:         sig,
+	}, nil
+}
@@ -0,0 +1,203 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	ChallengeSize = 32
+
+	MaxCredentialIDSize = 1023
+
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+
+	authDataMinSize = 37
+)
+
+var (
+	ErrInvalidCredential = errors.New("invalid webauthn credential")
+	ErrUnsupportedKey    = errors.New("unsupported webauthn public key")
+	ErrInvalidAssertion  = errors.New("invalid webauthn assertion")
+	ErrChallengeMismatch = errors.New("webauthn challenge mismatch")
+	ErrOriginMismatch    = errors.New("webauthn origin mismatch")
+	ErrRPIDMismatch      = errors.New("webauthn rp id mismatch")
+	ErrUserNotPresent    = errors.New("webauthn user not present")
+	ErrUserNotVerified   = errors.New("webauthn user not verified")
+	ErrSignatureMismatch = errors.New("webauthn signature mismatch")
+	ErrUnknownCredential = errors.New("unknown webauthn credential")
+)
+
+type Config struct {
+	RPID             string
+	Origins          []string
+	UserVerification bool
+}
+
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+}
+
+type Assertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, ChallengeSize)
+	_, err := rand.Read(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+func (c Credential) Validate() error {
+	if len(c.ID) == 0 || len(c.ID) > MaxCredentialIDSize {
+		return ErrInvalidCredential
+	}
+
+	_, err := ParsePublicKey(c.PublicKey)
+	return err
+}
+
+func ParsePublicKey(der []byte) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, ErrInvalidCredential
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, ErrUnsupportedKey
+		}
+	case ed25519.PublicKey:
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, ErrUnsupportedKey
+		}
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	return key, nil
+}
+
+func FindCredential(credentials []Credential, id []byte) (Credential, error) {
+	for _, credential := range credentials {
+		if subtle.ConstantTimeCompare(credential.ID, id) == 1 {
+			return credential, nil
+		}
+	}
+
+	return Credential{}, ErrUnknownCredential
+}
+
+func Verify(conf Config, challenge []byte, credential Credential, assertion Assertion) error {
+	if !bytes.Equal(credential.ID, assertion.CredentialID) {
+		return ErrUnknownCredential
+	}
+
+	var data clientData
+	err := json.Unmarshal(assertion.ClientDataJSON, &data)
+	if err != nil || data.Type != "webauthn.get" {
+		return ErrInvalidAssertion
+	}
+
+	gotChallenge, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(data.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(gotChallenge, challenge) != 1 {
+		return ErrChallengeMismatch
+	}
+
+	if !conf.allowsOrigin(data.Origin) {
+		return ErrOriginMismatch
+	}
+
+	authData := assertion.AuthenticatorData
+	if len(authData) < authDataMinSize {
+		return ErrInvalidAssertion
+	}
+
+	rpIDHash := sha256.Sum256([]byte(conf.RPID))
+	if subtle.ConstantTimeCompare(authData[:32], rpIDHash[:]) != 1 {
+		return ErrRPIDMismatch
+	}
+
+	flags := authData[32]
+	if flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+
+	if conf.UserVerification && flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+
+	key, err := ParsePublicKey(credential.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	signed := append(slices.Clip(authData), clientDataHash[:]...)
+
+	if !verifySignature(key, signed, assertion.Signature) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+func (conf Config) allowsOrigin(origin string) bool {
+	if len(conf.Origins) > 0 {
+		return slices.Contains(conf.Origins, origin)
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+
+	host := u.Hostname()
+	return host == conf.RPID || strings.HasSuffix(host, "."+conf.RPID)
+}
+
+func verifySignature(key crypto.PublicKey, signed, sig []byte) bool {
+	digest := sha256.Sum256(signed)
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, signed, sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return false
+	}
+}
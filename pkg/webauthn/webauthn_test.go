@@ -0,0 +1,209 @@
+package webauthn
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	testRPID   = "secrets.example.com"
+	testOrigin = "https://secrets.example.com"
+)
+
+func newTestAuthenticator(t *testing.T) (*VirtualAuthenticator, Credential) {
+	t.Helper()
+
+	authenticator, err := NewVirtualAuthenticator(testRPID, testOrigin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err := authenticator.Credential()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return authenticator, credential
+}
+
+func newTestChallenge(t *testing.T) []byte {
+	t.Helper()
+
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return challenge
+}
+
+func TestVerify(t *testing.T) {
+	conf := Config{RPID: testRPID, UserVerification: true}
+
+	tests := []struct {
+		name    string
+		conf    Config
+		prepare func(a *VirtualAuthenticator)
+		tamper  func(challenge []byte, assertion *Assertion) []byte
+		wantErr error
+	}{
+		{
+			name: "good assertion",
+			conf: conf,
+		},
+		{
+			name: "configured origin",
+			conf: Config{RPID: testRPID, Origins: []string{testOrigin}, UserVerification: true},
+		},
+		{
+			name:    "wrong origin",
+			conf:    conf,
+			prepare: func(a *VirtualAuthenticator) { a.Origin = "https://evil.example.net" },
+			wantErr: ErrOriginMismatch,
+		},
+		{
+			name:    "origin outside configured list",
+			conf:    Config{RPID: testRPID, Origins: []string{"https://other.example.com"}},
+			wantErr: ErrOriginMismatch,
+		},
+		{
+			name:    "plain http origin",
+			conf:    conf,
+			prepare: func(a *VirtualAuthenticator) { a.Origin = "http://secrets.example.com" },
+			wantErr: ErrOriginMismatch,
+		},
+		{
+			name:    "wrong rp id",
+			conf:    conf,
+			prepare: func(a *VirtualAuthenticator) { a.RPID = "example.com" },
+			wantErr: ErrRPIDMismatch,
+		},
+		{
+			name:    "missing user verification",
+			conf:    conf,
+			prepare: func(a *VirtualAuthenticator) { a.UserVerified = false },
+			wantErr: ErrUserNotVerified,
+		},
+		{
+			name:    "user verification not required",
+			conf:    Config{RPID: testRPID},
+			prepare: func(a *VirtualAuthenticator) { a.UserVerified = false },
+		},
+		{
+			name: "missing user presence",
+			conf: conf,
+			tamper: func(challenge []byte, assertion *Assertion) []byte {
+				assertion.AuthenticatorData[32] &^= flagUserPresent
+				return challenge
+			},
+			wantErr: ErrUserNotPresent,
+		},
+		{
+			name: "different challenge",
+			conf: conf,
+			tamper: func(_ []byte, _ *Assertion) []byte {
+				return newTestChallenge(t)
+			},
+			wantErr: ErrChallengeMismatch,
+		},
+		{
+			name: "tampered authenticator data",
+			conf: conf,
+			tamper: func(challenge []byte, assertion *Assertion) []byte {
+				assertion.AuthenticatorData[36]++
+				return challenge
+			},
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name: "unknown credential",
+			conf: conf,
+			tamper: func(challenge []byte, assertion *Assertion) []byte {
+				assertion.CredentialID = []byte("unknown")
+				return challenge
+			},
+			wantErr: ErrUnknownCredential,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator, credential := newTestAuthenticator(t)
+			if tt.prepare != nil {
+				tt.prepare(authenticator)
+			}
+
+			challenge := newTestChallenge(t)
+			assertion, err := authenticator.Assert(challenge)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.tamper != nil {
+				challenge = tt.tamper(challenge, &assertion)
+			}
+
+			err = Verify(tt.conf, challenge, credential, assertion)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Verify() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOtherAuthenticatorsSignature(t *testing.T) {
+	_, credential := newTestAuthenticator(t)
+	other, _ := newTestAuthenticator(t)
+
+	challenge := newTestChallenge(t)
+	assertion, err := other.Assert(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertion.CredentialID = credential.ID
+
+	err = Verify(Config{RPID: testRPID}, challenge, credential, assertion)
+	if !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrSignatureMismatch)
+	}
+}
+
+func TestFindCredential(t *testing.T) {
+	_, first := newTestAuthenticator(t)
+	_, second := newTestAuthenticator(t)
+
+	credential, err := FindCredential([]Credential{first, second}, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(credential.ID) != string(second.ID) {
+		t.Fatal("FindCredential() returned the wrong credential")
+	}
+
+	_, err = FindCredential([]Credential{first}, second.ID)
+	if !errors.Is(err, ErrUnknownCredential) {
+		t.Fatalf("FindCredential() error = %v, want %v", err, ErrUnknownCredential)
+	}
+}
+
+func TestCredentialValidate(t *testing.T) {
+	_, credential := newTestAuthenticator(t)
+
+	err := credential.Validate()
+	if err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+
+	err = Credential{ID: credential.ID, PublicKey: []byte("not a key")}.Validate()
+	if !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("Validate() error = %v, want %v", err, ErrInvalidCredential)
+	}
+
+	err = Credential{PublicKey: credential.PublicKey}.Validate()
+	if !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("Validate() error = %v, want %v", err, ErrInvalidCredential)
+	}
+}
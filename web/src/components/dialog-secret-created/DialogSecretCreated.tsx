@@ -10,6 +10,7 @@ interface Props {
   setOpen: (open: boolean) => void;
   secretKey: string;
   withSecretPhrase: boolean;
+  e2eKey?: string;
 }
 
 export default function DialogSecretCreated({
@@ -17,6 +18,7 @@ export default function DialogSecretCreated({
   setOpen,
   secretKey,
   withSecretPhrase,
+  e2eKey,
 }: Props) {
   const [copied, setCopied] = useState(false);
   useEffect(() => {
@@ -27,6 +29,9 @@ export default function DialogSecretCreated({
   if (withSecretPhrase) {
     linkToSecret += `?withSecretPhrase=true`;
   }
+  if (e2eKey) {
+    linkToSecret += `#${e2eKey}`;
+  }
 
   const handleClick = async () => {
     await copyTextToClipboard(linkToSecret);
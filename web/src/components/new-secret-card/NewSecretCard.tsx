@@ -2,7 +2,11 @@ import NewSecretForm from "@/components/new-secret-form/NewSecretForm";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
 
 interface Props {
-  onSubmit: (secretKey: string, withSecretPhrase: boolean) => void;
+  onSubmit: (
+    secretKey: string,
+    withSecretPhrase: boolean,
+    e2eKey?: string,
+  ) => void;
 }
 
 export default function NewSecretCard({ onSubmit }: Props) {
@@ -1,7 +1,11 @@
 import * as z from "zod";
 import { zodResolver } from "@hookform/resolvers/zod";
 import { useForm } from "react-hook-form";
-import { useCreateSecretMutation } from "@/feature/secrets/secrets.api";
+import {
+  useCreateE2ESecretMutation,
+  useCreateSecretMutation,
+} from "@/feature/secrets/secrets.api";
+import { generateKey, seal } from "@/lib/e2e";
 
 import {
   Form,
@@ -32,10 +36,16 @@ const formScheme = z.object({
   message: z.string().min(3).max(800),
   ttl: z.number().min(0).max(3600),
   secretPhrase: z.string().min(3).max(80).optional(),
+  availableFrom: z.string().optional(),
+  e2e: z.boolean(),
 });
 
 interface Props {
-  onSubmit: (secretKey: string, withSecretPhrase: boolean) => void;
+  onSubmit: (
+    secretKey: string,
+    withSecretPhrase: boolean,
+    e2eKey?: string,
+  ) => void;
 }
 
 export default function NewSecretForm({ onSubmit }: Props) {
@@ -45,16 +55,47 @@ export default function NewSecretForm({ onSubmit }: Props) {
       message: "",
       ttl: 0,
       secretPhrase: undefined,
+      availableFrom: undefined,
+      e2e: false,
     },
   });
 
   const [createSecret] = useCreateSecretMutation();
+  const [createE2ESecret] = useCreateE2ESecretMutation();
+
+  const handleSubmitE2E = async (data: z.infer<typeof formScheme>) => {
+    const e2eKey = generateKey();
+    const ciphertext = await seal(data.message, e2eKey);
+
+    createE2ESecret({
+      ciphertext,
+      ttl: data.ttl,
+      secretPhrase: data.secretPhrase,
+      availableFrom: data.availableFrom
+        ? new Date(data.availableFrom).toISOString()
+        : undefined,
+    })
+      .unwrap()
+      .then((res) => {
+        onSubmit(res.secretKey, res.withSecretPhrase, e2eKey);
+      });
+
+    form.reset();
+  };
 
   const handleSubmit = (data: z.infer<typeof formScheme>) => {
+    if (data.e2e) {
+      handleSubmitE2E(data);
+      return;
+    }
+
     createSecret({
       message: data.message,
       ttl: data.ttl,
       secretPhrase: data.secretPhrase,
+      availableFrom: data.availableFrom
+        ? new Date(data.availableFrom).toISOString()
+        : undefined,
     })
       .unwrap()
       .then((res) => {
@@ -148,6 +189,46 @@ export default function NewSecretForm({ onSubmit }: Props) {
                   </FormItem>
                 )}
               />
+
+              <FormField
+                control={form.control}
+                name="availableFrom"
+                render={({ field }) => (
+                  <FormItem className="mx-2 mt-4">
+                    <FormLabel className="text-lg italic">
+                      Available from
+                    </FormLabel>
+                    <FormControl>
+                      <Input type="datetime-local" {...field} />
+                    </FormControl>
+
+                    <FormMessage />
+                  </FormItem>
+                )}
+              />
+
+              <FormField
+                control={form.control}
+                name="e2e"
+                render={({ field }) => (
+                  <FormItem className="mx-2 mt-4 flex items-center gap-2">
+                    <FormControl>
+                      <input
+                        type="checkbox"
+                        checked={field.value}
+                        onChange={(event) =>
+                          field.onChange(event.target.checked)
+                        }
+                      />
+                    </FormControl>
+                    <FormLabel className="text-lg italic">
+                      End-to-end encryption
+                    </FormLabel>
+
+                    <FormMessage />
+                  </FormItem>
+                )}
+              />
             </AccordionContent>
           </AccordionItem>
         </Accordion>
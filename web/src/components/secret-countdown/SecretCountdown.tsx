@@ -0,0 +1,46 @@
+import { useEffect, useState } from "react";
+
+interface Props {
+  availableFrom: string;
+  onAvailable: () => void;
+}
+
+function formatRemaining(ms: number): string {
+  const totalSeconds = Math.max(0, Math.ceil(ms / 1000));
+  const days = Math.floor(totalSeconds / 86400);
+  const hours = Math.floor((totalSeconds % 86400) / 3600);
+  const minutes = Math.floor((totalSeconds % 3600) / 60);
+  const seconds = totalSeconds % 60;
+
+  const time = [hours, minutes, seconds]
+    .map((part) => part.toString().padStart(2, "0"))
+    .join(":");
+
+  return days > 0 ? `${days}d ${time}` : time;
+}
+
+export default function SecretCountdown({ availableFrom, onAvailable }: Props) {
+  const target = new Date(availableFrom).getTime();
+  const [remaining, setRemaining] = useState(target - Date.now());
+
+  useEffect(() => {
+    const timer = setInterval(() => {
+      const left = target - Date.now();
+      setRemaining(left);
+
+      if (left <= 0) {
+        clearInterval(timer);
+        onAvailable();
+      }
+    }, 1000);
+
+    return () => clearInterval(timer);
+  }, [target, onAvailable]);
+
+  return (
+    <div className="m-8 text-center">
+      <h2 className="text-3xl">Secret Not Yet Available</h2>
+      <p className="mt-4 text-2xl">{formatRemaining(remaining)}</p>
+    </div>
+  );
+}
@@ -1,19 +1,76 @@
-import { ISecret } from "@/entities/entites";
-import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
+import { useState } from "react";
+
+import { ISecret, ISecretField } from "@/entities/entites";
+import { Badge } from "@/components/ui/badge";
+import {
+  Card,
+  CardContent,
+  CardFooter,
+  CardHeader,
+  CardTitle,
+} from "@/components/ui/card";
 
 interface Props {
   secret: ISecret;
 }
 
+function SecretFieldValue({ field }: { field: ISecretField }) {
+  const [revealed, setRevealed] = useState(!field.sensitive);
+
+  if (!revealed) {
+    return (
+      <span
+        className="cursor-pointer select-none blur-sm"
+        onClick={() => setRevealed(true)}
+      >
+        {field.value}
+      </span>
+    );
+  }
+
+  return <span className="whitespace-pre-line break-all">{field.value}</span>;
+}
+
 export default function ViewSecretCard({ secret }: Props) {
   return (
     <Card className="w-[50rem] m-8">
       <CardHeader>
         <CardTitle>Secret</CardTitle>
       </CardHeader>
-      <CardContent className="text-lg whitespace-pre-line">
-        {secret.message}
-      </CardContent>
+      {secret.payloadType === "fields" && secret.fields ? (
+        <CardContent className="text-lg">
+          <dl className="space-y-4">
+            {secret.fields.map((field, idx) => (
+              <div key={idx}>
+                <dt className="flex items-center gap-2 font-semibold">
+                  {field.name}
+                  {field.sensitive && <Badge variant="outline">sensitive</Badge>}
+                </dt>
+                <dd>
+                  <SecretFieldValue field={field} />
+                </dd>
+              </div>
+            ))}
+          </dl>
+        </CardContent>
+      ) : (
+        <CardContent className="text-lg whitespace-pre-line">
+          {secret.message}
+        </CardContent>
+      )}
+      {secret.signer && (
+        <CardFooter className="flex flex-col items-start gap-1 text-sm">
+          <div className="flex items-center gap-2">
+            Signed by <span className="font-semibold">{secret.signer.name}</span>
+            <Badge variant={secret.signer.verified ? "outline" : "destructive"}>
+              {secret.signer.verified ? "verified" : "unverified"}
+            </Badge>
+          </div>
+          <code className="break-all text-muted-foreground">
+            {secret.signer.fingerprint}
+          </code>
+        </CardFooter>
+      )}
     </Card>
   );
 }
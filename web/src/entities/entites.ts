@@ -1,5 +1,27 @@
+export type SecretPayloadType = "text" | "fields" | "age";
+
+export interface ISecretField {
+  name: string;
+  value: string;
+  sensitive: boolean;
+  contentType: string;
+}
+
+export interface ISecretSigner {
+  name: string;
+  fingerprint: string;
+  signature: string;
+  ttl: number;
+  availableFrom?: string;
+  verified: boolean;
+}
+
 export interface ISecret {
   id: number;
   createdAt: string;
+  availableFrom: string;
+  payloadType: SecretPayloadType;
   message: string;
+  fields?: ISecretField[];
+  signer?: ISecretSigner;
 }
@@ -1,4 +1,4 @@
-import { ISecret } from "@/entities/entites";
+import { ISecret, ISecretField, ISecretSigner } from "@/entities/entites";
 import { slug } from "@/lib/slug";
 import { createApi, fetchBaseQuery } from "@reduxjs/toolkit/query/react";
 
@@ -11,10 +11,17 @@ interface GetSecretResponse {
   secret: ISecret;
 }
 
+export interface GetSecretNotYetAvailableResponse {
+  error: string;
+  availableFrom: string;
+}
+
 interface CreateSecretRequest {
-  message: string;
+  message?: string;
+  fields?: ISecretField[];
   ttl: number;
   secretPhrase?: string;
+  availableFrom?: string;
 }
 
 interface CreateSecretResponse {
@@ -22,6 +29,20 @@ interface CreateSecretResponse {
   withSecretPhrase: boolean;
 }
 
+interface GetE2ESecretResponse {
+  id: number;
+  createdAt: string;
+  ciphertext: string;
+  signer?: ISecretSigner;
+}
+
+interface CreateE2ESecretRequest {
+  ciphertext: string;
+  ttl: number;
+  secretPhrase?: string;
+  availableFrom?: string;
+}
+
 export const secretsApi = createApi({
   reducerPath: "secretsApi",
   baseQuery: fetchBaseQuery({
@@ -37,13 +58,48 @@ export const secretsApi = createApi({
     }),
 
     createSecret: builder.mutation<CreateSecretResponse, CreateSecretRequest>({
-      query: ({ message, ttl, secretPhrase }) => ({
+      query: ({ message, fields, ttl, secretPhrase, availableFrom }) => ({
         url: `/secrets`,
         method: "POST",
-        body: { message, ttl, secretPhrase: slug(secretPhrase) },
+        body: {
+          message,
+          fields,
+          ttl,
+          secretPhrase: slug(secretPhrase),
+          availableFrom,
+        },
+      }),
+    }),
+
+    getE2ESecret: builder.query<GetE2ESecretResponse, GetSecretRequest>({
+      query: ({ secretKey, secretPhrase }) => ({
+        url: `/e2e/secrets/${secretKey}`,
+        method: "POST",
+        body: { secretPhrase: slug(secretPhrase) },
+      }),
+    }),
+
+    createE2ESecret: builder.mutation<
+      CreateSecretResponse,
+      CreateE2ESecretRequest
+    >({
+      query: ({ ciphertext, ttl, secretPhrase, availableFrom }) => ({
+        url: `/e2e/secrets`,
+        method: "POST",
+        body: {
+          ciphertext,
+          ttl,
+          secretPhrase: slug(secretPhrase),
+          availableFrom,
+        },
       }),
     }),
   }),
 });
 
-export const { useGetSecretQuery, useCreateSecretMutation } = secretsApi;
+export const {
+  useGetSecretQuery,
+  useCreateSecretMutation,
+  useGetE2ESecretQuery,
+  useCreateE2ESecretMutation,
+} = secretsApi;
@@ -0,0 +1,90 @@
+const VERSION = 1;
+const KEY_SIZE = 32;
+const IV_SIZE = 12;
+const LENGTH_SIZE = 4;
+const MIN_PADDED_SIZE = 256;
+
+function encode(data: Uint8Array): string {
+  let binary = "";
+  data.forEach((byte) => {
+    binary += String.fromCharCode(byte);
+  });
+
+  return btoa(binary)
+    .replace(/\+/g, "-")
+    .replace(/\//g, "_")
+    .replace(/=+$/, "");
+}
+
+function decode(text: string): Uint8Array {
+  const base64 = text.replace(/-/g, "+").replace(/_/g, "/");
+  const binary = atob(base64 + "=".repeat((4 - (base64.length % 4)) % 4));
+
+  return Uint8Array.from(binary, (char) => char.charCodeAt(0));
+}
+
+function paddedSize(size: number): number {
+  let padded = MIN_PADDED_SIZE;
+  while (padded < size) padded *= 2;
+
+  return padded;
+}
+
+async function importKey(key: string, usage: KeyUsage): Promise<CryptoKey> {
+  const raw = decode(key);
+  if (raw.length !== KEY_SIZE) throw new Error("invalid e2e key");
+
+  return crypto.subtle.importKey("raw", raw, "AES-GCM", false, [usage]);
+}
+
+export function generateKey(): string {
+  return encode(crypto.getRandomValues(new Uint8Array(KEY_SIZE)));
+}
+
+export async function seal(message: string, key: string): Promise<string> {
+  const plaintext = new TextEncoder().encode(message);
+
+  const block = new Uint8Array(paddedSize(LENGTH_SIZE + plaintext.length));
+  new DataView(block.buffer).setUint32(0, plaintext.length);
+  block.set(plaintext, LENGTH_SIZE);
+
+  const header = new Uint8Array([VERSION]);
+  const iv = crypto.getRandomValues(new Uint8Array(IV_SIZE));
+  const ciphertext = await crypto.subtle.encrypt(
+    { name: "AES-GCM", iv, additionalData: header, tagLength: 128 },
+    await importKey(key, "encrypt"),
+    block,
+  );
+
+  const envelope = new Uint8Array(1 + IV_SIZE + ciphertext.byteLength);
+  envelope.set(header);
+  envelope.set(iv, 1);
+  envelope.set(new Uint8Array(ciphertext), 1 + IV_SIZE);
+
+  return encode(envelope);
+}
+
+export async function open(ciphertext: string, key: string): Promise<string> {
+  const envelope = decode(ciphertext);
+  if (envelope[0] !== VERSION) throw new Error("invalid e2e ciphertext");
+
+  const block = new Uint8Array(
+    await crypto.subtle.decrypt(
+      {
+        name: "AES-GCM",
+        iv: envelope.slice(1, 1 + IV_SIZE),
+        additionalData: envelope.slice(0, 1),
+        tagLength: 128,
+      },
+      await importKey(key, "decrypt"),
+      envelope.slice(1 + IV_SIZE),
+    ),
+  );
+
+  const size = new DataView(block.buffer).getUint32(0);
+  if (size > block.length - LENGTH_SIZE) {
+    throw new Error("invalid e2e ciphertext");
+  }
+
+  return new TextDecoder().decode(block.slice(LENGTH_SIZE, LENGTH_SIZE + size));
+}
@@ -7,11 +7,17 @@ export default function NewSecretPage() {
   const [openDialog, setOpenDialog] = useState(false);
   const [secretKey, setSecretKey] = useState("");
   const [withSecretPhrase, setWithSecretPhrase] = useState(false);
+  const [e2eKey, setE2EKey] = useState<string | undefined>(undefined);
 
-  const onSubmit = (secretKey: string, withSecretPhrase: boolean) => {
+  const onSubmit = (
+    secretKey: string,
+    withSecretPhrase: boolean,
+    e2eKey?: string,
+  ) => {
     setOpenDialog(true);
     setSecretKey(secretKey);
     setWithSecretPhrase(withSecretPhrase);
+    setE2EKey(e2eKey);
   };
 
   return (
@@ -22,6 +28,7 @@ export default function NewSecretPage() {
         setOpen={setOpenDialog}
         secretKey={secretKey}
         withSecretPhrase={withSecretPhrase}
+        e2eKey={e2eKey}
       />
     </div>
   );
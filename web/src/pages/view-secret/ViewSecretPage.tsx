@@ -1,9 +1,15 @@
 import ButtonBackwards from "@/components/button-backwards/ButtonBackwards";
 import DialogConfirmSecret from "@/components/dialog-confirm-secret/DialogConfirmSecret";
+import SecretCountdown from "@/components/secret-countdown/SecretCountdown";
 import ViewSecretCard from "@/components/view-secret-card/ViewSecretCard";
 import { ISecret } from "@/entities/entites";
-import { useGetSecretQuery } from "@/feature/secrets/secrets.api";
-import { useEffect, useState } from "react";
+import {
+  GetSecretNotYetAvailableResponse,
+  useGetE2ESecretQuery,
+  useGetSecretQuery,
+} from "@/feature/secrets/secrets.api";
+import { open } from "@/lib/e2e";
+import { useCallback, useEffect, useState } from "react";
 import { useParams } from "react-router-dom";
 
 export default function ViewSecretPage() {
@@ -12,6 +18,7 @@ export default function ViewSecretPage() {
 
   const withSecretPhrase =
     queryParams.get("withSecretPhrase") === "true" || false;
+  const e2eKey = window.location.hash.slice(1);
 
   const [openDialogConfirmSecret, setOpenDialogConfirmSecret] =
     useState(withSecretPhrase);
@@ -25,16 +32,60 @@ export default function ViewSecretPage() {
     setSecretPhrase(secretPhrase);
   };
 
-  const { data, isSuccess, isError, isLoading } = useGetSecretQuery({
-    secretKey: params.secretKey || "",
-    secretPhrase,
-  });
+  const [decryptFailed, setDecryptFailed] = useState(false);
+
+  const plainQuery = useGetSecretQuery(
+    {
+      secretKey: params.secretKey || "",
+      secretPhrase,
+    },
+    { skip: e2eKey !== "" },
+  );
+  const e2eQuery = useGetE2ESecretQuery(
+    {
+      secretKey: params.secretKey || "",
+      secretPhrase,
+    },
+    { skip: e2eKey === "" },
+  );
+
+  const { error, isSuccess, isLoading, refetch } =
+    e2eKey !== "" ? e2eQuery : plainQuery;
+  const isError =
+    (e2eKey !== "" ? e2eQuery : plainQuery).isError || decryptFailed;
+
+  const availableFrom =
+    error && "status" in error && error.status === 425
+      ? (error.data as GetSecretNotYetAvailableResponse).availableFrom
+      : null;
+
+  const handleAvailable = useCallback(() => {
+    refetch();
+  }, [refetch]);
 
   useEffect(() => {
+    if (!plainQuery.data || secret !== null) return;
+
+    setSecret(plainQuery.data.secret);
+  }, [plainQuery.data, secret]);
+
+  useEffect(() => {
+    const data = e2eQuery.data;
     if (!data || secret !== null) return;
 
-    setSecret(data.secret);
-  }, [data, secret]);
+    open(data.ciphertext, e2eKey)
+      .then((message) => {
+        setSecret({
+          id: data.id,
+          createdAt: data.createdAt,
+          availableFrom: data.createdAt,
+          payloadType: "text",
+          message,
+          signer: data.signer,
+        });
+      })
+      .catch(() => setDecryptFailed(true));
+  }, [e2eQuery.data, e2eKey, secret]);
 
   return (
     <div className="flex flex-row items-start justify-between">
@@ -57,7 +108,13 @@ export default function ViewSecretPage() {
               </div>
             )}
             {isSuccess && secret && <ViewSecretCard secret={secret} />}
-            {isError && (
+            {isError && availableFrom && (
+              <SecretCountdown
+                availableFrom={availableFrom}
+                onAvailable={handleAvailable}
+              />
+            )}
+            {isError && !availableFrom && (
               <div className="m-8 text-center">
                 <h2 className="text-3xl">Secret Not Found</h2>
               </div>